@@ -4,12 +4,15 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"syscall"
 	"time"
 
 	"web-analyzer-api/app/internal/api"
+	"web-analyzer-api/app/internal/config"
 	"web-analyzer-api/app/internal/di"
 	"web-analyzer-api/app/internal/util/logger"
 
@@ -19,11 +22,15 @@ import (
 )
 
 func main() {
-	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer cancel()
 
 	// Setup logger, HTTP and Metrics servers
-	log, mainServer, metricsServer := setupServers()
+	log, app, mainServer, metricsServer, err := setupServers()
+	if err != nil {
+		log.Error("Failed to setup servers", "error", err)
+		os.Exit(1)
+	}
 
 	// Start HTTP server
 	go func() {
@@ -60,6 +67,16 @@ func main() {
 		errs = append(errs, err)
 	}
 
+	// Drain background analyses; anything unfinished after the grace period is marked as interrupted
+	log.Info("Waiting for running analyses", "grace_period", app.Config.ShutdownGracePeriod.String())
+	ctxGrace, cancelGrace := context.WithTimeout(context.Background(), app.Config.ShutdownGracePeriod)
+	defer cancelGrace()
+
+	if err := app.Shutdown(ctxGrace); err != nil {
+		log.Error("Failed to shutdown background analyses gracefully", "error", err)
+		errs = append(errs, err)
+	}
+
 	if len(errs) > 0 {
 		log.Error("Shutdown completed with errors")
 	} else {
@@ -67,16 +84,19 @@ func main() {
 	}
 }
 
-func setupServers() (*logger.Logger, *http.Server, *http.Server) {
+func setupServers() (*logger.Logger, *di.Container, *http.Server, *http.Server, error) {
 	log := logger.Get(os.Getenv("LOG_LEVEL"))
 	log.Info("Starting Web Analyzer application")
 
-	app := di.NewContainer(log)
+	app, err := di.NewContainer(log, config.Load())
+	if err != nil {
+		return log, nil, nil, nil, err
+	}
 	log.Info("Dependency injection container initialized")
 
 	router := gin.New()
 
-	err := api.SetupRouter(router, app.HTTPHandlers, log)
+	err = api.SetupRouter(router, app.HTTPHandlers, log)
 	if err != nil {
 		log.Error("Failed to setup router", "error", err)
 	}
@@ -95,10 +115,14 @@ func setupServers() (*logger.Logger, *http.Server, *http.Server) {
 	enablePprof := os.Getenv("ENABLE_PPROF") == "true"
 
 	log.Info("Starting HTTP server", "port", serverPort)
+	// Long-lived event streams end when the server shuts down instead of holding it open
+	baseCtx, cancelStreams := context.WithCancel(context.Background())
 	httpServer := &http.Server{
-		Addr:    fmt.Sprintf(":%s", serverPort),
-		Handler: router,
+		Addr:        fmt.Sprintf(":%s", serverPort),
+		Handler:     router,
+		BaseContext: func(net.Listener) context.Context { return baseCtx },
 	}
+	httpServer.RegisterOnShutdown(cancelStreams)
 
 	log.Info("Starting Metrics server", "port", metricsPort)
 	metricsRouter := gin.New()
@@ -115,5 +139,5 @@ func setupServers() (*logger.Logger, *http.Server, *http.Server) {
 		Handler: metricsRouter,
 	}
 
-	return log, httpServer, metricsServer
+	return log, app, httpServer, metricsServer, nil
 }
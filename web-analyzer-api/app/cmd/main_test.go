@@ -2,6 +2,7 @@ package main
 
 import (
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -12,9 +13,13 @@ func TestSetupServers_DefaultValues(t *testing.T) {
 	os.Unsetenv("METRICS_PORT")
 	os.Unsetenv("ENABLE_PPROF")
 	os.Unsetenv("LOG_LEVEL")
+	os.Setenv("STORAGE_DRIVER", "memory")
+	defer os.Unsetenv("STORAGE_DRIVER")
 
-	_, mainServer, metricsServer := setupServers()
+	_, app, mainServer, metricsServer, err := setupServers()
 
+	assert.NoError(t, err)
+	assert.NotNil(t, app)
 	assert.NotNil(t, mainServer)
 	assert.NotNil(t, metricsServer)
 	assert.Equal(t, ":8081", mainServer.Addr)
@@ -26,19 +31,37 @@ func TestSetupServers_CustomValues(t *testing.T) {
 	os.Setenv("METRICS_PORT", "10010")
 	os.Setenv("ENABLE_PPROF", "true")
 	os.Setenv("LOG_LEVEL", "debug")
+	os.Setenv("STORAGE_DRIVER", "sqlite")
+	os.Setenv("SQLITE_PATH", filepath.Join(t.TempDir(), "test.db"))
 
 	defer func() {
 		os.Unsetenv("SERVER_PORT")
 		os.Unsetenv("METRICS_PORT")
 		os.Unsetenv("ENABLE_PPROF")
 		os.Unsetenv("LOG_LEVEL")
+		os.Unsetenv("STORAGE_DRIVER")
+		os.Unsetenv("SQLITE_PATH")
 	}()
 
-	log, mainServer, metricsServer := setupServers()
+	log, app, mainServer, metricsServer, err := setupServers()
+	defer app.Close()
 
+	assert.NoError(t, err)
 	assert.NotNil(t, log)
 	assert.NotNil(t, mainServer)
 	assert.NotNil(t, metricsServer)
 	assert.Equal(t, ":9091", mainServer.Addr)
 	assert.Equal(t, ":10010", metricsServer.Addr)
 }
+
+func TestSetupServers_InvalidStorageDriver(t *testing.T) {
+	os.Setenv("STORAGE_DRIVER", "unknown")
+	defer os.Unsetenv("STORAGE_DRIVER")
+
+	_, app, mainServer, metricsServer, err := setupServers()
+
+	assert.Error(t, err)
+	assert.Nil(t, app)
+	assert.Nil(t, mainServer)
+	assert.Nil(t, metricsServer)
+}
@@ -28,6 +28,7 @@ func TestCORSMiddleware(t *testing.T) {
 		assert.Equal(t, "*", resp.Header().Get("Access-Control-Allow-Origin"))
 		assert.Equal(t, "true", resp.Header().Get("Access-Control-Allow-Credentials"))
 		assert.Contains(t, resp.Header().Get("Access-Control-Allow-Methods"), "OPTIONS")
+		assert.Contains(t, resp.Header().Get("Access-Control-Allow-Methods"), "DELETE")
 		assert.Empty(t, resp.Body.String())
 	})
 
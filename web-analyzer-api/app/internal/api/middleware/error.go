@@ -1,7 +1,9 @@
 package middleware
 
 import (
+	"math"
 	"net/http"
+	"strconv"
 	"web-analyzer-api/app/internal/core/apperror"
 	"web-analyzer-api/app/internal/util/logger"
 
@@ -20,6 +22,9 @@ func ErrorHandler(logger logger.Logger) gin.HandlerFunc {
 				switch e := err.(type) {
 				case *apperror.AppError:
 					if !c.Writer.Written() {
+						if e.RetryAfter > 0 {
+							c.Header("Retry-After", strconv.Itoa(int(math.Ceil(e.RetryAfter.Seconds()))))
+						}
 						c.JSON(e.StatusCode, gin.H{"status_code": e.StatusCode, "message": e.Message, "category": e.Category, "reason": e.Reason})
 					}
 				default:
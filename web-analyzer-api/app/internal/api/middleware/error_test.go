@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 	"web-analyzer-api/app/internal/core/apperror"
 	"web-analyzer-api/app/internal/util/logger"
 
@@ -49,6 +50,23 @@ func TestErrorHandler(t *testing.T) {
 		assert.Contains(t, resp.Body.String(), "validation")
 	})
 
+	t.Run("AppError with Retry-After", func(t *testing.T) {
+		router := gin.New()
+		router.Use(ErrorHandler(*log))
+		router.GET("/test", func(c *gin.Context) {
+			c.Error(apperror.ServiceUnavailable("queue is full", 1500*time.Millisecond))
+		})
+
+		req, _ := http.NewRequest(http.MethodGet, "/test", nil)
+		resp := httptest.NewRecorder()
+
+		router.ServeHTTP(resp, req)
+
+		assert.Equal(t, http.StatusServiceUnavailable, resp.Code)
+		assert.Equal(t, "2", resp.Header().Get("Retry-After"))
+		assert.Contains(t, resp.Body.String(), "queue is full")
+	})
+
 	t.Run("Generic error handling", func(t *testing.T) {
 		router := gin.New()
 		router.Use(ErrorHandler(*log))
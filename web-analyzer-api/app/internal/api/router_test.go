@@ -29,15 +29,98 @@ func (m *MockWebAnalyzerService) GetAnalyzeData(ctx context.Context, analyzeId s
 	return args.Get(0).(*contract.WebAnalyzeResponse), args.Error(1)
 }
 
-func (m *MockWebAnalyzerService) AnalyzeWebsite(ctx context.Context, baseURL *url.URL) (string, error) {
-	args := m.Called(ctx, baseURL)
+func (m *MockWebAnalyzerService) AnalyzeWebsite(ctx context.Context, baseURL *url.URL, callbackURL string, options contract.AnalysisOptions) (string, error) {
+	args := m.Called(ctx, baseURL, callbackURL, options)
 	return args.String(0), args.Error(1)
 }
 
+func (m *MockWebAnalyzerService) ListAnalyses(ctx context.Context, req contract.ListAnalysesRequest) (*contract.ListAnalysesResponse, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*contract.ListAnalysesResponse), args.Error(1)
+}
+
+func (m *MockWebAnalyzerService) DiffAnalyses(ctx context.Context, req contract.AnalysisDiffRequest) (*contract.AnalysisDiffResponse, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*contract.AnalysisDiffResponse), args.Error(1)
+}
+
+func (m *MockWebAnalyzerService) WatchAnalysis(ctx context.Context, analyzeId string) (*contract.WebAnalyzeResponse, <-chan contract.AnalysisEvent, error) {
+	args := m.Called(ctx, analyzeId)
+	if args.Get(0) == nil {
+		return nil, nil, args.Error(2)
+	}
+	return args.Get(0).(*contract.WebAnalyzeResponse), args.Get(1).(<-chan contract.AnalysisEvent), args.Error(2)
+}
+
+func (m *MockWebAnalyzerService) AnalyzeBatch(ctx context.Context, baseURLs []*url.URL, callbackURL string, options contract.AnalysisOptions) (*contract.BatchAnalyzeResponse, error) {
+	args := m.Called(ctx, baseURLs, callbackURL, options)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*contract.BatchAnalyzeResponse), args.Error(1)
+}
+
+func (m *MockWebAnalyzerService) GetBatch(ctx context.Context, batchId string) (*contract.BatchResponse, error) {
+	args := m.Called(ctx, batchId)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*contract.BatchResponse), args.Error(1)
+}
+
+func (m *MockWebAnalyzerService) CrawlSite(ctx context.Context, baseURL *url.URL, source string, maxDepth int, maxPages int, options contract.AnalysisOptions) (*contract.CrawlAnalyzeResponse, error) {
+	args := m.Called(ctx, baseURL, source, maxDepth, maxPages, options)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*contract.CrawlAnalyzeResponse), args.Error(1)
+}
+
+func (m *MockWebAnalyzerService) GetCrawl(ctx context.Context, crawlId string) (*contract.CrawlResponse, error) {
+	args := m.Called(ctx, crawlId)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*contract.CrawlResponse), args.Error(1)
+}
+
+func (m *MockWebAnalyzerService) GetWebhookDeliveries(ctx context.Context, analyzeId string) (*contract.WebhookDeliveriesResponse, error) {
+	args := m.Called(ctx, analyzeId)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*contract.WebhookDeliveriesResponse), args.Error(1)
+}
+
 func (m *MockWebAnalyzerService) UpdateAnalysisStatus(analyzeId string, status string, errorDescription string) {
 	m.Called(analyzeId, status, errorDescription)
 }
 
+func (m *MockWebAnalyzerService) CancelAnalysis(ctx context.Context, analyzeId string) error {
+	args := m.Called(ctx, analyzeId)
+	return args.Error(0)
+}
+
+func (m *MockWebAnalyzerService) RecoverStaleAnalyses(policy string) error {
+	args := m.Called(policy)
+	return args.Error(0)
+}
+
+func (m *MockWebAnalyzerService) Start() {
+	m.Called()
+}
+
+func (m *MockWebAnalyzerService) Shutdown(ctx context.Context) error {
+	args := m.Called(ctx)
+	return args.Error(0)
+}
+
 func TestSetupRouter(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	log := logger.Get("debug")
@@ -4,15 +4,24 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/url"
+	"strconv"
+	"strings"
+	"time"
 	"web-analyzer-api/app/internal/contract"
 	"web-analyzer-api/app/internal/core"
 	"web-analyzer-api/app/internal/core/apperror"
+	webanalyzer "web-analyzer-api/app/internal/core/web_analyzer"
+	"web-analyzer-api/app/internal/repository"
 	"web-analyzer-api/app/internal/util"
 	"web-analyzer-api/app/internal/util/logger"
 
 	"github.com/gin-gonic/gin"
+	"golang.org/x/net/http/httpguts"
 )
 
+// streamHeartbeatInterval keeps idle event streams open through proxies.
+const streamHeartbeatInterval = 15 * time.Second
+
 type WebAnalyzerHandler struct {
 	log                *logger.Logger
 	webAnalyzerService core.WebAnalyzerService
@@ -30,8 +39,35 @@ func (h WebAnalyzerHandler) RegisterRoutes(v1 *gin.RouterGroup) {
 	v1.POST("/web-analyzer/analyze",
 		h.analyzeWebsite)
 
+	v1.POST("/web-analyzer/batches",
+		h.analyzeBatch)
+
+	v1.GET("/web-analyzer/batches/:batch_id",
+		h.getBatch)
+
+	v1.POST("/web-analyzer/crawls",
+		h.crawlSite)
+
+	v1.GET("/web-analyzer/crawls/:crawl_id",
+		h.getCrawl)
+
+	v1.GET("/web-analyzer/analyses",
+		h.listAnalyses)
+
+	v1.GET("/web-analyzer/diff",
+		h.diffAnalyses)
+
 	v1.GET("/web-analyzer/:analyze_id/analyze",
 		h.getAnalyzeData)
+
+	v1.GET("/web-analyzer/:analyze_id/events",
+		h.streamAnalysisEvents)
+
+	v1.GET("/web-analyzer/:analyze_id/webhooks",
+		h.getWebhookDeliveries)
+
+	v1.DELETE("/web-analyzer/:analyze_id/analyze",
+		h.cancelAnalysis)
 }
 
 func (h WebAnalyzerHandler) analyzeWebsite(c *gin.Context) {
@@ -41,7 +77,7 @@ func (h WebAnalyzerHandler) analyzeWebsite(c *gin.Context) {
 		return
 	}
 
-	result, err := h.webAnalyzerService.AnalyzeWebsite(c.Request.Context(), parsedURL)
+	result, err := h.webAnalyzerService.AnalyzeWebsite(c.Request.Context(), parsedURL, req.CallbackURL, req.Options)
 
 	if err != nil {
 		util.SetRequestError(c, err, h.log)
@@ -51,6 +87,128 @@ func (h WebAnalyzerHandler) analyzeWebsite(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"analyze_id": result})
 }
 
+func (h WebAnalyzerHandler) analyzeBatch(c *gin.Context) {
+	var req contract.BatchAnalyzeRequest
+	if err := json.NewDecoder(c.Request.Body).Decode(&req); err != nil {
+		util.SetRequestError(c, apperror.BadRequest("Invalid request body: "+err.Error()), h.log)
+		return
+	}
+
+	if len(req.URLs) == 0 {
+		util.SetRequestError(c, apperror.BadRequest("Provide at least one URL"), h.log)
+		return
+	}
+	if len(req.URLs) > webanalyzer.MaxBatchSize {
+		util.SetRequestError(c, apperror.BadRequest("Too many URLs. A batch accepts at most "+strconv.Itoa(webanalyzer.MaxBatchSize)), h.log)
+		return
+	}
+
+	parsedURLs := make([]*url.URL, len(req.URLs))
+	for i, rawURL := range req.URLs {
+		parsedURL, err := parseTargetURL(rawURL)
+		if err != nil {
+			util.SetRequestError(c, apperror.BadRequest("Invalid URL at index "+strconv.Itoa(i)+". Please provide valid URLs with scheme (http:// or https://)"), h.log)
+			return
+		}
+		parsedURLs[i] = parsedURL
+	}
+
+	if err := validateCallbackURL(req.CallbackURL); err != nil {
+		util.SetRequestError(c, err, h.log)
+		return
+	}
+
+	if err := validateOptions(req.Options); err != nil {
+		util.SetRequestError(c, err, h.log)
+		return
+	}
+
+	result, err := h.webAnalyzerService.AnalyzeBatch(c.Request.Context(), parsedURLs, req.CallbackURL, req.Options)
+
+	if err != nil {
+		util.SetRequestError(c, err, h.log)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func (h WebAnalyzerHandler) getBatch(c *gin.Context) {
+	batchId := c.Param("batch_id")
+	if batchId == "" {
+		util.SetRequestError(c, apperror.BadRequest("Batch id cannot be empty"), h.log)
+		return
+	}
+
+	result, err := h.webAnalyzerService.GetBatch(c.Request.Context(), batchId)
+
+	if err != nil {
+		util.SetRequestError(c, err, h.log)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func (h WebAnalyzerHandler) crawlSite(c *gin.Context) {
+	var req contract.CrawlRequest
+	if err := json.NewDecoder(c.Request.Body).Decode(&req); err != nil {
+		util.SetRequestError(c, apperror.BadRequest("Invalid request body: "+err.Error()), h.log)
+		return
+	}
+
+	parsedURL, err := parseTargetURL(req.URL)
+	if err != nil {
+		util.SetRequestError(c, err, h.log)
+		return
+	}
+
+	if req.Source != "" && req.Source != webanalyzer.CrawlSourceLinks && req.Source != webanalyzer.CrawlSourceSitemap {
+		util.SetRequestError(c, apperror.BadRequest("Invalid source. Allowed values: links, sitemap"), h.log)
+		return
+	}
+
+	if req.MaxDepth < 0 {
+		util.SetRequestError(c, apperror.BadRequest("Invalid max_depth. Must not be negative"), h.log)
+		return
+	}
+	if req.MaxPages < 0 {
+		util.SetRequestError(c, apperror.BadRequest("Invalid max_pages. Must not be negative"), h.log)
+		return
+	}
+
+	if err := validateOptions(req.Options); err != nil {
+		util.SetRequestError(c, err, h.log)
+		return
+	}
+
+	result, err := h.webAnalyzerService.CrawlSite(c.Request.Context(), parsedURL, req.Source, req.MaxDepth, req.MaxPages, req.Options)
+
+	if err != nil {
+		util.SetRequestError(c, err, h.log)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func (h WebAnalyzerHandler) getCrawl(c *gin.Context) {
+	crawlId := c.Param("crawl_id")
+	if crawlId == "" {
+		util.SetRequestError(c, apperror.BadRequest("Crawl id cannot be empty"), h.log)
+		return
+	}
+
+	result, err := h.webAnalyzerService.GetCrawl(c.Request.Context(), crawlId)
+
+	if err != nil {
+		util.SetRequestError(c, err, h.log)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
 func (h WebAnalyzerHandler) getAnalyzeData(c *gin.Context) {
 	analyzeId := c.Param("analyze_id")
 	if analyzeId == "" {
@@ -68,21 +226,265 @@ func (h WebAnalyzerHandler) getAnalyzeData(c *gin.Context) {
 	c.JSON(http.StatusOK, result)
 }
 
+func (h WebAnalyzerHandler) listAnalyses(c *gin.Context) {
+	req, err := parseListAnalysesQuery(c)
+	if err != nil {
+		util.SetRequestError(c, err, h.log)
+		return
+	}
+
+	result, err := h.webAnalyzerService.ListAnalyses(c.Request.Context(), req)
+
+	if err != nil {
+		util.SetRequestError(c, err, h.log)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func parseListAnalysesQuery(c *gin.Context) (contract.ListAnalysesRequest, error) {
+	req := contract.ListAnalysesRequest{
+		URL:    strings.TrimSpace(c.Query("url")),
+		Host:   strings.TrimSpace(c.Query("host")),
+		Sort:   c.DefaultQuery("sort", repository.SortByCreatedAt),
+		Order:  c.DefaultQuery("order", webanalyzer.SortOrderDesc),
+		Cursor: c.Query("cursor"),
+	}
+
+	if status := c.Query("status"); status != "" {
+		for _, s := range strings.Split(status, ",") {
+			if s = strings.TrimSpace(s); s != "" {
+				req.Statuses = append(req.Statuses, s)
+			}
+		}
+	}
+
+	if req.Sort != repository.SortByCreatedAt && req.Sort != repository.SortByUpdatedAt {
+		return req, apperror.BadRequest("Invalid sort field. Allowed values: created_at, updated_at")
+	}
+
+	if req.Order != webanalyzer.SortOrderAsc && req.Order != webanalyzer.SortOrderDesc {
+		return req, apperror.BadRequest("Invalid sort order. Allowed values: asc, desc")
+	}
+
+	if limit := c.Query("limit"); limit != "" {
+		value, err := strconv.Atoi(limit)
+		if err != nil || value < 1 || value > webanalyzer.MaxListLimit {
+			return req, apperror.BadRequest("Invalid limit. Must be between 1 and " + strconv.Itoa(webanalyzer.MaxListLimit))
+		}
+		req.Limit = value
+	}
+
+	var err error
+	if req.CreatedFrom, err = parseTimeQuery(c, "created_from"); err != nil {
+		return req, err
+	}
+	if req.CreatedTo, err = parseTimeQuery(c, "created_to"); err != nil {
+		return req, err
+	}
+	if req.CreatedFrom != nil && req.CreatedTo != nil && !req.CreatedFrom.Before(*req.CreatedTo) {
+		return req, apperror.BadRequest("created_from must be before created_to")
+	}
+
+	return req, nil
+}
+
+func parseTimeQuery(c *gin.Context, name string) (*time.Time, error) {
+	value := c.Query(name)
+	if value == "" {
+		return nil, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, apperror.BadRequest("Invalid " + name + ". Use RFC3339 format, e.g. 2024-01-02T15:04:05Z")
+	}
+	return &t, nil
+}
+
+func (h WebAnalyzerHandler) diffAnalyses(c *gin.Context) {
+	req := contract.AnalysisDiffRequest{
+		FromID: strings.TrimSpace(c.Query("from")),
+		ToID:   strings.TrimSpace(c.Query("to")),
+	}
+
+	if rawURL := strings.TrimSpace(c.Query("url")); rawURL != "" {
+		if req.FromID != "" || req.ToID != "" {
+			util.SetRequestError(c, apperror.BadRequest("Provide either url or from and to, not both"), h.log)
+			return
+		}
+
+		parsedURL, err := url.Parse(rawURL)
+		if err != nil || parsedURL.Scheme == "" || parsedURL.Host == "" {
+			util.SetRequestError(c, apperror.BadRequest("Invalid URL format. Please provide a valid URL with scheme (http:// or https://)"), h.log)
+			return
+		}
+		// Analyses store the URL in its parsed form, so compare against the same representation
+		req.URL = parsedURL.String()
+	} else if req.FromID == "" || req.ToID == "" {
+		util.SetRequestError(c, apperror.BadRequest("Provide either url or both from and to analyze ids"), h.log)
+		return
+	} else if req.FromID == req.ToID {
+		util.SetRequestError(c, apperror.BadRequest("from and to must be different analyses"), h.log)
+		return
+	}
+
+	result, err := h.webAnalyzerService.DiffAnalyses(c.Request.Context(), req)
+
+	if err != nil {
+		util.SetRequestError(c, err, h.log)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func (h WebAnalyzerHandler) streamAnalysisEvents(c *gin.Context) {
+	analyzeId := c.Param("analyze_id")
+	if analyzeId == "" {
+		util.SetRequestError(c, apperror.BadRequest("Analyze id cannot be empty"), h.log)
+		return
+	}
+
+	ctx := c.Request.Context()
+	snapshot, events, err := h.webAnalyzerService.WatchAnalysis(ctx, analyzeId)
+
+	if err != nil {
+		util.SetRequestError(c, err, h.log)
+		return
+	}
+
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	// Disable response buffering in the nginx reverse proxy
+	c.Header("X-Accel-Buffering", "no")
+
+	c.SSEvent(webanalyzer.EventSnapshot, snapshot)
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(streamHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			h.log.Debug("Event stream closed by client: analyzeId - " + analyzeId)
+			return
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			c.SSEvent(event.Type, event)
+			c.Writer.Flush()
+		case <-heartbeat.C:
+			c.Writer.WriteString(": keep-alive\n\n")
+			c.Writer.Flush()
+		}
+	}
+}
+
+func (h WebAnalyzerHandler) getWebhookDeliveries(c *gin.Context) {
+	analyzeId := c.Param("analyze_id")
+	if analyzeId == "" {
+		util.SetRequestError(c, apperror.BadRequest("Analyze id cannot be empty"), h.log)
+		return
+	}
+
+	result, err := h.webAnalyzerService.GetWebhookDeliveries(c.Request.Context(), analyzeId)
+
+	if err != nil {
+		util.SetRequestError(c, err, h.log)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func (h WebAnalyzerHandler) cancelAnalysis(c *gin.Context) {
+	analyzeId := c.Param("analyze_id")
+	if analyzeId == "" {
+		util.SetRequestError(c, apperror.BadRequest("Analyze id cannot be empty"), h.log)
+		return
+	}
+
+	err := h.webAnalyzerService.CancelAnalysis(c.Request.Context(), analyzeId)
+
+	if err != nil {
+		util.SetRequestError(c, err, h.log)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"analyze_id": analyzeId})
+}
+
 func (h *WebAnalyzerHandler) validateRequest(c *gin.Context, req *contract.WebAnalyzeRequest) (*url.URL, bool) {
 	if err := json.NewDecoder(c.Request.Body).Decode(req); err != nil {
 		util.SetRequestError(c, apperror.BadRequest("Invalid request body: "+err.Error()), h.log)
 		return nil, false
 	}
 
-	// Validate URL format
-	parsedURL, err := url.Parse(req.URL)
+	parsedURL, err := parseTargetURL(req.URL)
 	if err != nil {
-		util.SetRequestError(c, apperror.BadRequest("Invalid URL format: "+err.Error()), h.log)
+		util.SetRequestError(c, err, h.log)
 		return nil, false
 	}
-	if parsedURL.Scheme == "" || parsedURL.Host == "" {
-		util.SetRequestError(c, apperror.BadRequest("Invalid URL format. Please provide a valid URL with scheme (http:// or https://)"), h.log)
+
+	if err := validateCallbackURL(req.CallbackURL); err != nil {
+		util.SetRequestError(c, err, h.log)
+		return nil, false
+	}
+
+	if err := validateOptions(req.Options); err != nil {
+		util.SetRequestError(c, err, h.log)
 		return nil, false
 	}
 	return parsedURL, true
 }
+
+func parseTargetURL(rawURL string) (*url.URL, error) {
+	parsedURL, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, apperror.BadRequest("Invalid URL format: " + err.Error())
+	}
+	if parsedURL.Scheme == "" || parsedURL.Host == "" {
+		return nil, apperror.BadRequest("Invalid URL format. Please provide a valid URL with scheme (http:// or https://)")
+	}
+	return parsedURL, nil
+}
+
+func validateCallbackURL(rawURL string) error {
+	if rawURL == "" {
+		return nil
+	}
+
+	callbackURL, err := url.Parse(rawURL)
+	if err != nil || (callbackURL.Scheme != "http" && callbackURL.Scheme != "https") || callbackURL.Host == "" {
+		return apperror.BadRequest("Invalid callback URL. Please provide a valid http:// or https:// URL")
+	}
+	return nil
+}
+
+func validateOptions(options contract.AnalysisOptions) error {
+	if options.MaxLinks < 0 {
+		return apperror.BadRequest("Invalid options.max_links. Must not be negative")
+	}
+
+	if options.LinkWorkers < 0 || options.LinkWorkers > webanalyzer.MaxLinkWorkers {
+		return apperror.BadRequest("Invalid options.link_workers. Must be between 1 and " + strconv.Itoa(webanalyzer.MaxLinkWorkers))
+	}
+
+	maxTimeoutMs := int(webanalyzer.MaxLinkTimeout.Milliseconds())
+	if options.LinkTimeoutMs < 0 || options.LinkTimeoutMs > maxTimeoutMs {
+		return apperror.BadRequest("Invalid options.link_timeout_ms. Must be between 1 and " + strconv.Itoa(maxTimeoutMs))
+	}
+
+	if len(options.UserAgent) > webanalyzer.MaxUserAgentLength {
+		return apperror.BadRequest("Invalid options.user_agent. Must be at most " + strconv.Itoa(webanalyzer.MaxUserAgentLength) + " characters")
+	}
+	if !httpguts.ValidHeaderFieldValue(options.UserAgent) {
+		return apperror.BadRequest("Invalid options.user_agent. Control characters are not allowed")
+	}
+
+	return nil
+}
@@ -8,9 +8,11 @@ import (
 	"net/http/httptest"
 	"net/url"
 	"testing"
+	"time"
 	"web-analyzer-api/app/internal/api/middleware"
 	"web-analyzer-api/app/internal/contract"
 	"web-analyzer-api/app/internal/core/apperror"
+	webanalyzer "web-analyzer-api/app/internal/core/web_analyzer"
 	"web-analyzer-api/app/internal/util/logger"
 
 	"github.com/gin-gonic/gin"
@@ -31,15 +33,98 @@ func (m *MockWebAnalyzerService) GetAnalyzeData(ctx context.Context, analyzeId s
 	return args.Get(0).(*contract.WebAnalyzeResponse), args.Error(1)
 }
 
-func (m *MockWebAnalyzerService) AnalyzeWebsite(ctx context.Context, baseURL *url.URL) (string, error) {
-	args := m.Called(ctx, baseURL)
+func (m *MockWebAnalyzerService) AnalyzeWebsite(ctx context.Context, baseURL *url.URL, callbackURL string, options contract.AnalysisOptions) (string, error) {
+	args := m.Called(ctx, baseURL, callbackURL, options)
 	return args.String(0), args.Error(1)
 }
 
+func (m *MockWebAnalyzerService) ListAnalyses(ctx context.Context, req contract.ListAnalysesRequest) (*contract.ListAnalysesResponse, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*contract.ListAnalysesResponse), args.Error(1)
+}
+
+func (m *MockWebAnalyzerService) DiffAnalyses(ctx context.Context, req contract.AnalysisDiffRequest) (*contract.AnalysisDiffResponse, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*contract.AnalysisDiffResponse), args.Error(1)
+}
+
+func (m *MockWebAnalyzerService) WatchAnalysis(ctx context.Context, analyzeId string) (*contract.WebAnalyzeResponse, <-chan contract.AnalysisEvent, error) {
+	args := m.Called(ctx, analyzeId)
+	if args.Get(0) == nil {
+		return nil, nil, args.Error(2)
+	}
+	return args.Get(0).(*contract.WebAnalyzeResponse), args.Get(1).(<-chan contract.AnalysisEvent), args.Error(2)
+}
+
+func (m *MockWebAnalyzerService) AnalyzeBatch(ctx context.Context, baseURLs []*url.URL, callbackURL string, options contract.AnalysisOptions) (*contract.BatchAnalyzeResponse, error) {
+	args := m.Called(ctx, baseURLs, callbackURL, options)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*contract.BatchAnalyzeResponse), args.Error(1)
+}
+
+func (m *MockWebAnalyzerService) GetBatch(ctx context.Context, batchId string) (*contract.BatchResponse, error) {
+	args := m.Called(ctx, batchId)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*contract.BatchResponse), args.Error(1)
+}
+
+func (m *MockWebAnalyzerService) CrawlSite(ctx context.Context, baseURL *url.URL, source string, maxDepth int, maxPages int, options contract.AnalysisOptions) (*contract.CrawlAnalyzeResponse, error) {
+	args := m.Called(ctx, baseURL, source, maxDepth, maxPages, options)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*contract.CrawlAnalyzeResponse), args.Error(1)
+}
+
+func (m *MockWebAnalyzerService) GetCrawl(ctx context.Context, crawlId string) (*contract.CrawlResponse, error) {
+	args := m.Called(ctx, crawlId)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*contract.CrawlResponse), args.Error(1)
+}
+
+func (m *MockWebAnalyzerService) GetWebhookDeliveries(ctx context.Context, analyzeId string) (*contract.WebhookDeliveriesResponse, error) {
+	args := m.Called(ctx, analyzeId)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*contract.WebhookDeliveriesResponse), args.Error(1)
+}
+
 func (m *MockWebAnalyzerService) UpdateAnalysisStatus(analyzeId string, status string, errorDescription string) {
 	m.Called(analyzeId, status, errorDescription)
 }
 
+func (m *MockWebAnalyzerService) CancelAnalysis(ctx context.Context, analyzeId string) error {
+	args := m.Called(ctx, analyzeId)
+	return args.Error(0)
+}
+
+func (m *MockWebAnalyzerService) RecoverStaleAnalyses(policy string) error {
+	args := m.Called(policy)
+	return args.Error(0)
+}
+
+func (m *MockWebAnalyzerService) Start() {
+	m.Called()
+}
+
+func (m *MockWebAnalyzerService) Shutdown(ctx context.Context) error {
+	args := m.Called(ctx)
+	return args.Error(0)
+}
+
 // Setup Test
 func setupTest() (service *MockWebAnalyzerService, handler *WebAnalyzerHandler, router *gin.Engine) {
 	log := logger.Get("debug")
@@ -65,7 +150,7 @@ func TestWebAnalyzerHandler_AnalyzeWebsite(t *testing.T) {
 		resp := httptest.NewRecorder()
 
 		parsedURL, _ := url.Parse("http://my-app.com")
-		mockService.On("AnalyzeWebsite", mock.Anything, parsedURL).Return("test-id", nil)
+		mockService.On("AnalyzeWebsite", mock.Anything, parsedURL, "", contract.AnalysisOptions{}).Return("test-id", nil)
 
 		router.ServeHTTP(resp, req)
 
@@ -105,6 +190,94 @@ func TestWebAnalyzerHandler_AnalyzeWebsite(t *testing.T) {
 		assert.Equal(t, http.StatusBadRequest, resp.Code)
 	})
 
+	t.Run("With callback URL", func(t *testing.T) {
+		mockService, handler, router := setupTest()
+		router.POST("/analyze", handler.analyzeWebsite)
+
+		reqBody := contract.WebAnalyzeRequest{URL: "http://my-app.com", CallbackURL: "https://ci.test/hook"}
+		body, _ := json.Marshal(reqBody)
+		req, _ := http.NewRequest(http.MethodPost, "/analyze", bytes.NewBuffer(body))
+		req.Header.Set("x-api-key", "dev-key-123")
+		resp := httptest.NewRecorder()
+
+		mockService.On("AnalyzeWebsite", mock.Anything, mock.Anything, "https://ci.test/hook", contract.AnalysisOptions{}).Return("test-id", nil)
+
+		router.ServeHTTP(resp, req)
+
+		assert.Equal(t, http.StatusOK, resp.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("Invalid callback URL", func(t *testing.T) {
+		mockService, handler, router := setupTest()
+		router.POST("/analyze", handler.analyzeWebsite)
+
+		for _, callbackURL := range []string{"ci.test/hook", "ftp://ci.test/hook", "https://"} {
+			reqBody := contract.WebAnalyzeRequest{URL: "http://my-app.com", CallbackURL: callbackURL}
+			body, _ := json.Marshal(reqBody)
+			req, _ := http.NewRequest(http.MethodPost, "/analyze", bytes.NewBuffer(body))
+			req.Header.Set("x-api-key", "dev-key-123")
+			resp := httptest.NewRecorder()
+
+			router.ServeHTTP(resp, req)
+
+			assert.Equal(t, http.StatusBadRequest, resp.Code, callbackURL)
+		}
+		mockService.AssertNotCalled(t, "AnalyzeWebsite", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("With options", func(t *testing.T) {
+		mockService, handler, router := setupTest()
+		router.POST("/analyze", handler.analyzeWebsite)
+
+		body := `{"url": "http://my-app.com", "options": {"check_links": false, "link_workers": 4, "link_timeout_ms": 2000, "user_agent": "audit-bot/1.0"}}`
+		req, _ := http.NewRequest(http.MethodPost, "/analyze", bytes.NewBufferString(body))
+		req.Header.Set("x-api-key", "dev-key-123")
+		resp := httptest.NewRecorder()
+
+		checkLinks := false
+		expected := contract.AnalysisOptions{CheckLinks: &checkLinks, LinkWorkers: 4, LinkTimeoutMs: 2000, UserAgent: "audit-bot/1.0"}
+		mockService.On("AnalyzeWebsite", mock.Anything, mock.Anything, "", expected).Return("test-id", nil)
+
+		router.ServeHTTP(resp, req)
+
+		assert.Equal(t, http.StatusOK, resp.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("Invalid options", func(t *testing.T) {
+		tests := []struct {
+			name    string
+			options string
+			message string
+		}{
+			{"Negative max links", `{"max_links": -1}`, "options.max_links"},
+			{"Too many workers", `{"link_workers": 51}`, "options.link_workers"},
+			{"Negative timeout", `{"link_timeout_ms": -5}`, "options.link_timeout_ms"},
+			{"Timeout above limit", `{"link_timeout_ms": 60001}`, "options.link_timeout_ms"},
+			{"User agent with newline", `{"user_agent": "bot\r\nX-Injected: 1"}`, "options.user_agent"},
+			{"Wrong type", `{"check_links": "no"}`, "Invalid request body"},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				mockService, handler, router := setupTest()
+				router.POST("/analyze", handler.analyzeWebsite)
+
+				body := `{"url": "http://my-app.com", "options": ` + tt.options + `}`
+				req, _ := http.NewRequest(http.MethodPost, "/analyze", bytes.NewBufferString(body))
+				req.Header.Set("x-api-key", "dev-key-123")
+				resp := httptest.NewRecorder()
+
+				router.ServeHTTP(resp, req)
+
+				assert.Equal(t, http.StatusBadRequest, resp.Code)
+				assert.Contains(t, resp.Body.String(), tt.message)
+				mockService.AssertNotCalled(t, "AnalyzeWebsite", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+			})
+		}
+	})
+
 	t.Run("Service Error", func(t *testing.T) {
 		mockService, handler, router := setupTest()
 		router.POST("/analyze", handler.analyzeWebsite)
@@ -115,12 +288,30 @@ func TestWebAnalyzerHandler_AnalyzeWebsite(t *testing.T) {
 		req.Header.Set("x-api-key", "dev-key-123")
 		resp := httptest.NewRecorder()
 
-		mockService.On("AnalyzeWebsite", mock.Anything, mock.Anything).Return("", apperror.InternalServerError("service error"))
+		mockService.On("AnalyzeWebsite", mock.Anything, mock.Anything, mock.Anything, contract.AnalysisOptions{}).Return("", apperror.InternalServerError("service error"))
 
 		router.ServeHTTP(resp, req)
 
 		assert.Equal(t, http.StatusInternalServerError, resp.Code)
 	})
+
+	t.Run("Queue Full", func(t *testing.T) {
+		mockService, handler, router := setupTest()
+		router.POST("/analyze", handler.analyzeWebsite)
+
+		reqBody := contract.WebAnalyzeRequest{URL: "http://example.com"}
+		body, _ := json.Marshal(reqBody)
+		req, _ := http.NewRequest(http.MethodPost, "/analyze", bytes.NewBuffer(body))
+		req.Header.Set("x-api-key", "dev-key-123")
+		resp := httptest.NewRecorder()
+
+		mockService.On("AnalyzeWebsite", mock.Anything, mock.Anything, mock.Anything, contract.AnalysisOptions{}).Return("", apperror.ServiceUnavailable("queue is full", 30*time.Second))
+
+		router.ServeHTTP(resp, req)
+
+		assert.Equal(t, http.StatusServiceUnavailable, resp.Code)
+		assert.Equal(t, "30", resp.Header().Get("Retry-After"))
+	})
 }
 
 func TestWebAnalyzerHandler_GetAnalyzeData(t *testing.T) {
@@ -193,3 +384,550 @@ func TestWebAnalyzerHandler_GetAnalyzeData(t *testing.T) {
 		assert.Equal(t, http.StatusUnauthorized, resp.Code)
 	})
 }
+
+func TestWebAnalyzerHandler_CancelAnalysis(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("Success", func(t *testing.T) {
+		mockService, handler, router := setupTest()
+		router.DELETE("/analyze/:analyze_id", handler.cancelAnalysis)
+
+		mockService.On("CancelAnalysis", mock.Anything, "test-id").Return(nil)
+
+		req, _ := http.NewRequest(http.MethodDelete, "/analyze/test-id", nil)
+		req.Header.Set("x-api-key", "dev-key-123")
+		resp := httptest.NewRecorder()
+
+		router.ServeHTTP(resp, req)
+
+		assert.Equal(t, http.StatusAccepted, resp.Code)
+		var result map[string]string
+		json.Unmarshal(resp.Body.Bytes(), &result)
+		assert.Equal(t, "test-id", result["analyze_id"])
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("Already finished", func(t *testing.T) {
+		mockService, handler, router := setupTest()
+		router.DELETE("/analyze/:analyze_id", handler.cancelAnalysis)
+
+		mockService.On("CancelAnalysis", mock.Anything, "test-id").Return(apperror.Conflict("already finished"))
+
+		req, _ := http.NewRequest(http.MethodDelete, "/analyze/test-id", nil)
+		req.Header.Set("x-api-key", "dev-key-123")
+		resp := httptest.NewRecorder()
+
+		router.ServeHTTP(resp, req)
+
+		assert.Equal(t, http.StatusConflict, resp.Code)
+	})
+
+	t.Run("Not found Error", func(t *testing.T) {
+		mockService, handler, router := setupTest()
+		router.DELETE("/analyze/:analyze_id", handler.cancelAnalysis)
+
+		mockService.On("CancelAnalysis", mock.Anything, "test-id").Return(apperror.NotFound("not found"))
+
+		req, _ := http.NewRequest(http.MethodDelete, "/analyze/test-id", nil)
+		req.Header.Set("x-api-key", "dev-key-123")
+		resp := httptest.NewRecorder()
+
+		router.ServeHTTP(resp, req)
+
+		assert.Equal(t, http.StatusNotFound, resp.Code)
+	})
+}
+
+func TestWebAnalyzerHandler_ListAnalyses(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("Success", func(t *testing.T) {
+		mockService, handler, router := setupTest()
+		router.GET("/analyses", handler.listAnalyses)
+
+		createdFrom := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		expectedReq := contract.ListAnalysesRequest{
+			Statuses:    []string{"success", "failed"},
+			Host:        "example.com",
+			CreatedFrom: &createdFrom,
+			Sort:        "updated_at",
+			Order:       "asc",
+			Cursor:      "abc",
+			Limit:       5,
+		}
+		mockService.On("ListAnalyses", mock.Anything, expectedReq).Return(&contract.ListAnalysesResponse{
+			Items:      []contract.WebAnalyzeResponse{{AnalyzeID: "test-id", URL: "http://example.com", Status: "success"}},
+			NextCursor: "next",
+		}, nil)
+
+		req, _ := http.NewRequest(http.MethodGet, "/analyses?status=success,failed&host=example.com&created_from=2024-01-01T00:00:00Z&sort=updated_at&order=asc&cursor=abc&limit=5", nil)
+		req.Header.Set("x-api-key", "dev-key-123")
+		resp := httptest.NewRecorder()
+
+		router.ServeHTTP(resp, req)
+
+		assert.Equal(t, http.StatusOK, resp.Code)
+		var result contract.ListAnalysesResponse
+		json.Unmarshal(resp.Body.Bytes(), &result)
+		assert.Len(t, result.Items, 1)
+		assert.Equal(t, "test-id", result.Items[0].AnalyzeID)
+		assert.Equal(t, "next", result.NextCursor)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("Defaults", func(t *testing.T) {
+		mockService, handler, router := setupTest()
+		router.GET("/analyses", handler.listAnalyses)
+
+		expectedReq := contract.ListAnalysesRequest{Sort: "created_at", Order: "desc"}
+		mockService.On("ListAnalyses", mock.Anything, expectedReq).Return(&contract.ListAnalysesResponse{Items: []contract.WebAnalyzeResponse{}}, nil)
+
+		req, _ := http.NewRequest(http.MethodGet, "/analyses", nil)
+		req.Header.Set("x-api-key", "dev-key-123")
+		resp := httptest.NewRecorder()
+
+		router.ServeHTTP(resp, req)
+
+		assert.Equal(t, http.StatusOK, resp.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	invalidQueries := map[string]string{
+		"Invalid sort":        "sort=title",
+		"Invalid order":       "order=up",
+		"Invalid limit":       "limit=0",
+		"Limit too large":     "limit=1000",
+		"Invalid time":        "created_from=yesterday",
+		"Inverted time range": "created_from=2024-02-01T00:00:00Z&created_to=2024-01-01T00:00:00Z",
+	}
+	for name, query := range invalidQueries {
+		t.Run(name, func(t *testing.T) {
+			mockService, handler, router := setupTest()
+			router.GET("/analyses", handler.listAnalyses)
+
+			req, _ := http.NewRequest(http.MethodGet, "/analyses?"+query, nil)
+			req.Header.Set("x-api-key", "dev-key-123")
+			resp := httptest.NewRecorder()
+
+			router.ServeHTTP(resp, req)
+
+			assert.Equal(t, http.StatusBadRequest, resp.Code)
+			mockService.AssertNotCalled(t, "ListAnalyses", mock.Anything, mock.Anything)
+		})
+	}
+
+	t.Run("Service Error", func(t *testing.T) {
+		mockService, handler, router := setupTest()
+		router.GET("/analyses", handler.listAnalyses)
+
+		mockService.On("ListAnalyses", mock.Anything, mock.Anything).Return(nil, apperror.BadRequest("Invalid cursor"))
+
+		req, _ := http.NewRequest(http.MethodGet, "/analyses?cursor=bad", nil)
+		req.Header.Set("x-api-key", "dev-key-123")
+		resp := httptest.NewRecorder()
+
+		router.ServeHTTP(resp, req)
+
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+	})
+}
+
+func TestWebAnalyzerHandler_DiffAnalyses(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("By analyze ids", func(t *testing.T) {
+		mockService, handler, router := setupTest()
+		router.GET("/diff", handler.diffAnalyses)
+
+		mockService.On("DiffAnalyses", mock.Anything, contract.AnalysisDiffRequest{FromID: "a", ToID: "b"}).Return(&contract.AnalysisDiffResponse{
+			From:    contract.AnalysisRef{AnalyzeID: "a"},
+			To:      contract.AnalysisRef{AnalyzeID: "b"},
+			Changed: true,
+			Title:   &contract.StringChange{From: "Old", To: "New"},
+		}, nil)
+
+		req, _ := http.NewRequest(http.MethodGet, "/diff?from=a&to=b", nil)
+		req.Header.Set("x-api-key", "dev-key-123")
+		resp := httptest.NewRecorder()
+
+		router.ServeHTTP(resp, req)
+
+		assert.Equal(t, http.StatusOK, resp.Code)
+		var result contract.AnalysisDiffResponse
+		json.Unmarshal(resp.Body.Bytes(), &result)
+		assert.True(t, result.Changed)
+		assert.Equal(t, "New", result.Title.To)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("By URL", func(t *testing.T) {
+		mockService, handler, router := setupTest()
+		router.GET("/diff", handler.diffAnalyses)
+
+		mockService.On("DiffAnalyses", mock.Anything, contract.AnalysisDiffRequest{URL: "https://my-app.com/path"}).Return(&contract.AnalysisDiffResponse{}, nil)
+
+		req, _ := http.NewRequest(http.MethodGet, "/diff?url="+url.QueryEscape("https://my-app.com/path"), nil)
+		req.Header.Set("x-api-key", "dev-key-123")
+		resp := httptest.NewRecorder()
+
+		router.ServeHTTP(resp, req)
+
+		assert.Equal(t, http.StatusOK, resp.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	invalidQueries := map[string]string{
+		"Missing parameters": "",
+		"Missing to":         "from=a",
+		"Same analysis":      "from=a&to=a",
+		"URL and ids":        "url=https://my-app.com&from=a&to=b",
+		"Invalid URL":        "url=my-app",
+	}
+	for name, query := range invalidQueries {
+		t.Run(name, func(t *testing.T) {
+			mockService, handler, router := setupTest()
+			router.GET("/diff", handler.diffAnalyses)
+
+			req, _ := http.NewRequest(http.MethodGet, "/diff?"+query, nil)
+			req.Header.Set("x-api-key", "dev-key-123")
+			resp := httptest.NewRecorder()
+
+			router.ServeHTTP(resp, req)
+
+			assert.Equal(t, http.StatusBadRequest, resp.Code)
+			mockService.AssertNotCalled(t, "DiffAnalyses", mock.Anything, mock.Anything)
+		})
+	}
+
+	t.Run("Not enough runs", func(t *testing.T) {
+		mockService, handler, router := setupTest()
+		router.GET("/diff", handler.diffAnalyses)
+
+		mockService.On("DiffAnalyses", mock.Anything, mock.Anything).Return(nil, apperror.NotFound("not enough runs"))
+
+		req, _ := http.NewRequest(http.MethodGet, "/diff?url=https://my-app.com", nil)
+		req.Header.Set("x-api-key", "dev-key-123")
+		resp := httptest.NewRecorder()
+
+		router.ServeHTTP(resp, req)
+
+		assert.Equal(t, http.StatusNotFound, resp.Code)
+	})
+}
+
+func TestWebAnalyzerHandler_StreamAnalysisEvents(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("Success", func(t *testing.T) {
+		mockService, handler, router := setupTest()
+		router.GET("/analyze/:analyze_id/events", handler.streamAnalysisEvents)
+
+		events := make(chan contract.AnalysisEvent, 2)
+		events <- contract.AnalysisEvent{Type: "links_progress", AnalyzeID: "test-id", Progress: &contract.LinkProgress{Checked: 1, Total: 2}}
+		events <- contract.AnalysisEvent{Type: "completed", AnalyzeID: "test-id", Status: "success"}
+		close(events)
+		mockService.On("WatchAnalysis", mock.Anything, "test-id").Return(&contract.WebAnalyzeResponse{AnalyzeID: "test-id", Status: "pending"}, (<-chan contract.AnalysisEvent)(events), nil)
+
+		req, _ := http.NewRequest(http.MethodGet, "/analyze/test-id/events", nil)
+		req.Header.Set("x-api-key", "dev-key-123")
+		resp := httptest.NewRecorder()
+
+		router.ServeHTTP(resp, req)
+
+		assert.Equal(t, http.StatusOK, resp.Code)
+		assert.Contains(t, resp.Header().Get("Content-Type"), "text/event-stream")
+		body := resp.Body.String()
+		assert.Contains(t, body, "event:snapshot\n")
+		assert.Contains(t, body, `"checked":1,"total":2`)
+		assert.Contains(t, body, "event:completed\n")
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("Not found Error", func(t *testing.T) {
+		mockService, handler, router := setupTest()
+		router.GET("/analyze/:analyze_id/events", handler.streamAnalysisEvents)
+
+		mockService.On("WatchAnalysis", mock.Anything, "test-id").Return(nil, nil, apperror.NotFound("not found"))
+
+		req, _ := http.NewRequest(http.MethodGet, "/analyze/test-id/events", nil)
+		req.Header.Set("x-api-key", "dev-key-123")
+		resp := httptest.NewRecorder()
+
+		router.ServeHTTP(resp, req)
+
+		assert.Equal(t, http.StatusNotFound, resp.Code)
+	})
+}
+
+func TestWebAnalyzerHandler_GetWebhookDeliveries(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("Success", func(t *testing.T) {
+		mockService, handler, router := setupTest()
+		router.GET("/analyze/:analyze_id/webhooks", handler.getWebhookDeliveries)
+
+		mockService.On("GetWebhookDeliveries", mock.Anything, "test-id").Return(&contract.WebhookDeliveriesResponse{
+			AnalyzeID:   "test-id",
+			CallbackURL: "https://ci.test/hook",
+			Deliveries:  []contract.WebhookDelivery{{Event: "analysis.completed", Attempt: 1, StatusCode: 200, Success: true}},
+		}, nil)
+
+		req, _ := http.NewRequest(http.MethodGet, "/analyze/test-id/webhooks", nil)
+		req.Header.Set("x-api-key", "dev-key-123")
+		resp := httptest.NewRecorder()
+
+		router.ServeHTTP(resp, req)
+
+		assert.Equal(t, http.StatusOK, resp.Code)
+		var result contract.WebhookDeliveriesResponse
+		json.Unmarshal(resp.Body.Bytes(), &result)
+		assert.Equal(t, "https://ci.test/hook", result.CallbackURL)
+		assert.Len(t, result.Deliveries, 1)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("Not found Error", func(t *testing.T) {
+		mockService, handler, router := setupTest()
+		router.GET("/analyze/:analyze_id/webhooks", handler.getWebhookDeliveries)
+
+		mockService.On("GetWebhookDeliveries", mock.Anything, "test-id").Return(nil, apperror.NotFound("not found"))
+
+		req, _ := http.NewRequest(http.MethodGet, "/analyze/test-id/webhooks", nil)
+		req.Header.Set("x-api-key", "dev-key-123")
+		resp := httptest.NewRecorder()
+
+		router.ServeHTTP(resp, req)
+
+		assert.Equal(t, http.StatusNotFound, resp.Code)
+	})
+}
+
+func TestWebAnalyzerHandler_AnalyzeBatch(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	postBatch := func(router *gin.Engine, body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(http.MethodPost, "/batches", bytes.NewBufferString(body))
+		req.Header.Set("x-api-key", "dev-key-123")
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		return resp
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		mockService, handler, router := setupTest()
+		router.POST("/batches", handler.analyzeBatch)
+
+		first, _ := url.Parse("http://a.test")
+		second, _ := url.Parse("http://b.test/landing")
+		mockService.On("AnalyzeBatch", mock.Anything, []*url.URL{first, second}, "https://ci.test/hook", contract.AnalysisOptions{}).Return(&contract.BatchAnalyzeResponse{
+			BatchID:    "batch-id",
+			AnalyzeIDs: []string{"id-1", "id-2"},
+		}, nil)
+
+		resp := postBatch(router, `{"urls": ["http://a.test", "http://b.test/landing"], "callback_url": "https://ci.test/hook"}`)
+
+		assert.Equal(t, http.StatusOK, resp.Code)
+		var result contract.BatchAnalyzeResponse
+		json.Unmarshal(resp.Body.Bytes(), &result)
+		assert.Equal(t, "batch-id", result.BatchID)
+		assert.Equal(t, []string{"id-1", "id-2"}, result.AnalyzeIDs)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("Invalid requests", func(t *testing.T) {
+		tooMany := make([]string, webanalyzer.MaxBatchSize+1)
+		for i := range tooMany {
+			tooMany[i] = "http://a.test"
+		}
+		tooManyBody, _ := json.Marshal(contract.BatchAnalyzeRequest{URLs: tooMany})
+
+		tests := []struct {
+			name    string
+			body    string
+			message string
+		}{
+			{"Invalid JSON", "invalid-json", "Invalid request body"},
+			{"No URLs", `{"urls": []}`, "at least one URL"},
+			{"Too many URLs", string(tooManyBody), "Too many URLs"},
+			{"Invalid URL", `{"urls": ["http://a.test", "not-a-url"]}`, "index 1"},
+			{"Invalid callback URL", `{"urls": ["http://a.test"], "callback_url": "ftp://ci.test"}`, "Invalid callback URL"},
+			{"Invalid options", `{"urls": ["http://a.test"], "options": {"link_workers": 100}}`, "options.link_workers"},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				mockService, handler, router := setupTest()
+				router.POST("/batches", handler.analyzeBatch)
+
+				resp := postBatch(router, tt.body)
+
+				assert.Equal(t, http.StatusBadRequest, resp.Code)
+				assert.Contains(t, resp.Body.String(), tt.message)
+				mockService.AssertNotCalled(t, "AnalyzeBatch", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+			})
+		}
+	})
+
+	t.Run("Queue full", func(t *testing.T) {
+		mockService, handler, router := setupTest()
+		router.POST("/batches", handler.analyzeBatch)
+
+		mockService.On("AnalyzeBatch", mock.Anything, mock.Anything, "", contract.AnalysisOptions{}).Return(nil, apperror.ServiceUnavailable("Analysis queue is full, please retry later", 30*time.Second))
+
+		resp := postBatch(router, `{"urls": ["http://a.test"]}`)
+
+		assert.Equal(t, http.StatusServiceUnavailable, resp.Code)
+		assert.Equal(t, "30", resp.Header().Get("Retry-After"))
+	})
+}
+
+func TestWebAnalyzerHandler_GetBatch(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("Success", func(t *testing.T) {
+		mockService, handler, router := setupTest()
+		router.GET("/batches/:batch_id", handler.getBatch)
+
+		mockService.On("GetBatch", mock.Anything, "batch-id").Return(&contract.BatchResponse{
+			BatchID: "batch-id",
+			Summary: contract.BatchSummary{Total: 1, Statuses: map[string]int{"success": 1}, BrokenLinks: 2},
+			Items:   []contract.BatchItem{{AnalyzeID: "id-1", URL: "http://a.test", Status: "success", InaccessibleLinks: 2}},
+		}, nil)
+
+		req, _ := http.NewRequest(http.MethodGet, "/batches/batch-id", nil)
+		req.Header.Set("x-api-key", "dev-key-123")
+		resp := httptest.NewRecorder()
+
+		router.ServeHTTP(resp, req)
+
+		assert.Equal(t, http.StatusOK, resp.Code)
+		var result contract.BatchResponse
+		json.Unmarshal(resp.Body.Bytes(), &result)
+		assert.Equal(t, "batch-id", result.BatchID)
+		assert.Equal(t, 2, result.Summary.BrokenLinks)
+		assert.Len(t, result.Items, 1)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("Not found Error", func(t *testing.T) {
+		mockService, handler, router := setupTest()
+		router.GET("/batches/:batch_id", handler.getBatch)
+
+		mockService.On("GetBatch", mock.Anything, "missing").Return(nil, apperror.NotFound("Batch not found"))
+
+		req, _ := http.NewRequest(http.MethodGet, "/batches/missing", nil)
+		req.Header.Set("x-api-key", "dev-key-123")
+		resp := httptest.NewRecorder()
+
+		router.ServeHTTP(resp, req)
+
+		assert.Equal(t, http.StatusNotFound, resp.Code)
+	})
+}
+
+func TestWebAnalyzerHandler_CrawlSite(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	postCrawl := func(router *gin.Engine, body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(http.MethodPost, "/crawls", bytes.NewBufferString(body))
+		req.Header.Set("x-api-key", "dev-key-123")
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		return resp
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		mockService, handler, router := setupTest()
+		router.POST("/crawls", handler.crawlSite)
+
+		startURL, _ := url.Parse("http://a.test")
+		mockService.On("CrawlSite", mock.Anything, startURL, "", 2, 50, contract.AnalysisOptions{}).Return(&contract.CrawlAnalyzeResponse{
+			CrawlID:   "crawl-id",
+			AnalyzeID: "id-1",
+		}, nil)
+
+		resp := postCrawl(router, `{"url": "http://a.test", "max_depth": 2, "max_pages": 50}`)
+
+		assert.Equal(t, http.StatusOK, resp.Code)
+		var result contract.CrawlAnalyzeResponse
+		json.Unmarshal(resp.Body.Bytes(), &result)
+		assert.Equal(t, "crawl-id", result.CrawlID)
+		assert.Equal(t, "id-1", result.AnalyzeID)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("Invalid requests", func(t *testing.T) {
+		tests := []struct {
+			name    string
+			body    string
+			message string
+		}{
+			{"Invalid JSON", "invalid-json", "Invalid request body"},
+			{"Invalid URL", `{"url": "not-a-url"}`, "Invalid URL format"},
+			{"Unknown source", `{"url": "http://a.test", "source": "feed"}`, "Invalid source"},
+			{"Negative depth", `{"url": "http://a.test", "max_depth": -1}`, "max_depth"},
+			{"Negative page limit", `{"url": "http://a.test", "max_pages": -1}`, "max_pages"},
+			{"Invalid options", `{"url": "http://a.test", "options": {"link_workers": 100}}`, "options.link_workers"},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				mockService, handler, router := setupTest()
+				router.POST("/crawls", handler.crawlSite)
+
+				resp := postCrawl(router, tt.body)
+
+				assert.Equal(t, http.StatusBadRequest, resp.Code)
+				assert.Contains(t, resp.Body.String(), tt.message)
+				mockService.AssertNotCalled(t, "CrawlSite", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+			})
+		}
+	})
+}
+
+func TestWebAnalyzerHandler_GetCrawl(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("Success", func(t *testing.T) {
+		mockService, handler, router := setupTest()
+		router.GET("/crawls/:crawl_id", handler.getCrawl)
+
+		mockService.On("GetCrawl", mock.Anything, "crawl-id").Return(&contract.CrawlResponse{
+			CrawlID:     "crawl-id",
+			Status:      "completed",
+			Done:        true,
+			Summary:     contract.CrawlSummary{Pages: 1, Statuses: map[string]int{"success": 1}},
+			Pages:       []contract.CrawlPage{{AnalyzeID: "id-1", URL: "http://a.test/", Status: "success", Links: []string{}}},
+			Unreachable: []string{},
+		}, nil)
+
+		req, _ := http.NewRequest(http.MethodGet, "/crawls/crawl-id", nil)
+		req.Header.Set("x-api-key", "dev-key-123")
+		resp := httptest.NewRecorder()
+
+		router.ServeHTTP(resp, req)
+
+		assert.Equal(t, http.StatusOK, resp.Code)
+		var result contract.CrawlResponse
+		json.Unmarshal(resp.Body.Bytes(), &result)
+		assert.Equal(t, "crawl-id", result.CrawlID)
+		assert.True(t, result.Done)
+		assert.Len(t, result.Pages, 1)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("Not found Error", func(t *testing.T) {
+		mockService, handler, router := setupTest()
+		router.GET("/crawls/:crawl_id", handler.getCrawl)
+
+		mockService.On("GetCrawl", mock.Anything, "missing").Return(nil, apperror.NotFound("Crawl not found"))
+
+		req, _ := http.NewRequest(http.MethodGet, "/crawls/missing", nil)
+		req.Header.Set("x-api-key", "dev-key-123")
+		resp := httptest.NewRecorder()
+
+		router.ServeHTTP(resp, req)
+
+		assert.Equal(t, http.StatusNotFound, resp.Code)
+	})
+}
@@ -0,0 +1,135 @@
+package config
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	StorageDriverMemory = "memory"
+	StorageDriverSQLite = "sqlite"
+)
+
+type Config struct {
+	StorageDriver string
+	SQLitePath    string
+
+	AnalysisWorkers   int
+	AnalysisQueueSize int
+
+	ShutdownGracePeriod time.Duration
+	StaleAnalysisPolicy string
+
+	WebhookSecret         string
+	WebhookMaxAttempts    int
+	WebhookInitialBackoff time.Duration
+	WebhookMaxBackoff     time.Duration
+	WebhookTimeout        time.Duration
+
+	AllowPrivateNetworks bool
+	FetchAllowedHosts    []string
+
+	PageConnectTimeout time.Duration
+	PageHeaderTimeout  time.Duration
+	PageFetchTimeout   time.Duration
+	PageMaxBodyBytes   int64
+
+	RobotsUserAgent string
+	RobotsCacheTTL  time.Duration
+
+	LinkHostConcurrency int
+	LinkHostRate        int
+	LinkHostBurst       int
+	LinkMaxRetries      int
+	LinkMaxRetryAfter   time.Duration
+	LinkCacheSize       int
+	LinkCacheSuccessTTL time.Duration
+	LinkCacheFailureTTL time.Duration
+	CrawlMaxDepth       int
+	CrawlMaxPages       int
+}
+
+func Load() Config {
+	return Config{
+		StorageDriver: getEnv("STORAGE_DRIVER", StorageDriverSQLite),
+		SQLitePath:    getEnv("SQLITE_PATH", "web-analyzer.db"),
+
+		AnalysisWorkers:   getEnvInt("ANALYSIS_WORKERS", 4),
+		AnalysisQueueSize: getEnvInt("ANALYSIS_QUEUE_SIZE", 100),
+
+		ShutdownGracePeriod: getEnvDuration("SHUTDOWN_GRACE_PERIOD", 30*time.Second),
+		StaleAnalysisPolicy: getEnv("STALE_ANALYSIS_POLICY", "resume"),
+
+		WebhookSecret:         getEnv("WEBHOOK_SECRET", ""),
+		WebhookMaxAttempts:    getEnvInt("WEBHOOK_MAX_ATTEMPTS", 5),
+		WebhookInitialBackoff: getEnvDuration("WEBHOOK_INITIAL_BACKOFF", time.Second),
+		WebhookMaxBackoff:     getEnvDuration("WEBHOOK_MAX_BACKOFF", time.Minute),
+		WebhookTimeout:        getEnvDuration("WEBHOOK_TIMEOUT", 10*time.Second),
+
+		AllowPrivateNetworks: getEnvBool("ALLOW_PRIVATE_NETWORKS", false),
+		FetchAllowedHosts:    getEnvList("FETCH_ALLOWED_HOSTS"),
+
+		PageConnectTimeout: getEnvDuration("PAGE_CONNECT_TIMEOUT", 10*time.Second),
+		PageHeaderTimeout:  getEnvDuration("PAGE_HEADER_TIMEOUT", 15*time.Second),
+		PageFetchTimeout:   getEnvDuration("PAGE_FETCH_TIMEOUT", 30*time.Second),
+		PageMaxBodyBytes:   int64(getEnvInt("PAGE_MAX_BODY_BYTES", 10<<20)),
+
+		RobotsUserAgent: getEnv("ROBOTS_USER_AGENT", "web-analyzer"),
+		RobotsCacheTTL:  getEnvDuration("ROBOTS_CACHE_TTL", time.Hour),
+
+		LinkHostConcurrency: getEnvInt("LINK_HOST_CONCURRENCY", 4),
+		LinkHostRate:        getEnvInt("LINK_HOST_RATE", 10),
+		LinkHostBurst:       getEnvInt("LINK_HOST_BURST", 10),
+		LinkMaxRetries:      getEnvInt("LINK_MAX_RETRIES", 2),
+		LinkMaxRetryAfter:   getEnvDuration("LINK_MAX_RETRY_AFTER", 30*time.Second),
+		LinkCacheSize:       getEnvInt("LINK_CACHE_SIZE", 10000),
+		LinkCacheSuccessTTL: getEnvDuration("LINK_CACHE_SUCCESS_TTL", 10*time.Minute),
+		LinkCacheFailureTTL: getEnvDuration("LINK_CACHE_FAILURE_TTL", time.Minute),
+		CrawlMaxDepth:       getEnvInt("CRAWL_MAX_DEPTH", 3),
+		CrawlMaxPages:       getEnvInt("CRAWL_MAX_PAGES", 100),
+	}
+}
+
+func getEnv(key string, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
+
+func getEnvInt(key string, defaultValue int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+
+func getEnvBool(key string, defaultValue bool) bool {
+	value, err := strconv.ParseBool(os.Getenv(key))
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+
+// getEnvList splits a comma separated variable, dropping empty entries.
+func getEnvList(key string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	value, err := time.ParseDuration(os.Getenv(key))
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
@@ -0,0 +1,163 @@
+package config
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoad(t *testing.T) {
+	t.Run("Default values", func(t *testing.T) {
+		os.Unsetenv("STORAGE_DRIVER")
+		os.Unsetenv("SQLITE_PATH")
+		os.Unsetenv("ANALYSIS_WORKERS")
+		os.Unsetenv("ANALYSIS_QUEUE_SIZE")
+		os.Unsetenv("SHUTDOWN_GRACE_PERIOD")
+		os.Unsetenv("STALE_ANALYSIS_POLICY")
+		os.Unsetenv("WEBHOOK_SECRET")
+		os.Unsetenv("WEBHOOK_MAX_ATTEMPTS")
+		os.Unsetenv("WEBHOOK_INITIAL_BACKOFF")
+		os.Unsetenv("WEBHOOK_MAX_BACKOFF")
+		os.Unsetenv("WEBHOOK_TIMEOUT")
+		os.Unsetenv("ALLOW_PRIVATE_NETWORKS")
+		os.Unsetenv("FETCH_ALLOWED_HOSTS")
+		os.Unsetenv("PAGE_CONNECT_TIMEOUT")
+		os.Unsetenv("PAGE_HEADER_TIMEOUT")
+		os.Unsetenv("PAGE_FETCH_TIMEOUT")
+		os.Unsetenv("PAGE_MAX_BODY_BYTES")
+		os.Unsetenv("ROBOTS_USER_AGENT")
+		os.Unsetenv("ROBOTS_CACHE_TTL")
+		os.Unsetenv("LINK_HOST_CONCURRENCY")
+		os.Unsetenv("LINK_HOST_RATE")
+		os.Unsetenv("LINK_HOST_BURST")
+		os.Unsetenv("LINK_MAX_RETRIES")
+		os.Unsetenv("LINK_MAX_RETRY_AFTER")
+		os.Unsetenv("LINK_CACHE_SIZE")
+		os.Unsetenv("LINK_CACHE_SUCCESS_TTL")
+		os.Unsetenv("LINK_CACHE_FAILURE_TTL")
+		os.Unsetenv("CRAWL_MAX_DEPTH")
+		os.Unsetenv("CRAWL_MAX_PAGES")
+
+		cfg := Load()
+
+		assert.Equal(t, StorageDriverSQLite, cfg.StorageDriver)
+		assert.Equal(t, "web-analyzer.db", cfg.SQLitePath)
+		assert.Equal(t, 4, cfg.AnalysisWorkers)
+		assert.Equal(t, 100, cfg.AnalysisQueueSize)
+		assert.Equal(t, 30*time.Second, cfg.ShutdownGracePeriod)
+		assert.Equal(t, "resume", cfg.StaleAnalysisPolicy)
+		assert.Empty(t, cfg.WebhookSecret)
+		assert.Equal(t, 5, cfg.WebhookMaxAttempts)
+		assert.Equal(t, time.Second, cfg.WebhookInitialBackoff)
+		assert.Equal(t, time.Minute, cfg.WebhookMaxBackoff)
+		assert.Equal(t, 10*time.Second, cfg.WebhookTimeout)
+		assert.False(t, cfg.AllowPrivateNetworks)
+		assert.Empty(t, cfg.FetchAllowedHosts)
+		assert.Equal(t, 10*time.Second, cfg.PageConnectTimeout)
+		assert.Equal(t, 15*time.Second, cfg.PageHeaderTimeout)
+		assert.Equal(t, 30*time.Second, cfg.PageFetchTimeout)
+		assert.Equal(t, int64(10<<20), cfg.PageMaxBodyBytes)
+		assert.Equal(t, "web-analyzer", cfg.RobotsUserAgent)
+		assert.Equal(t, time.Hour, cfg.RobotsCacheTTL)
+		assert.Equal(t, 4, cfg.LinkHostConcurrency)
+		assert.Equal(t, 10, cfg.LinkHostRate)
+		assert.Equal(t, 10, cfg.LinkHostBurst)
+		assert.Equal(t, 2, cfg.LinkMaxRetries)
+		assert.Equal(t, 30*time.Second, cfg.LinkMaxRetryAfter)
+		assert.Equal(t, 10000, cfg.LinkCacheSize)
+		assert.Equal(t, 10*time.Minute, cfg.LinkCacheSuccessTTL)
+		assert.Equal(t, time.Minute, cfg.LinkCacheFailureTTL)
+		assert.Equal(t, 3, cfg.CrawlMaxDepth)
+		assert.Equal(t, 100, cfg.CrawlMaxPages)
+	})
+
+	t.Run("Custom values", func(t *testing.T) {
+		os.Setenv("STORAGE_DRIVER", StorageDriverMemory)
+		os.Setenv("SQLITE_PATH", "/tmp/test.db")
+		os.Setenv("ANALYSIS_WORKERS", "8")
+		os.Setenv("ANALYSIS_QUEUE_SIZE", "500")
+		os.Setenv("SHUTDOWN_GRACE_PERIOD", "1m")
+		os.Setenv("STALE_ANALYSIS_POLICY", "fail")
+		os.Setenv("WEBHOOK_SECRET", "secret")
+		os.Setenv("WEBHOOK_MAX_ATTEMPTS", "3")
+		os.Setenv("ALLOW_PRIVATE_NETWORKS", "true")
+		os.Setenv("FETCH_ALLOWED_HOSTS", "staging.internal, 10.20.0.0/16,,")
+		os.Setenv("PAGE_FETCH_TIMEOUT", "5s")
+		os.Setenv("PAGE_MAX_BODY_BYTES", "1024")
+		os.Setenv("ROBOTS_USER_AGENT", "acme-checker/2.0")
+		os.Setenv("ROBOTS_CACHE_TTL", "10m")
+		os.Setenv("LINK_HOST_CONCURRENCY", "2")
+		os.Setenv("LINK_HOST_RATE", "5")
+		os.Setenv("LINK_MAX_RETRY_AFTER", "5s")
+		os.Setenv("LINK_CACHE_SIZE", "500")
+		os.Setenv("LINK_CACHE_SUCCESS_TTL", "30m")
+		os.Setenv("LINK_CACHE_FAILURE_TTL", "10s")
+		os.Setenv("CRAWL_MAX_DEPTH", "5")
+		os.Setenv("CRAWL_MAX_PAGES", "250")
+		defer func() {
+			os.Unsetenv("LINK_HOST_CONCURRENCY")
+			os.Unsetenv("LINK_HOST_RATE")
+			os.Unsetenv("LINK_MAX_RETRY_AFTER")
+			os.Unsetenv("LINK_CACHE_SIZE")
+			os.Unsetenv("LINK_CACHE_SUCCESS_TTL")
+			os.Unsetenv("LINK_CACHE_FAILURE_TTL")
+			os.Unsetenv("CRAWL_MAX_DEPTH")
+			os.Unsetenv("CRAWL_MAX_PAGES")
+			os.Unsetenv("ROBOTS_USER_AGENT")
+			os.Unsetenv("ROBOTS_CACHE_TTL")
+			os.Unsetenv("PAGE_FETCH_TIMEOUT")
+			os.Unsetenv("PAGE_MAX_BODY_BYTES")
+			os.Unsetenv("ALLOW_PRIVATE_NETWORKS")
+			os.Unsetenv("FETCH_ALLOWED_HOSTS")
+			os.Unsetenv("WEBHOOK_SECRET")
+			os.Unsetenv("WEBHOOK_MAX_ATTEMPTS")
+			os.Unsetenv("STORAGE_DRIVER")
+			os.Unsetenv("SQLITE_PATH")
+			os.Unsetenv("ANALYSIS_WORKERS")
+			os.Unsetenv("ANALYSIS_QUEUE_SIZE")
+			os.Unsetenv("SHUTDOWN_GRACE_PERIOD")
+			os.Unsetenv("STALE_ANALYSIS_POLICY")
+		}()
+
+		cfg := Load()
+
+		assert.Equal(t, StorageDriverMemory, cfg.StorageDriver)
+		assert.Equal(t, "/tmp/test.db", cfg.SQLitePath)
+		assert.Equal(t, 8, cfg.AnalysisWorkers)
+		assert.Equal(t, 500, cfg.AnalysisQueueSize)
+		assert.Equal(t, time.Minute, cfg.ShutdownGracePeriod)
+		assert.Equal(t, "fail", cfg.StaleAnalysisPolicy)
+		assert.Equal(t, "secret", cfg.WebhookSecret)
+		assert.Equal(t, 3, cfg.WebhookMaxAttempts)
+		assert.True(t, cfg.AllowPrivateNetworks)
+		assert.Equal(t, []string{"staging.internal", "10.20.0.0/16"}, cfg.FetchAllowedHosts)
+		assert.Equal(t, 5*time.Second, cfg.PageFetchTimeout)
+		assert.Equal(t, int64(1024), cfg.PageMaxBodyBytes)
+		assert.Equal(t, "acme-checker/2.0", cfg.RobotsUserAgent)
+		assert.Equal(t, 10*time.Minute, cfg.RobotsCacheTTL)
+		assert.Equal(t, 2, cfg.LinkHostConcurrency)
+		assert.Equal(t, 5, cfg.LinkHostRate)
+		assert.Equal(t, 5*time.Second, cfg.LinkMaxRetryAfter)
+		assert.Equal(t, 500, cfg.LinkCacheSize)
+		assert.Equal(t, 30*time.Minute, cfg.LinkCacheSuccessTTL)
+		assert.Equal(t, 10*time.Second, cfg.LinkCacheFailureTTL)
+		assert.Equal(t, 5, cfg.CrawlMaxDepth)
+		assert.Equal(t, 250, cfg.CrawlMaxPages)
+	})
+}
+
+func TestGetEnvInt(t *testing.T) {
+	os.Setenv("TEST_INT", "not-a-number")
+	defer os.Unsetenv("TEST_INT")
+
+	assert.Equal(t, 7, getEnvInt("TEST_INT", 7))
+}
+
+func TestGetEnvDuration(t *testing.T) {
+	os.Setenv("TEST_DURATION", "30")
+	defer os.Unsetenv("TEST_DURATION")
+
+	assert.Equal(t, time.Second, getEnvDuration("TEST_DURATION", time.Second))
+}
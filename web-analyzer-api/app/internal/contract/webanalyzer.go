@@ -1,28 +1,323 @@
 package contract
 
+import "time"
+
 type WebAnalyzeRequest struct {
-	URL string `json:"url" validate:"required,url"`
+	URL         string          `json:"url" validate:"required,url"`
+	CallbackURL string          `json:"callback_url,omitempty"`
+	Options     AnalysisOptions `json:"options"`
+}
+
+// AnalysisOptions tunes a single analysis. Omitted fields use the server defaults.
+type AnalysisOptions struct {
+	CheckLinks           *bool  `json:"check_links,omitempty"`
+	MaxLinks             int    `json:"max_links,omitempty"`
+	LinkWorkers          int    `json:"link_workers,omitempty"`
+	LinkTimeoutMs        int    `json:"link_timeout_ms,omitempty"`
+	FollowRedirects      *bool  `json:"follow_redirects,omitempty"`
+	IncludeExternalLinks *bool  `json:"include_external_links,omitempty"`
+	UserAgent            string `json:"user_agent,omitempty"`
+	RespectRobots        *bool  `json:"respect_robots,omitempty"`
 }
 
 type WebAnalyzeResponse struct {
-	URL              string         `json:"url"`
-	HTMLVersion      string         `json:"html_version"`
-	Title            string         `json:"title"`
-	Headings         map[string]int `json:"headings"`
-	Links            LinkAnalysis   `json:"links"`
-	HasLoginForm     bool           `json:"has_login_form"`
-	Status           string         `json:"status"`
-	ErrorDescription string         `json:"error_description"`
+	AnalyzeID        string          `json:"analyze_id"`
+	URL              string          `json:"url"`
+	HTMLVersion      string          `json:"html_version"`
+	Title            string          `json:"title"`
+	Headings         map[string]int  `json:"headings"`
+	Links            LinkAnalysis    `json:"links"`
+	HasLoginForm     bool            `json:"has_login_form"`
+	SEO              *SEOAnalysis    `json:"seo,omitempty"`
+	Status           string          `json:"status"`
+	ErrorDescription string          `json:"error_description"`
+	FinalURL         string          `json:"final_url,omitempty"`
+	Redirects        []RedirectHop   `json:"redirects,omitempty"`
+	Encoding         string          `json:"encoding,omitempty"`
+	Options          AnalysisOptions `json:"options"`
+	QueuePosition    int             `json:"queue_position,omitempty"`
+	CreatedAt        *time.Time      `json:"created_at,omitempty"`
+	UpdatedAt        *time.Time      `json:"updated_at,omitempty"`
+}
+
+type BatchAnalyzeRequest struct {
+	URLs        []string        `json:"urls"`
+	CallbackURL string          `json:"callback_url,omitempty"`
+	Options     AnalysisOptions `json:"options"`
+}
+
+type BatchAnalyzeResponse struct {
+	BatchID    string   `json:"batch_id"`
+	AnalyzeIDs []string `json:"analyze_ids"`
+}
+
+type BatchResponse struct {
+	BatchID   string       `json:"batch_id"`
+	Done      bool         `json:"done"`
+	CreatedAt time.Time    `json:"created_at"`
+	Summary   BatchSummary `json:"summary"`
+	Items     []BatchItem  `json:"items"`
+}
+
+type BatchItem struct {
+	AnalyzeID         string `json:"analyze_id"`
+	URL               string `json:"url"`
+	Status            string `json:"status"`
+	ErrorDescription  string `json:"error_description,omitempty"`
+	QueuePosition     int    `json:"queue_position,omitempty"`
+	InaccessibleLinks int    `json:"inaccessible_links"`
+	HasLoginForm      bool   `json:"has_login_form"`
+}
+
+// BatchSummary aggregates the results of the successful analyses of a batch.
+type BatchSummary struct {
+	Total              int            `json:"total"`
+	Statuses           map[string]int `json:"statuses"`
+	BrokenLinks        int            `json:"broken_links"`
+	PagesWithLoginForm int            `json:"pages_with_login_form"`
+	HTMLVersions       map[string]int `json:"html_versions"`
+}
+
+// CrawlRequest starts a crawl of the site of URL. A zero MaxDepth or MaxPages means the server maximum.
+// Source is links, the default, to follow links from URL or sitemap to analyze the pages the sitemaps list.
+type CrawlRequest struct {
+	URL      string          `json:"url"`
+	Source   string          `json:"source,omitempty"`
+	MaxDepth int             `json:"max_depth,omitempty"`
+	MaxPages int             `json:"max_pages,omitempty"`
+	Options  AnalysisOptions `json:"options"`
+}
+
+type CrawlAnalyzeResponse struct {
+	CrawlID   string `json:"crawl_id"`
+	AnalyzeID string `json:"analyze_id"`
+}
+
+// CrawlResponse is the site-level report of a crawl. The links of its pages make up the link graph of the site.
+// Unreachable lists the pages that could not be analyzed or that no chain of analyzed pages links to from URL.
+type CrawlResponse struct {
+	CrawlID     string        `json:"crawl_id"`
+	URL         string        `json:"url"`
+	Source      string        `json:"source"`
+	Status      string        `json:"status"`
+	Done        bool          `json:"done"`
+	MaxDepth    int           `json:"max_depth"`
+	MaxPages    int           `json:"max_pages"`
+	CreatedAt   time.Time     `json:"created_at"`
+	UpdatedAt   *time.Time    `json:"updated_at,omitempty"`
+	Summary     CrawlSummary  `json:"summary"`
+	Pages       []CrawlPage   `json:"pages"`
+	Unreachable []string      `json:"unreachable"`
+	Sitemap     *CrawlSitemap `json:"sitemap,omitempty"`
+}
+
+// CrawlSitemap reports on the sitemaps of a sitemap crawl. NotAnalyzed counts the listed pages the crawl left
+// out. Errors and Redirects are the listed pages that failed or redirected, NotInSitemap the internal pages
+// linked from analyzed pages that the sitemaps leave out.
+type CrawlSitemap struct {
+	Sitemaps     []string           `json:"sitemaps"`
+	Pages        int                `json:"pages"`
+	NotAnalyzed  int                `json:"not_analyzed"`
+	Errors       []CrawlSitemapPage `json:"errors"`
+	Redirects    []CrawlSitemapPage `json:"redirects"`
+	NotInSitemap []string           `json:"not_in_sitemap"`
+}
+
+type CrawlSitemapPage struct {
+	AnalyzeID  string `json:"analyze_id"`
+	URL        string `json:"url"`
+	StatusCode int    `json:"status_code,omitempty"`
+	FinalURL   string `json:"final_url,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+type CrawlSummary struct {
+	Pages       int            `json:"pages"`
+	Statuses    map[string]int `json:"statuses"`
+	BrokenLinks int            `json:"broken_links"`
+	Unreachable int            `json:"unreachable"`
+}
+
+// CrawlPage is a crawled page with the outcome of its analysis and the internal pages it links to.
+type CrawlPage struct {
+	AnalyzeID         string   `json:"analyze_id"`
+	URL               string   `json:"url"`
+	Depth             int      `json:"depth"`
+	Status            string   `json:"status"`
+	ErrorDescription  string   `json:"error_description,omitempty"`
+	Title             string   `json:"title"`
+	HTMLVersion       string   `json:"html_version"`
+	InaccessibleLinks int      `json:"inaccessible_links"`
+	HasLoginForm      bool     `json:"has_login_form"`
+	Links             []string `json:"links"`
+}
+
+type ListAnalysesRequest struct {
+	Statuses    []string
+	URL         string
+	Host        string
+	CreatedFrom *time.Time
+	CreatedTo   *time.Time
+	Sort        string
+	Order       string
+	Cursor      string
+	Limit       int
+}
+
+type ListAnalysesResponse struct {
+	Items      []WebAnalyzeResponse `json:"items"`
+	NextCursor string               `json:"next_cursor,omitempty"`
 }
 
 type LinkAnalysis struct {
-	Internal            int                `json:"internal"`
-	External            int                `json:"external"`
-	Inaccessible        int                `json:"inaccessible"`
-	InaccessibleDetails []InaccessibleLink `json:"inaccessible_details"`
+	Internal             int                      `json:"internal"`
+	External             int                      `json:"external"`
+	InternalOccurrences  int                      `json:"internal_occurrences"`
+	ExternalOccurrences  int                      `json:"external_occurrences"`
+	Inaccessible         int                      `json:"inaccessible"`
+	InaccessibleDetails  []InaccessibleLink       `json:"inaccessible_details"`
+	Redirected           int                      `json:"redirected"`
+	RedirectedDetails    []LinkRedirect           `json:"redirected_details"`
+	ErrorCategories      map[string]int           `json:"error_categories,omitempty"`
+	SkippedRobots        int                      `json:"skipped_robots"`
+	SkippedRobotsDetails []string                 `json:"skipped_robots_details"`
+	Resources            map[string]ResourceStats `json:"resources,omitempty"`
+}
+
+// SEOAnalysis is the SEO metadata of a page. TitleCount is the number of <title> elements, Canonical and the
+// hreflang URLs are absolute, and Open Graph and Twitter Card properties are keyed without their og: or twitter:
+// prefix.
+type SEOAnalysis struct {
+	TitleCount  int                 `json:"title_count"`
+	Description string              `json:"description"`
+	Robots      string              `json:"robots"`
+	Canonical   string              `json:"canonical"`
+	Hreflang    []HreflangAlternate `json:"hreflang"`
+	OpenGraph   map[string]string   `json:"open_graph"`
+	TwitterCard map[string]string   `json:"twitter_card"`
+	Viewport    string              `json:"viewport"`
+	Lang        string              `json:"lang"`
+	Issues      []SEOIssue          `json:"issues"`
+}
+
+type HreflangAlternate struct {
+	Lang string `json:"lang"`
+	URL  string `json:"url"`
+}
+
+// SEOIssue is a failed SEO check. Code is one of title_missing, title_duplicate, title_length,
+// description_missing, description_length, multiple_h1, canonical_elsewhere or noindex.
+type SEOIssue struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
 }
 
+// ResourceStats counts the unique resources of one kind: anchor, image, script, stylesheet, iframe, preload
+// or form.
+type ResourceStats struct {
+	Internal int `json:"internal"`
+	External int `json:"external"`
+	Broken   int `json:"broken"`
+}
+
+// InaccessibleLink is a link that failed its check. Category is one of blocked, dns, refused, timeout, tls,
+// redirect_loop, too_many_redirects, http_4xx, http_5xx, network or missing_fragment.
 type InaccessibleLink struct {
+	URL        string   `json:"url"`
+	StatusCode int      `json:"status_code"`
+	Category   string   `json:"category"`
+	Message    string   `json:"message,omitempty"`
+	Kinds      []string `json:"kinds,omitempty"`
+}
+
+type RedirectHop struct {
 	URL        string `json:"url"`
 	StatusCode int    `json:"status_code"`
 }
+
+// LinkRedirect describes a checked link that redirected. StatusCode is the status of the last response.
+type LinkRedirect struct {
+	URL        string        `json:"url"`
+	FinalURL   string        `json:"final_url"`
+	StatusCode int           `json:"status_code"`
+	Chain      []RedirectHop `json:"chain"`
+	Error      string        `json:"error,omitempty"`
+}
+
+type AnalysisDiffRequest struct {
+	FromID string
+	ToID   string
+	URL    string
+}
+
+type AnalysisDiffResponse struct {
+	From         AnalysisRef            `json:"from"`
+	To           AnalysisRef            `json:"to"`
+	Changed      bool                   `json:"changed"`
+	Title        *StringChange          `json:"title,omitempty"`
+	HTMLVersion  *StringChange          `json:"html_version,omitempty"`
+	HasLoginForm *BoolChange            `json:"has_login_form,omitempty"`
+	Headings     map[string]HeadingDiff `json:"headings"`
+	Links        LinkDiff               `json:"links"`
+}
+
+type AnalysisRef struct {
+	AnalyzeID string     `json:"analyze_id"`
+	URL       string     `json:"url"`
+	CreatedAt *time.Time `json:"created_at,omitempty"`
+}
+
+type StringChange struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+type BoolChange struct {
+	From bool `json:"from"`
+	To   bool `json:"to"`
+}
+
+type HeadingDiff struct {
+	From  int `json:"from"`
+	To    int `json:"to"`
+	Delta int `json:"delta"`
+}
+
+type LinkDiff struct {
+	InternalDelta     int                `json:"internal_delta"`
+	ExternalDelta     int                `json:"external_delta"`
+	InaccessibleDelta int                `json:"inaccessible_delta"`
+	NewlyBroken       []InaccessibleLink `json:"newly_broken"`
+	NewlyFixed        []InaccessibleLink `json:"newly_fixed"`
+}
+
+type AnalysisEvent struct {
+	Type      string            `json:"type"`
+	AnalyzeID string            `json:"analyze_id"`
+	Status    string            `json:"status,omitempty"`
+	Message   string            `json:"message,omitempty"`
+	Progress  *LinkProgress     `json:"progress,omitempty"`
+	Link      *InaccessibleLink `json:"link,omitempty"`
+}
+
+type LinkProgress struct {
+	Checked int `json:"checked"`
+	Total   int `json:"total"`
+}
+
+type WebhookDeliveriesResponse struct {
+	AnalyzeID   string            `json:"analyze_id"`
+	CallbackURL string            `json:"callback_url"`
+	Deliveries  []WebhookDelivery `json:"deliveries"`
+}
+
+type WebhookDelivery struct {
+	ID         string    `json:"id"`
+	Event      string    `json:"event"`
+	Attempt    int       `json:"attempt"`
+	StatusCode int       `json:"status_code"`
+	Success    bool      `json:"success"`
+	Error      string    `json:"error,omitempty"`
+	DurationMs int64     `json:"duration_ms"`
+	CreatedAt  time.Time `json:"created_at"`
+}
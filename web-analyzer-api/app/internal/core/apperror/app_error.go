@@ -3,14 +3,17 @@ package apperror
 import (
 	"fmt"
 	"net/http"
+	"time"
 )
 
 const (
 	CategoryValidation = "validation"
 	CategoryAuth       = "auth"
 	CategoryNotFound   = "not_found"
+	CategoryConflict   = "conflict"
 	CategoryInternal   = "internal"
 	CategoryDownstream = "downstream"
+	CategoryOverloaded = "overloaded"
 	CategoryUnknown    = "unknown"
 )
 
@@ -20,6 +23,7 @@ type AppError struct {
 	ChainedError error
 	Category     string
 	Reason       string
+	RetryAfter   time.Duration
 }
 
 func BadRequest(message string) *AppError {
@@ -38,6 +42,16 @@ func NotFound(message string) *AppError {
 	return categorizedError(message, http.StatusNotFound, CategoryNotFound)
 }
 
+func Conflict(message string) *AppError {
+	return categorizedError(message, http.StatusConflict, CategoryConflict)
+}
+
+func ServiceUnavailable(message string, retryAfter time.Duration) *AppError {
+	err := categorizedError(message, http.StatusServiceUnavailable, CategoryOverloaded)
+	err.RetryAfter = retryAfter
+	return err
+}
+
 func (e *AppError) Error() string {
 	result := fmt.Sprintf("status code: %d, message: %s", e.StatusCode, e.Message)
 	if e.ChainedError != nil {
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"net/http"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -40,6 +41,23 @@ func TestNotFound(t *testing.T) {
 	assert.Equal(t, CategoryNotFound, err.Category)
 }
 
+func TestConflict(t *testing.T) {
+	msg := "already finished"
+	err := Conflict(msg)
+	assert.Equal(t, http.StatusConflict, err.StatusCode)
+	assert.Equal(t, msg, err.Message)
+	assert.Equal(t, CategoryConflict, err.Category)
+}
+
+func TestServiceUnavailable(t *testing.T) {
+	msg := "queue is full"
+	err := ServiceUnavailable(msg, 30*time.Second)
+	assert.Equal(t, http.StatusServiceUnavailable, err.StatusCode)
+	assert.Equal(t, msg, err.Message)
+	assert.Equal(t, CategoryOverloaded, err.Category)
+	assert.Equal(t, 30*time.Second, err.RetryAfter)
+}
+
 func TestErrorMethod(t *testing.T) {
 	t.Run("Basic error", func(t *testing.T) {
 		err := &AppError{StatusCode: 400, Message: "test"}
@@ -10,12 +10,25 @@ import (
 )
 
 type LinkChecker interface {
-	CheckLink(ctx context.Context, client *http.Client, link string, baseURL *url.URL) *model.LinkCheckResult
-	RunWorker(ctx context.Context, linksChan <-chan string, resultsChan chan<- model.LinkCheckResult, baseURL *url.URL, wg *sync.WaitGroup)
+	CheckLink(ctx context.Context, client *http.Client, link string, baseURL *url.URL, options model.AnalysisOptions) *model.LinkCheckResult
+	RunWorker(ctx context.Context, linksChan <-chan string, resultsChan chan<- model.LinkCheckResult, baseURL *url.URL, options model.AnalysisOptions, wg *sync.WaitGroup)
+	Acquire(ctx context.Context, target *url.URL, options model.AnalysisOptions) (release func(), err error)
 }
 
 type WebAnalyzerService interface {
 	GetAnalyzeData(ctx context.Context, analyzeId string) (*contract.WebAnalyzeResponse, error)
-	AnalyzeWebsite(ctx context.Context, baseURL *url.URL) (analysisId string, err error)
+	AnalyzeWebsite(ctx context.Context, baseURL *url.URL, callbackURL string, options contract.AnalysisOptions) (analysisId string, err error)
+	AnalyzeBatch(ctx context.Context, baseURLs []*url.URL, callbackURL string, options contract.AnalysisOptions) (*contract.BatchAnalyzeResponse, error)
+	GetBatch(ctx context.Context, batchId string) (*contract.BatchResponse, error)
+	CrawlSite(ctx context.Context, baseURL *url.URL, source string, maxDepth int, maxPages int, options contract.AnalysisOptions) (*contract.CrawlAnalyzeResponse, error)
+	GetCrawl(ctx context.Context, crawlId string) (*contract.CrawlResponse, error)
+	ListAnalyses(ctx context.Context, req contract.ListAnalysesRequest) (*contract.ListAnalysesResponse, error)
+	DiffAnalyses(ctx context.Context, req contract.AnalysisDiffRequest) (*contract.AnalysisDiffResponse, error)
+	WatchAnalysis(ctx context.Context, analyzeId string) (*contract.WebAnalyzeResponse, <-chan contract.AnalysisEvent, error)
+	GetWebhookDeliveries(ctx context.Context, analyzeId string) (*contract.WebhookDeliveriesResponse, error)
 	UpdateAnalysisStatus(analyzeId string, status string, errorDescription string)
+	CancelAnalysis(ctx context.Context, analyzeId string) error
+	RecoverStaleAnalyses(policy string) error
+	Start()
+	Shutdown(ctx context.Context) error
 }
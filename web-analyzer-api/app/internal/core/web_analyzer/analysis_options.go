@@ -0,0 +1,72 @@
+package webanalyzer
+
+import (
+	"time"
+	"web-analyzer-api/app/internal/contract"
+	"web-analyzer-api/app/internal/model"
+)
+
+const (
+	DefaultLinkWorkers = 10
+	MaxLinkWorkers     = 50
+
+	DefaultLinkTimeout = 10 * time.Second
+	MaxLinkTimeout     = time.Minute
+
+	MaxUserAgentLength = 256
+)
+
+// toModelOptions maps validated request options to the options stored with an analysis.
+func toModelOptions(options contract.AnalysisOptions) model.AnalysisOptions {
+	return model.AnalysisOptions{
+		SkipLinkCheck:     options.CheckLinks != nil && !*options.CheckLinks,
+		MaxLinks:          options.MaxLinks,
+		LinkWorkers:       options.LinkWorkers,
+		LinkTimeout:       time.Duration(options.LinkTimeoutMs) * time.Millisecond,
+		SkipRedirects:     options.FollowRedirects != nil && !*options.FollowRedirects,
+		SkipExternalLinks: options.IncludeExternalLinks != nil && !*options.IncludeExternalLinks,
+		UserAgent:         options.UserAgent,
+		RespectRobots:     options.RespectRobots != nil && *options.RespectRobots,
+	}
+}
+
+// toContractOptions returns the options an analysis runs with, filling in the server defaults.
+func toContractOptions(options model.AnalysisOptions) contract.AnalysisOptions {
+	checkLinks := !options.SkipLinkCheck
+	followRedirects := !options.SkipRedirects
+	includeExternalLinks := !options.SkipExternalLinks
+	respectRobots := options.RespectRobots
+
+	return contract.AnalysisOptions{
+		CheckLinks:           &checkLinks,
+		MaxLinks:             options.MaxLinks,
+		LinkWorkers:          linkWorkers(options),
+		LinkTimeoutMs:        int(linkTimeout(options).Milliseconds()),
+		FollowRedirects:      &followRedirects,
+		IncludeExternalLinks: &includeExternalLinks,
+		UserAgent:            options.UserAgent,
+		RespectRobots:        &respectRobots,
+	}
+}
+
+// sameLinkCheck reports whether two analyses checked links the same way, so that their broken links can be
+// compared. The number of link workers does not change the results and may differ.
+func sameLinkCheck(a model.AnalysisOptions, b model.AnalysisOptions) bool {
+	a.LinkWorkers, b.LinkWorkers = 0, 0
+	a.LinkTimeout, b.LinkTimeout = linkTimeout(a), linkTimeout(b)
+	return a == b
+}
+
+func linkWorkers(options model.AnalysisOptions) int {
+	if options.LinkWorkers <= 0 {
+		return DefaultLinkWorkers
+	}
+	return min(options.LinkWorkers, MaxLinkWorkers)
+}
+
+func linkTimeout(options model.AnalysisOptions) time.Duration {
+	if options.LinkTimeout <= 0 {
+		return DefaultLinkTimeout
+	}
+	return min(options.LinkTimeout, MaxLinkTimeout)
+}
@@ -0,0 +1,158 @@
+package webanalyzer
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+	"web-analyzer-api/app/internal/contract"
+	"web-analyzer-api/app/internal/model"
+	"web-analyzer-api/app/internal/repositorymemory"
+	"web-analyzer-api/app/internal/util/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnalysisOptions(t *testing.T) {
+	disabled, enabled := false, true
+
+	t.Run("Defaults", func(t *testing.T) {
+		options := toModelOptions(contract.AnalysisOptions{})
+		assert.Equal(t, model.AnalysisOptions{}, options)
+
+		effective := toContractOptions(options)
+		assert.True(t, *effective.CheckLinks)
+		assert.True(t, *effective.FollowRedirects)
+		assert.True(t, *effective.IncludeExternalLinks)
+		assert.False(t, *effective.RespectRobots)
+		assert.Equal(t, DefaultLinkWorkers, effective.LinkWorkers)
+		assert.Equal(t, int(DefaultLinkTimeout.Milliseconds()), effective.LinkTimeoutMs)
+	})
+
+	t.Run("Requested values", func(t *testing.T) {
+		options := toModelOptions(contract.AnalysisOptions{
+			CheckLinks:           &disabled,
+			MaxLinks:             20,
+			LinkWorkers:          3,
+			LinkTimeoutMs:        1500,
+			FollowRedirects:      &disabled,
+			IncludeExternalLinks: &disabled,
+			UserAgent:            "audit-bot/1.0",
+			RespectRobots:        &enabled,
+		})
+
+		assert.Equal(t, model.AnalysisOptions{
+			SkipLinkCheck:     true,
+			MaxLinks:          20,
+			LinkWorkers:       3,
+			LinkTimeout:       1500 * time.Millisecond,
+			SkipRedirects:     true,
+			SkipExternalLinks: true,
+			UserAgent:         "audit-bot/1.0",
+			RespectRobots:     true,
+		}, options)
+		assert.Equal(t, 3, linkWorkers(options))
+		assert.Equal(t, 1500*time.Millisecond, linkTimeout(options))
+	})
+
+	t.Run("Limits", func(t *testing.T) {
+		options := model.AnalysisOptions{LinkWorkers: MaxLinkWorkers + 1, LinkTimeout: 2 * MaxLinkTimeout}
+		assert.Equal(t, MaxLinkWorkers, linkWorkers(options))
+		assert.Equal(t, MaxLinkTimeout, linkTimeout(options))
+	})
+}
+
+func TestAnalyzeWebsite_Options(t *testing.T) {
+	log := logger.Get("info")
+
+	var (
+		mu         sync.Mutex
+		requested  []string
+		userAgents []string
+	)
+
+	var ts *httptest.Server
+	ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requested = append(requested, r.URL.Path)
+		userAgents = append(userAgents, r.UserAgent())
+		mu.Unlock()
+
+		switch r.URL.Path {
+		case "/":
+			fmt.Fprintf(w, `<html><body><a href="%[1]s/a">a</a><a href="/b">b</a><a href="/c">c</a><a href="http://external.invalid/">x</a></body></html>`, ts.URL)
+		case "/b":
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	runAnalysis := func(t *testing.T, options contract.AnalysisOptions) *contract.WebAnalyzeResponse {
+		mu.Lock()
+		requested, userAgents = nil, nil
+		mu.Unlock()
+
+		service := NewWebAnalyzerService(log, repositorymemory.NewWebAnalyzerRepo(log), repositorymemory.NewBatchRepo(log), repositorymemory.NewCrawlRepo(log), NewLinkChecker(log, newTestNetworkGuard(), newTestRobotsCache(log), LinkCheckConfig{}), NewJobQueue(log, 1, 10), newTestWebhookDispatcher(log), newTestNetworkGuard(), PageFetchConfig{}, CrawlConfig{})
+		defer service.Shutdown(context.Background())
+
+		pageURL, _ := url.Parse(ts.URL + "/")
+		id, err := service.AnalyzeWebsite(context.Background(), pageURL, "", options)
+		require.NoError(t, err)
+
+		var result *contract.WebAnalyzeResponse
+		require.Eventually(t, func() bool {
+			result, err = service.GetAnalyzeData(context.Background(), id)
+			return err == nil && result.Status == StatusSuccess
+		}, 5*time.Second, 20*time.Millisecond)
+		return result
+	}
+
+	t.Run("Link check disabled", func(t *testing.T) {
+		disabled := false
+		result := runAnalysis(t, contract.AnalysisOptions{CheckLinks: &disabled, UserAgent: "audit-bot/1.0"})
+
+		assert.Equal(t, 3, result.Links.Internal)
+		assert.Equal(t, 1, result.Links.External)
+		assert.Equal(t, 0, result.Links.Inaccessible)
+		assert.False(t, *result.Options.CheckLinks)
+
+		mu.Lock()
+		defer mu.Unlock()
+		assert.Equal(t, []string{"/"}, requested)
+		assert.Equal(t, []string{"audit-bot/1.0"}, userAgents)
+	})
+
+	t.Run("Internal links only", func(t *testing.T) {
+		excluded := false
+		result := runAnalysis(t, contract.AnalysisOptions{IncludeExternalLinks: &excluded, UserAgent: "audit-bot/1.0"})
+
+		// The unresolvable external link would be reported as inaccessible if it was checked
+		assert.Equal(t, 1, result.Links.Inaccessible)
+		assert.Equal(t, ts.URL+"/b", result.Links.InaccessibleDetails[0].URL)
+		assert.Equal(t, 1, result.Links.External)
+
+		mu.Lock()
+		defer mu.Unlock()
+		for _, userAgent := range userAgents {
+			assert.Equal(t, "audit-bot/1.0", userAgent)
+		}
+	})
+
+	t.Run("Maximum links", func(t *testing.T) {
+		excluded := false
+		runAnalysis(t, contract.AnalysisOptions{IncludeExternalLinks: &excluded, MaxLinks: 2})
+
+		mu.Lock()
+		defer mu.Unlock()
+		checked := map[string]bool{}
+		for _, path := range requested[1:] {
+			checked[path] = true
+		}
+		assert.Len(t, checked, 2)
+	})
+}
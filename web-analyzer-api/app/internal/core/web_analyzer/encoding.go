@@ -0,0 +1,21 @@
+package webanalyzer
+
+import (
+	"bytes"
+	"io"
+
+	"golang.org/x/net/html/charset"
+	"golang.org/x/text/encoding/unicode"
+	"golang.org/x/text/transform"
+)
+
+// decodeHTML returns a UTF-8 reader over an HTML body together with the name of its detected encoding.
+// The encoding is taken from a byte order mark, the Content-Type header or a <meta> charset declaration,
+// in that order, and otherwise guessed from the content.
+func decodeHTML(body []byte, contentType string) (io.Reader, string) {
+	encoding, name, _ := charset.DetermineEncoding(body, contentType)
+
+	// BOMOverride drops a byte order mark, which the UTF-8 decoder would otherwise keep
+	decoder := unicode.BOMOverride(encoding.NewDecoder())
+	return transform.NewReader(bytes.NewReader(body), decoder), name
+}
@@ -0,0 +1,108 @@
+package webanalyzer
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+	"web-analyzer-api/app/internal/contract"
+	"web-analyzer-api/app/internal/repositorymemory"
+	"web-analyzer-api/app/internal/util/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/japanese"
+)
+
+func encode(t *testing.T, e encoding.Encoding, s string) []byte {
+	encoded, err := e.NewEncoder().Bytes([]byte(s))
+	require.NoError(t, err)
+	return encoded
+}
+
+func TestDecodeHTML(t *testing.T) {
+	tests := []struct {
+		name        string
+		body        []byte
+		contentType string
+		expected    string
+		encoding    string
+	}{
+		{
+			name:        "Content-Type header",
+			body:        encode(t, japanese.ShiftJIS, "<title>日本語</title>"),
+			contentType: "text/html; charset=Shift_JIS",
+			expected:    "<title>日本語</title>",
+			encoding:    "shift_jis",
+		},
+		{
+			name:        "Meta charset",
+			body:        encode(t, charmap.Windows1251, `<meta charset="windows-1251"><title>Привет</title>`),
+			contentType: "text/html",
+			expected:    `<meta charset="windows-1251"><title>Привет</title>`,
+			encoding:    "windows-1251",
+		},
+		{
+			name:        "Meta http-equiv",
+			body:        encode(t, charmap.ISO8859_1, `<meta http-equiv="Content-Type" content="text/html; charset=iso-8859-1"><title>Café</title>`),
+			contentType: "",
+			expected:    `<meta http-equiv="Content-Type" content="text/html; charset=iso-8859-1"><title>Café</title>`,
+			encoding:    "windows-1252",
+		},
+		{
+			name:        "Byte order mark wins over the header",
+			body:        append([]byte("\xef\xbb\xbf"), "<title>Über</title>"...),
+			contentType: "text/html; charset=iso-8859-1",
+			expected:    "<title>Über</title>",
+			encoding:    "utf-8",
+		},
+		{
+			name:        "No declaration",
+			body:        []byte("<title>Über</title>"),
+			contentType: "text/html",
+			expected:    "<title>Über</title>",
+			encoding:    "utf-8",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reader, name := decodeHTML(tt.body, tt.contentType)
+			decoded, err := io.ReadAll(reader)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, string(decoded))
+			assert.Equal(t, tt.encoding, name)
+		})
+	}
+}
+
+func TestAnalyzeWebsite_Encoding(t *testing.T) {
+	log := logger.Get("info")
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=Shift_JIS")
+		w.Write(encode(t, japanese.ShiftJIS, "<html><head><title>日本語のページ</title></head></html>"))
+	}))
+	defer ts.Close()
+
+	service := NewWebAnalyzerService(log, repositorymemory.NewWebAnalyzerRepo(log), repositorymemory.NewBatchRepo(log), repositorymemory.NewCrawlRepo(log), NewLinkChecker(log, newTestNetworkGuard(), newTestRobotsCache(log), LinkCheckConfig{}), NewJobQueue(log, 1, 10), newTestWebhookDispatcher(log), newTestNetworkGuard(), PageFetchConfig{}, CrawlConfig{})
+	defer service.Shutdown(context.Background())
+
+	pageURL, _ := url.Parse(ts.URL)
+	id, err := service.AnalyzeWebsite(context.Background(), pageURL, "", contract.AnalysisOptions{})
+	require.NoError(t, err)
+
+	var result *contract.WebAnalyzeResponse
+	require.Eventually(t, func() bool {
+		result, err = service.GetAnalyzeData(context.Background(), id)
+		return err == nil && result.Status == StatusSuccess
+	}, 5*time.Second, 20*time.Millisecond)
+
+	assert.Equal(t, "日本語のページ", result.Title)
+	assert.Equal(t, "shift_jis", result.Encoding)
+}
@@ -0,0 +1,133 @@
+package webanalyzer
+
+import (
+	"sync"
+	"web-analyzer-api/app/internal/contract"
+)
+
+const (
+	EventSnapshot         = "snapshot"
+	EventStarted          = "started"
+	EventFetched          = "fetched"
+	EventParsed           = "parsed"
+	EventLinksProgress    = "links_progress"
+	EventLinkInaccessible = "link_inaccessible"
+	EventCompleted        = "completed"
+	EventFailed           = "failed"
+	EventCancelled        = "cancelled"
+	EventInterrupted      = "interrupted"
+)
+
+// subscriberBuffer is the number of events a stream subscriber can fall behind before events are dropped.
+const subscriberBuffer = 64
+
+type eventSubscriber struct {
+	events chan contract.AnalysisEvent
+}
+
+// eventBroker fans out progress events of analyses to stream subscribers. Publishing never blocks
+// an analysis: progress updates are skipped for a subscriber that is behind, and a subscriber that
+// cannot take any other event is dropped and its channel closed.
+type eventBroker struct {
+	mu          sync.Mutex
+	subscribers map[string]map[*eventSubscriber]struct{}
+}
+
+func newEventBroker() *eventBroker {
+	return &eventBroker{
+		subscribers: make(map[string]map[*eventSubscriber]struct{}),
+	}
+}
+
+func (b *eventBroker) subscribe(analysisId string) *eventSubscriber {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	sub := &eventSubscriber{events: make(chan contract.AnalysisEvent, subscriberBuffer)}
+	if b.subscribers[analysisId] == nil {
+		b.subscribers[analysisId] = make(map[*eventSubscriber]struct{})
+	}
+	b.subscribers[analysisId][sub] = struct{}{}
+	return sub
+}
+
+// unsubscribe removes the subscriber and closes its channel. It is safe to call more than once.
+func (b *eventBroker) unsubscribe(analysisId string, sub *eventSubscriber) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.remove(analysisId, sub)
+}
+
+func (b *eventBroker) publish(event contract.AnalysisEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	final := isFinalEvent(event.Type)
+	for sub := range b.subscribers[event.AnalyzeID] {
+		select {
+		case sub.events <- event:
+		default:
+			if event.Type == EventLinksProgress {
+				// A later progress event supersedes this one
+				continue
+			}
+			b.remove(event.AnalyzeID, sub)
+			continue
+		}
+
+		if final {
+			b.remove(event.AnalyzeID, sub)
+		}
+	}
+}
+
+func (b *eventBroker) subscriberCount(analysisId string) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return len(b.subscribers[analysisId])
+}
+
+func (b *eventBroker) remove(analysisId string, sub *eventSubscriber) {
+	subs, ok := b.subscribers[analysisId]
+	if !ok {
+		return
+	}
+	if _, ok := subs[sub]; !ok {
+		return
+	}
+
+	delete(subs, sub)
+	close(sub.events)
+	if len(subs) == 0 {
+		delete(b.subscribers, analysisId)
+	}
+}
+
+func isFinalEvent(eventType string) bool {
+	switch eventType {
+	case EventCompleted, EventFailed, EventCancelled, EventInterrupted:
+		return true
+	default:
+		return false
+	}
+}
+
+// statusEvent returns the event type published when an analysis moves to the given status, or "" if none is.
+func statusEvent(status string) string {
+	switch status {
+	case StatusPending:
+		return EventStarted
+	case StatusSuccess:
+		return EventCompleted
+	case StatusFailed:
+		return EventFailed
+	case StatusCancelled:
+		return EventCancelled
+	case StatusInterrupted:
+		return EventInterrupted
+	default:
+		return ""
+	}
+}
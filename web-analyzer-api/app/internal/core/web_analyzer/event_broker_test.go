@@ -0,0 +1,202 @@
+package webanalyzer
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+	"web-analyzer-api/app/internal/contract"
+	"web-analyzer-api/app/internal/core/apperror"
+	"web-analyzer-api/app/internal/model"
+	"web-analyzer-api/app/internal/repository"
+	"web-analyzer-api/app/internal/repositorymemory"
+	"web-analyzer-api/app/internal/util/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventBroker(t *testing.T) {
+	t.Run("Final event closes subscribers", func(t *testing.T) {
+		broker := newEventBroker()
+		first := broker.subscribe("id")
+		second := broker.subscribe("id")
+		other := broker.subscribe("other-id")
+
+		broker.publish(contract.AnalysisEvent{Type: EventFetched, AnalyzeID: "id"})
+		broker.publish(contract.AnalysisEvent{Type: EventCompleted, AnalyzeID: "id"})
+
+		for _, sub := range []*eventSubscriber{first, second} {
+			assert.Equal(t, EventFetched, (<-sub.events).Type)
+			assert.Equal(t, EventCompleted, (<-sub.events).Type)
+			_, open := <-sub.events
+			assert.False(t, open)
+		}
+		assert.Equal(t, 0, broker.subscriberCount("id"))
+		assert.Equal(t, 1, broker.subscriberCount("other-id"))
+		assert.Empty(t, other.events)
+	})
+
+	t.Run("Unsubscribe is idempotent", func(t *testing.T) {
+		broker := newEventBroker()
+		sub := broker.subscribe("id")
+
+		broker.unsubscribe("id", sub)
+		broker.unsubscribe("id", sub)
+		broker.publish(contract.AnalysisEvent{Type: EventFetched, AnalyzeID: "id"})
+
+		_, open := <-sub.events
+		assert.False(t, open)
+		assert.Equal(t, 0, broker.subscriberCount("id"))
+	})
+
+	t.Run("Slow subscriber", func(t *testing.T) {
+		broker := newEventBroker()
+		sub := broker.subscribe("id")
+
+		for i := 0; i < subscriberBuffer+10; i++ {
+			broker.publish(contract.AnalysisEvent{Type: EventLinksProgress, AnalyzeID: "id"})
+		}
+		// Progress events are skipped while the subscriber is behind
+		assert.Equal(t, 1, broker.subscriberCount("id"))
+		assert.Len(t, sub.events, subscriberBuffer)
+
+		// Any other event that does not fit drops the subscriber
+		broker.publish(contract.AnalysisEvent{Type: EventLinkInaccessible, AnalyzeID: "id"})
+		assert.Equal(t, 0, broker.subscriberCount("id"))
+
+		received := 0
+		for range sub.events {
+			received++
+		}
+		assert.Equal(t, subscriberBuffer, received)
+	})
+}
+
+// newTestService returns a service backed by repo whose workers are not started, so analyses stay in the
+// state the test saved them in.
+func newTestService(t *testing.T, repo repository.WebAnalyzerRepository) *webAnalyzerService {
+	t.Helper()
+	log := logger.Get("info")
+	linkChecker := NewLinkChecker(log, newTestNetworkGuard(), newTestRobotsCache(log), LinkCheckConfig{})
+	return NewUnstartedWebAnalyzerService(log, repo, repositorymemory.NewBatchRepo(log), repositorymemory.NewCrawlRepo(log), linkChecker, NewJobQueue(log, 1, 10), newTestWebhookDispatcher(log), newTestNetworkGuard(), PageFetchConfig{}, CrawlConfig{}).(*webAnalyzerService)
+}
+
+func TestWatchAnalysis(t *testing.T) {
+	log := logger.Get("info")
+
+	t.Run("Streams progress until completion", func(t *testing.T) {
+		release := make(chan struct{})
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/":
+				<-release
+				fmt.Fprint(w, `<html><body><a href="/ok">ok</a><a href="/broken">broken</a><a href="#top">top</a></body></html>`)
+			case "/broken":
+				w.WriteHeader(http.StatusNotFound)
+			default:
+				w.WriteHeader(http.StatusOK)
+			}
+		}))
+		defer ts.Close()
+
+		repo := repositorymemory.NewWebAnalyzerRepo(log)
+		service := NewWebAnalyzerService(log, repo, repositorymemory.NewBatchRepo(log), repositorymemory.NewCrawlRepo(log), NewLinkChecker(log, newTestNetworkGuard(), newTestRobotsCache(log), LinkCheckConfig{}), NewJobQueue(log, 1, 10), newTestWebhookDispatcher(log), newTestNetworkGuard(), PageFetchConfig{}, CrawlConfig{}).(*webAnalyzerService)
+		defer service.Shutdown(context.Background())
+
+		baseURL, _ := url.Parse(ts.URL)
+		id, err := service.AnalyzeWebsite(context.Background(), baseURL, "", contract.AnalysisOptions{})
+		require.NoError(t, err)
+
+		snapshot, events, err := service.WatchAnalysis(context.Background(), id)
+		require.NoError(t, err)
+		assert.Equal(t, id, snapshot.AnalyzeID)
+		close(release)
+
+		var received []contract.AnalysisEvent
+		timeout := time.After(5 * time.Second)
+	collect:
+		for {
+			select {
+			case event, ok := <-events:
+				if !ok {
+					break collect
+				}
+				received = append(received, event)
+			case <-timeout:
+				t.Fatal("event stream was not closed")
+			}
+		}
+
+		types := []string{}
+		var lastProgress *contract.LinkProgress
+		var broken *contract.InaccessibleLink
+		for _, event := range received {
+			types = append(types, event.Type)
+			if event.Type == EventLinksProgress {
+				lastProgress = event.Progress
+			}
+			if event.Type == EventLinkInaccessible {
+				broken = event.Link
+			}
+		}
+
+		assert.Contains(t, types, EventFetched)
+		assert.Contains(t, types, EventParsed)
+		assert.Equal(t, EventCompleted, types[len(types)-1])
+		require.NotNil(t, lastProgress)
+		assert.Equal(t, contract.LinkProgress{Checked: 2, Total: 2}, *lastProgress)
+		require.NotNil(t, broken)
+		assert.Equal(t, ts.URL+"/broken", broken.URL)
+		assert.Equal(t, http.StatusNotFound, broken.StatusCode)
+	})
+
+	t.Run("Finished analysis", func(t *testing.T) {
+		repo := repositorymemory.NewWebAnalyzerRepo(log)
+		service := newTestService(t, repo)
+		id, _ := repo.Save(model.WebAnalyzer{URL: "http://test.com", Status: StatusSuccess})
+
+		snapshot, events, err := service.WatchAnalysis(context.Background(), id)
+
+		require.NoError(t, err)
+		assert.Equal(t, StatusSuccess, snapshot.Status)
+		_, open := <-events
+		assert.False(t, open)
+		assert.Equal(t, 0, service.events.subscriberCount(id))
+	})
+
+	t.Run("Client disconnects", func(t *testing.T) {
+		repo := repositorymemory.NewWebAnalyzerRepo(log)
+		service := newTestService(t, repo)
+		id, _ := repo.Save(model.WebAnalyzer{URL: "http://test.com", Status: StatusQueued})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		_, events, err := service.WatchAnalysis(ctx, id)
+		require.NoError(t, err)
+		assert.Equal(t, 1, service.events.subscriberCount(id))
+
+		cancel()
+		select {
+		case _, open := <-events:
+			assert.False(t, open)
+		case <-time.After(time.Second):
+			t.Fatal("subscription was not released")
+		}
+		assert.Equal(t, 0, service.events.subscriberCount(id))
+	})
+
+	t.Run("Not found", func(t *testing.T) {
+		repo := repositorymemory.NewWebAnalyzerRepo(log)
+		service := newTestService(t, repo)
+
+		_, _, err := service.WatchAnalysis(context.Background(), "missing")
+
+		var appErr *apperror.AppError
+		require.ErrorAs(t, err, &appErr)
+		assert.Equal(t, http.StatusNotFound, appErr.StatusCode)
+		assert.Equal(t, 0, service.events.subscriberCount("missing"))
+	})
+}
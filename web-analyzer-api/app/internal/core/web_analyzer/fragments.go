@@ -0,0 +1,129 @@
+package webanalyzer
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"sync"
+	"web-analyzer-api/app/internal/model"
+	htmlhelper "web-analyzer-api/app/internal/util/html"
+
+	"golang.org/x/net/html"
+)
+
+// fragmentLink is a link to an anchor. Page is the normalized URL of the page the anchor is looked up on.
+type fragmentLink struct {
+	URL      string
+	Page     string
+	Fragment string
+}
+
+// collectFragmentLinks returns the unique links with a fragment that point to the analyzed page or to another
+// internal page. Links to the top of a page and text fragments are left out, as they need no anchor.
+func collectFragmentLinks(links []string, linkBaseURL *url.URL, pageURL *url.URL) []fragmentLink {
+	fragmentLinks := []fragmentLink{}
+	seen := map[string]bool{}
+
+	for _, link := range links {
+		ref, err := url.Parse(strings.TrimSpace(link))
+		if err != nil || !needsAnchor(ref.Fragment) {
+			continue
+		}
+
+		// In-page links resolve like any other, so "#id" points to the page itself
+		page, ok := htmlhelper.NormalizeLink(linkBaseURL.ResolveReference(ref).String(), linkBaseURL)
+		if !ok || !htmlhelper.IsInternalLink(page, pageURL) {
+			continue
+		}
+
+		target := page + "#" + ref.EscapedFragment()
+		if seen[target] {
+			continue
+		}
+		seen[target] = true
+		fragmentLinks = append(fragmentLinks, fragmentLink{URL: target, Page: page, Fragment: ref.Fragment})
+	}
+
+	return fragmentLinks
+}
+
+func needsAnchor(fragment string) bool {
+	return fragment != "" && !strings.EqualFold(fragment, "top") && !strings.HasPrefix(fragment, ":~:")
+}
+
+// findMissingFragments returns the fragment links whose anchor does not exist. Anchors of the analyzed page
+// are taken from doc, other pages are fetched when they are in checkedPages, which holds the pages that were
+// found accessible, and not in skippedPages, which holds the pages skipped by robots.txt. Pages are fetched
+// under the same robots.txt rules and host limits as link checks. Pages that cannot be fetched or parsed are
+// skipped.
+func (s *webAnalyzerService) findMissingFragments(ctx context.Context, links []fragmentLink, doc *html.Node, pageURL *url.URL, checkedPages map[string]bool, skippedPages map[string]bool, options model.AnalysisOptions) []fragmentLink {
+	self, _ := htmlhelper.NormalizeLink(pageURL.String(), pageURL)
+	anchors := map[string]map[string]bool{self: htmlhelper.GetAnchors(doc)}
+
+	var pages []string
+	for _, link := range links {
+		if _, ok := anchors[link.Page]; !ok && checkedPages[link.Page] && !skippedPages[link.Page] {
+			anchors[link.Page] = nil
+			pages = append(pages, link.Page)
+		}
+	}
+
+	mu := sync.Mutex{}
+	wg := sync.WaitGroup{}
+	slots := make(chan struct{}, linkWorkers(options))
+	for _, page := range pages {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			select {
+			case slots <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+			defer func() { <-slots }()
+
+			pageAnchors, err := s.fetchAnchors(ctx, page, options)
+			if err != nil {
+				s.log.Debug("Skipping fragments of " + page + ": " + err.Error())
+				return
+			}
+			mu.Lock()
+			anchors[page] = pageAnchors
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	missing := []fragmentLink{}
+	for _, link := range links {
+		pageAnchors := anchors[link.Page]
+		if pageAnchors != nil && !pageAnchors[link.Fragment] {
+			missing = append(missing, link)
+		}
+	}
+	return missing
+}
+
+func (s *webAnalyzerService) fetchAnchors(ctx context.Context, page string, options model.AnalysisOptions) (map[string]bool, error) {
+	target, err := url.Parse(page)
+	if err != nil {
+		return nil, err
+	}
+
+	release, err := s.linkChecker.Acquire(ctx, target, options)
+	if err != nil {
+		return nil, err
+	}
+	fetched, _, err := s.pages.fetch(ctx, page, options.UserAgent)
+	release()
+	if err != nil {
+		return nil, err
+	}
+
+	content, _ := decodeHTML(fetched.Body, fetched.ContentType)
+	doc, err := html.Parse(content)
+	if err != nil {
+		return nil, err
+	}
+	return htmlhelper.GetAnchors(doc), nil
+}
@@ -0,0 +1,138 @@
+package webanalyzer
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	"web-analyzer-api/app/internal/contract"
+	"web-analyzer-api/app/internal/model"
+	"web-analyzer-api/app/internal/repositorymemory"
+	"web-analyzer-api/app/internal/util/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/html"
+)
+
+func TestCollectFragmentLinks(t *testing.T) {
+	pageURL, _ := url.Parse("http://myapp.test/docs/page")
+
+	links := collectFragmentLinks([]string{
+		"#install", "#install", "page#install", "#", "#top", "#:~:text=setup",
+		"/docs/guide#Usage", "guide", "http://external.test/#intro", "mailto:me@myapp.test#x", "#caf%C3%A9",
+	}, pageURL, pageURL)
+
+	assert.Equal(t, []fragmentLink{
+		{URL: "http://myapp.test/docs/page#install", Page: "http://myapp.test/docs/page", Fragment: "install"},
+		{URL: "http://myapp.test/docs/guide#Usage", Page: "http://myapp.test/docs/guide", Fragment: "Usage"},
+		{URL: "http://myapp.test/docs/page#caf%C3%A9", Page: "http://myapp.test/docs/page", Fragment: "café"},
+	}, links)
+}
+
+func TestAnalyzeWebsite_Fragments(t *testing.T) {
+	log := logger.Get("info")
+
+	requests := map[string]int{}
+	var mu sync.Mutex
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests[r.Method+" "+r.URL.Path]++
+		mu.Unlock()
+
+		switch r.URL.Path {
+		case "/":
+			fmt.Fprint(w, `<html><body><h2 id="intro">Intro</h2><a name="legacy"></a>
+				<a href="#intro">Intro</a><a href="#legacy">Legacy</a><a href="#missing">Missing</a>
+				<a href="/guide#usage">Usage</a><a href="/guide#gone">Gone</a><a href="/broken#usage">Broken</a>
+			</body></html>`)
+		case "/guide":
+			fmt.Fprint(w, `<html><body><section id="usage"></section></body></html>`)
+		case "/broken":
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	service := NewWebAnalyzerService(log, repositorymemory.NewWebAnalyzerRepo(log), repositorymemory.NewBatchRepo(log), repositorymemory.NewCrawlRepo(log), NewLinkChecker(log, newTestNetworkGuard(), newTestRobotsCache(log), LinkCheckConfig{}), NewJobQueue(log, 1, 10), newTestWebhookDispatcher(log), newTestNetworkGuard(), PageFetchConfig{}, CrawlConfig{})
+	defer service.Shutdown(context.Background())
+
+	pageURL, _ := url.Parse(ts.URL + "/")
+	id, err := service.AnalyzeWebsite(context.Background(), pageURL, "", contract.AnalysisOptions{})
+	require.NoError(t, err)
+
+	var result *contract.WebAnalyzeResponse
+	require.Eventually(t, func() bool {
+		result, err = service.GetAnalyzeData(context.Background(), id)
+		return err == nil && result.Status == StatusSuccess
+	}, 5*time.Second, 20*time.Millisecond)
+
+	// The broken page is reported once for itself, its anchor is not looked up
+	assert.ElementsMatch(t, []contract.InaccessibleLink{
+		{URL: ts.URL + "/broken", StatusCode: http.StatusNotFound, Category: LinkErrorHTTP4xx, Message: "404 Not Found", Kinds: []string{"anchor"}},
+		{URL: ts.URL + "/#missing", Category: LinkErrorMissingFragment, Message: `anchor "missing" not found`, Kinds: []string{"anchor"}},
+		{URL: ts.URL + "/guide#gone", Category: LinkErrorMissingFragment, Message: `anchor "gone" not found`, Kinds: []string{"anchor"}},
+	}, result.Links.InaccessibleDetails)
+	assert.Equal(t, 3, result.Links.Inaccessible)
+	assert.Equal(t, map[string]int{LinkErrorHTTP4xx: 1, LinkErrorMissingFragment: 2}, result.Links.ErrorCategories)
+
+	mu.Lock()
+	// The guide is fetched once for both of its anchors
+	assert.Equal(t, 1, requests["GET /guide"])
+	mu.Unlock()
+
+	t.Run("Not validated without link checks", func(t *testing.T) {
+		disabled := false
+		id, err := service.AnalyzeWebsite(context.Background(), pageURL, "", contract.AnalysisOptions{CheckLinks: &disabled})
+		require.NoError(t, err)
+
+		require.Eventually(t, func() bool {
+			result, err = service.GetAnalyzeData(context.Background(), id)
+			return err == nil && result.Status == StatusSuccess
+		}, 5*time.Second, 20*time.Millisecond)
+		assert.Zero(t, result.Links.Inaccessible)
+	})
+}
+
+func TestFindMissingFragments_Robots(t *testing.T) {
+	log := logger.Get("info")
+
+	var fetched []string
+	var mu sync.Mutex
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			fmt.Fprint(w, "User-agent: *\nDisallow: /private\n")
+			return
+		}
+		mu.Lock()
+		fetched = append(fetched, r.URL.Path)
+		mu.Unlock()
+		fmt.Fprint(w, `<html><body><p id="a"></p></body></html>`)
+	}))
+	defer ts.Close()
+
+	service := NewWebAnalyzerService(log, repositorymemory.NewWebAnalyzerRepo(log), repositorymemory.NewBatchRepo(log), repositorymemory.NewCrawlRepo(log), NewLinkChecker(log, newTestNetworkGuard(), newTestRobotsCache(log), LinkCheckConfig{}), NewJobQueue(log, 1, 10), newTestWebhookDispatcher(log), newTestNetworkGuard(), PageFetchConfig{}, CrawlConfig{}).(*webAnalyzerService)
+	defer service.Shutdown(context.Background())
+
+	pageURL, _ := url.Parse(ts.URL + "/")
+	links := []fragmentLink{
+		{URL: ts.URL + "/private#b", Page: ts.URL + "/private", Fragment: "b"},
+		{URL: ts.URL + "/skipped#b", Page: ts.URL + "/skipped", Fragment: "b"},
+		{URL: ts.URL + "/guide#b", Page: ts.URL + "/guide", Fragment: "b"},
+	}
+	checked := map[string]bool{ts.URL + "/private": true, ts.URL + "/skipped": true, ts.URL + "/guide": true}
+	skipped := map[string]bool{ts.URL + "/skipped": true}
+
+	doc, _ := html.Parse(strings.NewReader(`<html></html>`))
+	missing := service.findMissingFragments(context.Background(), links, doc, pageURL, checked, skipped, model.AnalysisOptions{RespectRobots: true})
+
+	// Only the page robots.txt allows and the analysis did not skip is looked up
+	assert.Equal(t, []fragmentLink{links[2]}, missing)
+	assert.Equal(t, []string{"/guide"}, fetched)
+}
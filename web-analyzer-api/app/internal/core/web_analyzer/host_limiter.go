@@ -0,0 +1,212 @@
+package webanalyzer
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultHostConcurrency = 4
+	DefaultHostRate        = 10
+	DefaultLinkMaxRetries  = 2
+	DefaultMaxRetryAfter   = 30 * time.Second
+
+	// defaultRetryDelay is the first retry delay of a 429 or 503 response without a usable Retry-After
+	// header. It doubles with every retry.
+	defaultRetryDelay = time.Second
+)
+
+// LinkCheckConfig tunes the link checker shared by all analyses: how hard it hits a single host and how
+// long it reuses results. Zero values mean the defaults.
+type LinkCheckConfig struct {
+	// HostConcurrency is the number of requests in flight to one host, across all analyses.
+	HostConcurrency int
+	// HostRate is the number of requests per second sent to one host.
+	HostRate int
+	// HostBurst is the number of requests a host may receive at once before HostRate applies.
+	HostBurst int
+	// MaxRetries is the number of times a link answering 429 or 503 is retried.
+	MaxRetries int
+	// MaxRetryAfter is the longest Retry-After that is waited for. A link asking for more is not retried.
+	MaxRetryAfter time.Duration
+	// CacheSize is the number of link results kept across analyses.
+	CacheSize int
+	// CacheSuccessTTL is how long the result of an accessible link is reused.
+	CacheSuccessTTL time.Duration
+	// CacheFailureTTL is how long the result of an inaccessible link is reused.
+	CacheFailureTTL time.Duration
+}
+
+func (c LinkCheckConfig) withDefaults() LinkCheckConfig {
+	if c.HostConcurrency <= 0 {
+		c.HostConcurrency = DefaultHostConcurrency
+	}
+	if c.HostRate <= 0 {
+		c.HostRate = DefaultHostRate
+	}
+	if c.HostBurst <= 0 {
+		c.HostBurst = c.HostRate
+	}
+	if c.MaxRetries <= 0 {
+		c.MaxRetries = DefaultLinkMaxRetries
+	}
+	if c.MaxRetryAfter <= 0 {
+		c.MaxRetryAfter = DefaultMaxRetryAfter
+	}
+	if c.CacheSize <= 0 {
+		c.CacheSize = DefaultLinkCacheSize
+	}
+	if c.CacheSuccessTTL <= 0 {
+		c.CacheSuccessTTL = DefaultLinkCacheSuccessTTL
+	}
+	if c.CacheFailureTTL <= 0 {
+		c.CacheFailureTTL = DefaultLinkCacheFailureTTL
+	}
+	return c
+}
+
+// hostLimiter caps the requests in flight to each host and rate limits them with a token bucket per host.
+type hostLimiter struct {
+	config LinkCheckConfig
+	mu     sync.Mutex
+	hosts  map[string]*hostState
+}
+
+type hostState struct {
+	slots  chan struct{}
+	tokens float64
+	last   time.Time
+	users  int
+}
+
+func newHostLimiter(config LinkCheckConfig) *hostLimiter {
+	return &hostLimiter{
+		config: config.withDefaults(),
+		hosts:  make(map[string]*hostState),
+	}
+}
+
+// acquire waits for a free slot and a token for host. The returned function releases the slot and must be
+// called once the request is done.
+func (l *hostLimiter) acquire(ctx context.Context, host string) (func(), error) {
+	host = strings.ToLower(host)
+
+	l.mu.Lock()
+	state, ok := l.hosts[host]
+	if !ok {
+		l.pruneIdle()
+		state = &hostState{
+			slots:  make(chan struct{}, l.config.HostConcurrency),
+			tokens: float64(l.config.HostBurst),
+			last:   time.Now(),
+		}
+		l.hosts[host] = state
+	}
+	state.users++
+	l.mu.Unlock()
+
+	select {
+	case state.slots <- struct{}{}:
+	case <-ctx.Done():
+		l.leave(state)
+		return nil, ctx.Err()
+	}
+
+	release := func() {
+		<-state.slots
+		l.leave(state)
+	}
+
+	if err := sleepContext(ctx, l.reserve(state)); err != nil {
+		release()
+		return nil, err
+	}
+
+	return release, nil
+}
+
+// reserve takes a token from the bucket of a host and returns how long to wait until it is available.
+func (l *hostLimiter) reserve(state *hostState) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.refill(state, time.Now())
+	state.tokens--
+	if state.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-state.tokens / float64(l.config.HostRate) * float64(time.Second))
+}
+
+// refill adds the tokens earned since the last refill. It must be called with mu held.
+func (l *hostLimiter) refill(state *hostState, now time.Time) {
+	elapsed := now.Sub(state.last).Seconds()
+	state.tokens = min(float64(l.config.HostBurst), state.tokens+elapsed*float64(l.config.HostRate))
+	state.last = now
+}
+
+func (l *hostLimiter) leave(state *hostState) {
+	l.mu.Lock()
+	state.users--
+	l.mu.Unlock()
+}
+
+// pruneIdle drops hosts without requests whose bucket is full again, as a new state would be the same.
+// It must be called with mu held.
+func (l *hostLimiter) pruneIdle() {
+	now := time.Now()
+	for host, state := range l.hosts {
+		if state.users > 0 {
+			continue
+		}
+		l.refill(state, now)
+		if state.tokens >= float64(l.config.HostBurst) {
+			delete(l.hosts, host)
+		}
+	}
+}
+
+// isRetryableStatus reports whether a response asks the client to come back later.
+func isRetryableStatus(statusCode int) bool {
+	return statusCode == http.StatusTooManyRequests || statusCode == http.StatusServiceUnavailable
+}
+
+// retryDelay returns how long to wait before retrying a 429 or 503 response. It is false when the server
+// asks for a longer wait than maxDelay.
+func retryDelay(resp *http.Response, attempt int, maxDelay time.Duration) (time.Duration, bool) {
+	delay := defaultRetryDelay << attempt
+
+	if value := strings.TrimSpace(resp.Header.Get("Retry-After")); value != "" {
+		if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+			delay = time.Duration(seconds) * time.Second
+		} else if at, err := http.ParseTime(value); err == nil {
+			delay = max(time.Until(at), 0)
+		}
+	}
+
+	if delay > maxDelay {
+		return 0, false
+	}
+	return delay, true
+}
+
+// sleepContext waits for d or until ctx is done.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
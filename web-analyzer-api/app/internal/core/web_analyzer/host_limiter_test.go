@@ -0,0 +1,172 @@
+package webanalyzer
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+	"web-analyzer-api/app/internal/model"
+	"web-analyzer-api/app/internal/util/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLinkCheckConfig_Defaults(t *testing.T) {
+	config := LinkCheckConfig{}.withDefaults()
+	assert.Equal(t, DefaultHostConcurrency, config.HostConcurrency)
+	assert.Equal(t, DefaultHostRate, config.HostRate)
+	assert.Equal(t, DefaultHostRate, config.HostBurst)
+	assert.Equal(t, DefaultLinkMaxRetries, config.MaxRetries)
+	assert.Equal(t, DefaultMaxRetryAfter, config.MaxRetryAfter)
+	assert.Equal(t, DefaultLinkCacheSize, config.CacheSize)
+	assert.Equal(t, DefaultLinkCacheSuccessTTL, config.CacheSuccessTTL)
+	assert.Equal(t, DefaultLinkCacheFailureTTL, config.CacheFailureTTL)
+}
+
+func TestHostLimiter_Concurrency(t *testing.T) {
+	limiter := newHostLimiter(LinkCheckConfig{HostConcurrency: 2, HostRate: 1000})
+
+	var inFlight, maxInFlight atomic.Int32
+	wg := sync.WaitGroup{}
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			release, err := limiter.acquire(context.Background(), "cdn.test")
+			if !assert.NoError(t, err) {
+				return
+			}
+			defer release()
+
+			current := inFlight.Add(1)
+			for {
+				seen := maxInFlight.Load()
+				if current <= seen || maxInFlight.CompareAndSwap(seen, current) {
+					break
+				}
+			}
+			time.Sleep(10 * time.Millisecond)
+			inFlight.Add(-1)
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(2), maxInFlight.Load())
+	assert.Empty(t, limiter.hosts["cdn.test"].slots)
+}
+
+func TestHostLimiter_Rate(t *testing.T) {
+	limiter := newHostLimiter(LinkCheckConfig{HostConcurrency: 10, HostRate: 20, HostBurst: 2})
+
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		release, err := limiter.acquire(context.Background(), "CDN.test")
+		require.NoError(t, err)
+		release()
+	}
+	// The burst is free, the two requests after it wait 50ms each
+	assert.GreaterOrEqual(t, time.Since(start), 90*time.Millisecond)
+
+	t.Run("Hosts have their own bucket", func(t *testing.T) {
+		start := time.Now()
+		release, err := limiter.acquire(context.Background(), "other.test")
+		require.NoError(t, err)
+		release()
+		assert.Less(t, time.Since(start), 40*time.Millisecond)
+	})
+
+	t.Run("Cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := limiter.acquire(ctx, "cdn.test")
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+}
+
+func TestRetryDelay(t *testing.T) {
+	resp := func(retryAfter string) *http.Response {
+		header := http.Header{}
+		if retryAfter != "" {
+			header.Set("Retry-After", retryAfter)
+		}
+		return &http.Response{StatusCode: http.StatusTooManyRequests, Header: header}
+	}
+
+	delay, ok := retryDelay(resp("3"), 0, time.Minute)
+	assert.True(t, ok)
+	assert.Equal(t, 3*time.Second, delay)
+
+	delay, ok = retryDelay(resp(time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat)), 0, time.Minute)
+	assert.True(t, ok)
+	assert.Zero(t, delay)
+
+	delay, ok = retryDelay(resp(""), 2, time.Minute)
+	assert.True(t, ok)
+	assert.Equal(t, 4*time.Second, delay)
+
+	_, ok = retryDelay(resp("120"), 0, time.Minute)
+	assert.False(t, ok)
+}
+
+func TestCheckLink_Retries(t *testing.T) {
+	log := logger.Get("info")
+	baseURL, _ := setupBaseURL()
+	client := newLinkCheckClient(model.AnalysisOptions{}, newTestNetworkGuard().Transport())
+
+	t.Run("Succeeds after Retry-After", func(t *testing.T) {
+		var requests atomic.Int32
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if requests.Add(1) == 1 {
+				w.Header().Set("Retry-After", "0")
+				w.WriteHeader(http.StatusTooManyRequests)
+			}
+		}))
+		defer ts.Close()
+
+		lc := NewLinkChecker(log, newTestNetworkGuard(), newTestRobotsCache(log), LinkCheckConfig{})
+		result := lc.CheckLink(context.Background(), client, ts.URL, baseURL, model.AnalysisOptions{})
+		require.NotNil(t, result)
+		assert.True(t, result.IsAccessible)
+		// A 429 to HEAD is retried rather than repeated with GET
+		assert.Equal(t, int32(2), requests.Load())
+	})
+
+	t.Run("Inaccessible after the retries", func(t *testing.T) {
+		var requests atomic.Int32
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests.Add(1)
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer ts.Close()
+
+		lc := NewLinkChecker(log, newTestNetworkGuard(), newTestRobotsCache(log), LinkCheckConfig{MaxRetries: 3})
+		result := lc.CheckLink(context.Background(), client, ts.URL, baseURL, model.AnalysisOptions{})
+		require.NotNil(t, result)
+		assert.False(t, result.IsAccessible)
+		assert.Equal(t, LinkErrorHTTP5xx, result.ErrorCategory)
+		assert.Equal(t, "503 Service Unavailable after 3 retries", result.ErrorMessage)
+		assert.Equal(t, int32(4), requests.Load())
+	})
+
+	t.Run("Retry-After above the limit", func(t *testing.T) {
+		var requests atomic.Int32
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests.Add(1)
+			w.Header().Set("Retry-After", "3600")
+			w.WriteHeader(http.StatusTooManyRequests)
+		}))
+		defer ts.Close()
+
+		lc := NewLinkChecker(log, newTestNetworkGuard(), newTestRobotsCache(log), LinkCheckConfig{})
+		result := lc.CheckLink(context.Background(), client, ts.URL, baseURL, model.AnalysisOptions{})
+		require.NotNil(t, result)
+		assert.Equal(t, LinkErrorHTTP4xx, result.ErrorCategory)
+		assert.Equal(t, "429 Too Many Requests", result.ErrorMessage)
+		assert.Equal(t, int32(1), requests.Load())
+	})
+}
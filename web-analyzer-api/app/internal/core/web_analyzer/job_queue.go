@@ -0,0 +1,234 @@
+package webanalyzer
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"sync"
+	"web-analyzer-api/app/internal/util/logger"
+)
+
+var (
+	ErrQueueFull   = errors.New("analysis queue is full")
+	ErrQueueClosed = errors.New("analysis queue is closed")
+)
+
+type jobHandler func(ctx context.Context, analysisId string, baseURL *url.URL)
+
+type analysisJob struct {
+	analysisId string
+	baseURL    *url.URL
+}
+
+// JobQueue is a bounded FIFO backlog of analyses served by a fixed number of workers.
+// A slot must be claimed with Reserve before the analysis is persisted, and then either
+// handed over with Submit or given back with Release.
+type JobQueue struct {
+	log      *logger.Logger
+	workers  int
+	backlog  int
+	mu       sync.Mutex
+	cond     *sync.Cond
+	waiting  []analysisJob
+	reserved int
+	running  map[string]context.CancelCauseFunc
+	closed   bool
+	workerWg sync.WaitGroup
+}
+
+func NewJobQueue(log *logger.Logger, workers int, backlog int) *JobQueue {
+	if workers < 1 {
+		workers = 1
+	}
+	if backlog < 1 {
+		backlog = 1
+	}
+
+	q := &JobQueue{
+		log:     log,
+		workers: workers,
+		backlog: backlog,
+		running: make(map[string]context.CancelCauseFunc),
+	}
+	q.cond = sync.NewCond(&q.mu)
+	return q
+}
+
+// Start launches the analysis workers which run handler for every submitted job.
+func (q *JobQueue) Start(handler jobHandler) {
+	for i := 0; i < q.workers; i++ {
+		q.workerWg.Add(1)
+		go q.runWorker(handler)
+	}
+	q.log.Info("Analysis workers started", "workers", q.workers, "backlog", q.backlog)
+}
+
+func (q *JobQueue) Reserve() error {
+	return q.ReserveN(1)
+}
+
+// ReserveN claims n backlog slots at once. Either all of them are claimed or none.
+func (q *JobQueue) ReserveN(n int) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.closed {
+		return ErrQueueClosed
+	}
+
+	if len(q.waiting)+q.reserved+n > q.backlog {
+		return ErrQueueFull
+	}
+
+	q.reserved += n
+	return nil
+}
+
+func (q *JobQueue) Release() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.reserved > 0 {
+		q.reserved--
+	}
+}
+
+func (q *JobQueue) Submit(analysisId string, baseURL *url.URL) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.reserved > 0 {
+		q.reserved--
+	}
+
+	// The queue may have been closed between Reserve and Submit
+	if q.closed {
+		return ErrQueueClosed
+	}
+
+	q.waiting = append(q.waiting, analysisJob{analysisId: analysisId, baseURL: baseURL})
+	q.cond.Signal()
+	return nil
+}
+
+// Position returns the 1-based position of a waiting analysis, or 0 if it is not waiting.
+func (q *JobQueue) Position(analysisId string) int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for i, job := range q.waiting {
+		if job.analysisId == analysisId {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// Remove drops a waiting analysis from the backlog and reports whether it was waiting.
+func (q *JobQueue) Remove(analysisId string) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for i, job := range q.waiting {
+		if job.analysisId == analysisId {
+			q.waiting = append(q.waiting[:i], q.waiting[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// Interrupt cancels the context of a running analysis with the given cause and reports whether it was running.
+func (q *JobQueue) Interrupt(analysisId string, cause error) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	cancel, ok := q.running[analysisId]
+	if ok {
+		cancel(cause)
+	}
+	return ok
+}
+
+// Close stops the queue from accepting new jobs and returns the ids of jobs that were still waiting.
+// Workers exit once their current job is finished.
+func (q *JobQueue) Close() []string {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.closed = true
+	ids := make([]string, len(q.waiting))
+	for i, job := range q.waiting {
+		ids[i] = job.analysisId
+	}
+	q.waiting = nil
+	q.cond.Broadcast()
+
+	return ids
+}
+
+// Wait blocks until all workers have exited or the context is done.
+func (q *JobQueue) Wait(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		q.workerWg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// InterruptAll cancels every running job with the given cause and returns their ids.
+func (q *JobQueue) InterruptAll(cause error) []string {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	ids := make([]string, 0, len(q.running))
+	for id, cancel := range q.running {
+		cancel(cause)
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+func (q *JobQueue) runWorker(handler jobHandler) {
+	defer q.workerWg.Done()
+
+	for {
+		q.mu.Lock()
+		for len(q.waiting) == 0 && !q.closed {
+			q.cond.Wait()
+		}
+		if len(q.waiting) == 0 {
+			q.mu.Unlock()
+			return
+		}
+		job := q.waiting[0]
+		q.waiting = q.waiting[1:]
+
+		// Register the job as running before releasing the lock so it is always either waiting or running
+		ctx, cancel := context.WithCancelCause(context.Background())
+		q.running[job.analysisId] = cancel
+		q.mu.Unlock()
+
+		q.log.Debug("Analysis worker picked up job: analyzeId - " + job.analysisId)
+		handler(ctx, job.analysisId, job.baseURL)
+
+		q.mu.Lock()
+		delete(q.running, job.analysisId)
+		q.mu.Unlock()
+		cancel(nil)
+	}
+}
+
+func (q *JobQueue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return len(q.waiting)
+}
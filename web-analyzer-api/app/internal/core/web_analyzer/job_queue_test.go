@@ -0,0 +1,179 @@
+package webanalyzer
+
+import (
+	"context"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+	"web-analyzer-api/app/internal/util/logger"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJobQueue_Reserve(t *testing.T) {
+	q := NewJobQueue(logger.Get("info"), 1, 2)
+	baseURL, _ := url.Parse("http://test.com")
+
+	assert.NoError(t, q.Reserve())
+	assert.NoError(t, q.Reserve())
+	assert.ErrorIs(t, q.Reserve(), ErrQueueFull)
+
+	// Submitted jobs keep occupying the backlog until a worker picks them up
+	q.Submit("id-1", baseURL)
+	assert.ErrorIs(t, q.Reserve(), ErrQueueFull)
+
+	q.Release()
+	assert.NoError(t, q.Reserve())
+}
+
+func TestJobQueue_ReserveN(t *testing.T) {
+	q := NewJobQueue(logger.Get("info"), 1, 5)
+
+	assert.NoError(t, q.ReserveN(3))
+	// A request larger than the remaining room claims nothing
+	assert.ErrorIs(t, q.ReserveN(3), ErrQueueFull)
+	assert.NoError(t, q.ReserveN(2))
+	assert.ErrorIs(t, q.Reserve(), ErrQueueFull)
+
+	q.Close()
+	assert.ErrorIs(t, q.ReserveN(1), ErrQueueClosed)
+}
+
+func TestJobQueue_Position(t *testing.T) {
+	q := NewJobQueue(logger.Get("info"), 1, 10)
+	baseURL, _ := url.Parse("http://test.com")
+
+	q.Submit("id-1", baseURL)
+	q.Submit("id-2", baseURL)
+	q.Submit("id-3", baseURL)
+
+	assert.Equal(t, 1, q.Position("id-1"))
+	assert.Equal(t, 2, q.Position("id-2"))
+	assert.Equal(t, 3, q.Position("id-3"))
+	assert.Equal(t, 0, q.Position("unknown"))
+	assert.Equal(t, 3, q.Len())
+}
+
+func TestJobQueue_Workers(t *testing.T) {
+	const workers = 2
+
+	q := NewJobQueue(logger.Get("info"), workers, 10)
+	baseURL, _ := url.Parse("http://test.com")
+
+	var (
+		mu      sync.Mutex
+		running int
+		peak    int
+		done    sync.WaitGroup
+	)
+	release := make(chan struct{})
+
+	q.Start(func(ctx context.Context, analysisId string, baseURL *url.URL) {
+		defer done.Done()
+
+		mu.Lock()
+		running++
+		if running > peak {
+			peak = running
+		}
+		mu.Unlock()
+
+		<-release
+
+		mu.Lock()
+		running--
+		mu.Unlock()
+	})
+
+	for _, id := range []string{"id-1", "id-2", "id-3", "id-4"} {
+		done.Add(1)
+		assert.NoError(t, q.Reserve())
+		q.Submit(id, baseURL)
+	}
+
+	assert.Eventually(t, func() bool { return q.Len() == 2 }, time.Second, 10*time.Millisecond)
+	assert.Equal(t, 1, q.Position("id-3"))
+
+	close(release)
+	done.Wait()
+
+	assert.Equal(t, workers, peak)
+	assert.Equal(t, 0, q.Len())
+}
+
+func TestJobQueue_Remove(t *testing.T) {
+	q := NewJobQueue(logger.Get("info"), 1, 10)
+	baseURL, _ := url.Parse("http://test.com")
+
+	q.Submit("id-1", baseURL)
+	q.Submit("id-2", baseURL)
+
+	assert.True(t, q.Remove("id-1"))
+	assert.False(t, q.Remove("id-1"))
+	assert.Equal(t, 1, q.Position("id-2"))
+	assert.Equal(t, 1, q.Len())
+}
+
+func TestJobQueue_Interrupt(t *testing.T) {
+	q := NewJobQueue(logger.Get("info"), 1, 10)
+	baseURL, _ := url.Parse("http://test.com")
+
+	started := make(chan struct{})
+	causes := make(chan error, 1)
+	q.Start(func(ctx context.Context, analysisId string, baseURL *url.URL) {
+		close(started)
+		<-ctx.Done()
+		causes <- context.Cause(ctx)
+	})
+
+	assert.False(t, q.Interrupt("id-1", ErrAnalysisCancelled))
+
+	q.Submit("id-1", baseURL)
+	<-started
+
+	assert.True(t, q.Interrupt("id-1", ErrAnalysisCancelled))
+	assert.ErrorIs(t, <-causes, ErrAnalysisCancelled)
+
+	assert.Eventually(t, func() bool { return !q.Interrupt("id-1", ErrAnalysisCancelled) }, time.Second, 10*time.Millisecond)
+}
+
+func TestJobQueue_Close(t *testing.T) {
+	q := NewJobQueue(logger.Get("info"), 2, 10)
+	baseURL, _ := url.Parse("http://test.com")
+
+	q.Start(func(ctx context.Context, analysisId string, baseURL *url.URL) {})
+
+	assert.NoError(t, q.Reserve())
+	q.Close()
+
+	assert.ErrorIs(t, q.Submit("id-1", baseURL), ErrQueueClosed)
+	assert.ErrorIs(t, q.Reserve(), ErrQueueClosed)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	assert.NoError(t, q.Wait(ctx))
+}
+
+func TestJobQueue_Wait(t *testing.T) {
+	q := NewJobQueue(logger.Get("info"), 1, 10)
+	baseURL, _ := url.Parse("http://test.com")
+
+	started := make(chan struct{})
+	q.Start(func(ctx context.Context, analysisId string, baseURL *url.URL) {
+		close(started)
+		<-ctx.Done()
+	})
+
+	assert.NoError(t, q.Submit("id-1", baseURL))
+	<-started
+
+	assert.Empty(t, q.Close())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, q.Wait(ctx), context.DeadlineExceeded)
+
+	assert.Equal(t, []string{"id-1"}, q.InterruptAll(ErrAnalysisInterrupted))
+	assert.NoError(t, q.Wait(context.Background()))
+}
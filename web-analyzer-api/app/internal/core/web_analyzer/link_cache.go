@@ -0,0 +1,113 @@
+package webanalyzer
+
+import (
+	"container/list"
+	"strconv"
+	"sync"
+	"time"
+	"web-analyzer-api/app/internal/model"
+	"web-analyzer-api/app/internal/util/metrics"
+)
+
+const (
+	DefaultLinkCacheSize       = 10000
+	DefaultLinkCacheSuccessTTL = 10 * time.Minute
+	DefaultLinkCacheFailureTTL = time.Minute
+)
+
+// linkResultCache keeps link check results across analyses, evicting the least recently used result once
+// it holds size results. Accessible and inaccessible results expire after their own TTL.
+type linkResultCache struct {
+	size       int
+	successTTL time.Duration
+	failureTTL time.Duration
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	order   *list.List
+}
+
+type linkCacheEntry struct {
+	key     string
+	result  model.LinkCheckResult
+	expires time.Time
+}
+
+func newLinkResultCache(size int, successTTL time.Duration, failureTTL time.Duration) *linkResultCache {
+	metrics.RegisterLinkCacheMetrics()
+
+	return &linkResultCache{
+		size:       size,
+		successTTL: successTTL,
+		failureTTL: failureTTL,
+		entries:    make(map[string]*list.Element),
+		order:      list.New(),
+	}
+}
+
+// linkCacheKey identifies a check of target. Options that change the result of a check are part of it.
+func linkCacheKey(target string, options model.AnalysisOptions) string {
+	return strconv.FormatBool(options.SkipRedirects) + " " + linkTimeout(options).String() + " " + options.UserAgent + " " + target
+}
+
+// Get returns the cached result for key unless it has expired.
+func (c *linkResultCache) Get(key string) (model.LinkCheckResult, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.entries[key]
+	if !ok {
+		metrics.RecordLinkCacheMiss()
+		return model.LinkCheckResult{}, false
+	}
+
+	entry := element.Value.(*linkCacheEntry)
+	if time.Now().After(entry.expires) {
+		c.remove(element)
+		metrics.RecordLinkCacheMiss()
+		return model.LinkCheckResult{}, false
+	}
+
+	c.order.MoveToFront(element)
+	metrics.RecordLinkCacheHit()
+	return cloneLinkCheckResult(entry.result), true
+}
+
+// Put stores result under key, evicting the least recently used results when the cache is full.
+func (c *linkResultCache) Put(key string, result model.LinkCheckResult) {
+	ttl := c.successTTL
+	if !result.IsAccessible {
+		ttl = c.failureTTL
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry := &linkCacheEntry{key: key, result: cloneLinkCheckResult(result), expires: time.Now().Add(ttl)}
+	if element, ok := c.entries[key]; ok {
+		element.Value = entry
+		c.order.MoveToFront(element)
+		return
+	}
+
+	c.entries[key] = c.order.PushFront(entry)
+	for c.order.Len() > c.size {
+		c.remove(c.order.Back())
+		metrics.RecordLinkCacheEviction()
+	}
+	metrics.SetLinkCacheEntries(c.order.Len())
+}
+
+// remove drops an element. It must be called with mu held.
+func (c *linkResultCache) remove(element *list.Element) {
+	c.order.Remove(element)
+	delete(c.entries, element.Value.(*linkCacheEntry).key)
+	metrics.SetLinkCacheEntries(c.order.Len())
+}
+
+func cloneLinkCheckResult(result model.LinkCheckResult) model.LinkCheckResult {
+	if result.Redirects != nil {
+		result.Redirects = append([]model.RedirectHop(nil), result.Redirects...)
+	}
+	return result
+}
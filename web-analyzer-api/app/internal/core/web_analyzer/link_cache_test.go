@@ -0,0 +1,116 @@
+package webanalyzer
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+	"web-analyzer-api/app/internal/model"
+	"web-analyzer-api/app/internal/util/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLinkResultCache(t *testing.T) {
+	t.Run("Hit and miss", func(t *testing.T) {
+		cache := newLinkResultCache(10, time.Minute, time.Minute)
+
+		_, ok := cache.Get("a")
+		assert.False(t, ok)
+
+		cache.Put("a", model.LinkCheckResult{URL: "a", IsAccessible: true, Redirects: []model.RedirectHop{{URL: "b"}}})
+		result, ok := cache.Get("a")
+		require.True(t, ok)
+		assert.Equal(t, "a", result.URL)
+
+		// Results are copies
+		result.Redirects[0].URL = "changed"
+		result, _ = cache.Get("a")
+		assert.Equal(t, "b", result.Redirects[0].URL)
+	})
+
+	t.Run("Success and failure TTL", func(t *testing.T) {
+		cache := newLinkResultCache(10, time.Minute, 20*time.Millisecond)
+		cache.Put("ok", model.LinkCheckResult{IsAccessible: true})
+		cache.Put("broken", model.LinkCheckResult{IsAccessible: false})
+
+		time.Sleep(30 * time.Millisecond)
+
+		_, ok := cache.Get("ok")
+		assert.True(t, ok)
+		_, ok = cache.Get("broken")
+		assert.False(t, ok)
+		assert.Equal(t, 1, cache.order.Len())
+	})
+
+	t.Run("Evicts the least recently used", func(t *testing.T) {
+		cache := newLinkResultCache(2, time.Minute, time.Minute)
+		cache.Put("a", model.LinkCheckResult{IsAccessible: true})
+		cache.Put("b", model.LinkCheckResult{IsAccessible: true})
+		cache.Get("a")
+		cache.Put("c", model.LinkCheckResult{IsAccessible: true})
+
+		_, ok := cache.Get("a")
+		assert.True(t, ok)
+		_, ok = cache.Get("b")
+		assert.False(t, ok)
+		_, ok = cache.Get("c")
+		assert.True(t, ok)
+	})
+}
+
+func TestCheckLink_Cache(t *testing.T) {
+	log := logger.Get("info")
+	baseURL, _ := setupBaseURL()
+	client := newLinkCheckClient(model.AnalysisOptions{}, newTestNetworkGuard().Transport())
+
+	var requests atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+	}))
+	defer ts.Close()
+
+	lc := NewLinkChecker(log, newTestNetworkGuard(), newTestRobotsCache(log), LinkCheckConfig{})
+
+	result := lc.CheckLink(context.Background(), client, ts.URL+"/page", baseURL, model.AnalysisOptions{})
+	require.NotNil(t, result)
+	assert.True(t, result.IsAccessible)
+
+	// The same link in another form is served from the cache
+	result = lc.CheckLink(context.Background(), client, ts.URL+"/page#top", baseURL, model.AnalysisOptions{})
+	require.NotNil(t, result)
+	assert.True(t, result.IsAccessible)
+	assert.Equal(t, ts.URL+"/page#top", result.URL)
+	assert.Equal(t, int32(1), requests.Load())
+
+	// Options that change the result are checked separately
+	result = lc.CheckLink(context.Background(), client, ts.URL+"/page", baseURL, model.AnalysisOptions{UserAgent: "other"})
+	require.NotNil(t, result)
+	assert.Equal(t, int32(2), requests.Load())
+}
+
+func TestCheckLink_CacheByTimeout(t *testing.T) {
+	log := logger.Get("info")
+	baseURL, _ := setupBaseURL()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+	}))
+	defer ts.Close()
+
+	lc := NewLinkChecker(log, newTestNetworkGuard(), newTestRobotsCache(log), LinkCheckConfig{})
+
+	shortOptions := model.AnalysisOptions{LinkTimeout: 50 * time.Millisecond}
+	result := lc.CheckLink(context.Background(), newLinkCheckClient(shortOptions, newTestNetworkGuard().Transport()), ts.URL+"/slow", baseURL, shortOptions)
+	require.NotNil(t, result)
+	assert.False(t, result.IsAccessible)
+
+	// A timeout recorded by an analysis with a short link timeout is not served to one with a longer timeout
+	longOptions := model.AnalysisOptions{LinkTimeout: 5 * time.Second}
+	result = lc.CheckLink(context.Background(), newLinkCheckClient(longOptions, newTestNetworkGuard().Transport()), ts.URL+"/slow", baseURL, longOptions)
+	require.NotNil(t, result)
+	assert.True(t, result.IsAccessible)
+}
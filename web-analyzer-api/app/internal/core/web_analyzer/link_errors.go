@@ -0,0 +1,87 @@
+package webanalyzer
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+)
+
+// Causes of an inaccessible link.
+const (
+	LinkErrorBlocked          = "blocked"
+	LinkErrorDNS              = "dns"
+	LinkErrorRefused          = "refused"
+	LinkErrorTimeout          = "timeout"
+	LinkErrorTLS              = "tls"
+	LinkErrorTooManyRedirects = RedirectErrorTooMany
+	LinkErrorRedirectLoop     = RedirectErrorLoop
+	LinkErrorHTTP4xx          = "http_4xx"
+	LinkErrorHTTP5xx          = "http_5xx"
+	LinkErrorNetwork          = "network"
+	LinkErrorMissingFragment  = "missing_fragment"
+)
+
+// classifyLinkError returns the cause of a failed link request.
+func classifyLinkError(err error) string {
+	if code := redirectErrorCode(err); code != "" {
+		return code
+	}
+
+	if errors.Is(err, ErrBlockedAddress) {
+		return LinkErrorBlocked
+	}
+
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return LinkErrorDNS
+	}
+
+	if errors.Is(err, syscall.ECONNREFUSED) {
+		return LinkErrorRefused
+	}
+
+	if isTLSError(err) {
+		return LinkErrorTLS
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return LinkErrorTimeout
+	}
+
+	return LinkErrorNetwork
+}
+
+func isTLSError(err error) bool {
+	var (
+		verificationErr *tls.CertificateVerificationError
+		recordHeaderErr tls.RecordHeaderError
+		alertErr        tls.AlertError
+		unknownAuthErr  x509.UnknownAuthorityError
+		hostnameErr     x509.HostnameError
+		invalidCertErr  x509.CertificateInvalidError
+	)
+
+	return errors.As(err, &verificationErr) || errors.As(err, &recordHeaderErr) || errors.As(err, &alertErr) ||
+		errors.As(err, &unknownAuthErr) || errors.As(err, &hostnameErr) || errors.As(err, &invalidCertErr)
+}
+
+// classifyStatus returns the cause of an error status, or an empty string for a successful one.
+func classifyStatus(statusCode int) string {
+	switch {
+	case statusCode >= 500:
+		return LinkErrorHTTP5xx
+	case statusCode >= 400:
+		return LinkErrorHTTP4xx
+	default:
+		return ""
+	}
+}
+
+func statusMessage(statusCode int) string {
+	return strconv.Itoa(statusCode) + " " + http.StatusText(statusCode)
+}
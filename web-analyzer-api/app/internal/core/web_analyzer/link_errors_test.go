@@ -0,0 +1,93 @@
+package webanalyzer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+	"web-analyzer-api/app/internal/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClassifyLinkError(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected string
+	}{
+		{"DNS", &url.Error{Op: "Head", URL: "http://missing.invalid", Err: &net.OpError{Op: "dial", Err: &net.DNSError{Err: "no such host", Name: "missing.invalid"}}}, LinkErrorDNS},
+		{"Refused", &url.Error{Op: "Head", URL: "http://localhost:1", Err: &net.OpError{Op: "dial", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)}}, LinkErrorRefused},
+		{"Timeout", &url.Error{Op: "Head", URL: "http://slow.test", Err: context.DeadlineExceeded}, LinkErrorTimeout},
+		{"Redirect loop", ErrRedirectLoop, LinkErrorRedirectLoop},
+		{"Too many redirects", fmt.Errorf("check: %w", ErrTooManyRedirects), LinkErrorTooManyRedirects},
+		{"Other", errors.New("connection reset"), LinkErrorNetwork},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, classifyLinkError(tt.err))
+		})
+	}
+}
+
+func TestClassifyStatus(t *testing.T) {
+	assert.Equal(t, "", classifyStatus(http.StatusOK))
+	assert.Equal(t, "", classifyStatus(http.StatusFound))
+	assert.Equal(t, LinkErrorHTTP4xx, classifyStatus(http.StatusNotFound))
+	assert.Equal(t, LinkErrorHTTP5xx, classifyStatus(http.StatusBadGateway))
+	assert.Equal(t, "404 Not Found", statusMessage(http.StatusNotFound))
+}
+
+func TestCheckLink_ErrorCategories(t *testing.T) {
+	baseURL, lc := setupBaseURL()
+
+	t.Run("Refused", func(t *testing.T) {
+		result := lc.CheckLink(context.Background(), newLinkCheckClient(model.AnalysisOptions{}, newTestNetworkGuard().Transport()), "http://localhost:1", baseURL, model.AnalysisOptions{})
+		require.NotNil(t, result)
+		assert.Equal(t, LinkErrorRefused, result.ErrorCategory)
+		assert.NotEmpty(t, result.ErrorMessage)
+	})
+
+	t.Run("TLS", func(t *testing.T) {
+		ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		defer ts.Close()
+
+		result := lc.CheckLink(context.Background(), newLinkCheckClient(model.AnalysisOptions{}, newTestNetworkGuard().Transport()), ts.URL, baseURL, model.AnalysisOptions{})
+		require.NotNil(t, result)
+		assert.False(t, result.IsAccessible)
+		assert.Equal(t, LinkErrorTLS, result.ErrorCategory)
+	})
+
+	t.Run("Timeout", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			time.Sleep(200 * time.Millisecond)
+		}))
+		defer ts.Close()
+
+		options := model.AnalysisOptions{LinkTimeout: 20 * time.Millisecond}
+		result := lc.CheckLink(context.Background(), newLinkCheckClient(options, newTestNetworkGuard().Transport()), ts.URL, baseURL, options)
+		require.NotNil(t, result)
+		assert.Equal(t, LinkErrorTimeout, result.ErrorCategory)
+	})
+
+	t.Run("HTTP status", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadGateway)
+		}))
+		defer ts.Close()
+
+		result := lc.CheckLink(context.Background(), newLinkCheckClient(model.AnalysisOptions{}, newTestNetworkGuard().Transport()), ts.URL, baseURL, model.AnalysisOptions{})
+		require.NotNil(t, result)
+		assert.Equal(t, LinkErrorHTTP5xx, result.ErrorCategory)
+		assert.Equal(t, "502 Bad Gateway", result.ErrorMessage)
+	})
+}
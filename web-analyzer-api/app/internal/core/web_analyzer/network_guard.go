@@ -0,0 +1,139 @@
+package webanalyzer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+	"syscall"
+	"time"
+)
+
+var ErrBlockedAddress = errors.New("address is not allowed")
+
+// NetworkGuardConfig controls which addresses page fetches and link checks may connect to.
+type NetworkGuardConfig struct {
+	// AllowPrivateNetworks turns the guard off.
+	AllowPrivateNetworks bool
+	// AllowedHosts are host names, IP addresses or CIDR ranges that may be reached even though they are private.
+	AllowedHosts []string
+}
+
+// blockedPrefixes are special purpose ranges not covered by the net/netip classification helpers.
+var blockedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+	netip.MustParsePrefix("64:ff9b::/96"),
+	netip.MustParsePrefix("fec0::/10"),
+}
+
+// NetworkGuard dials outgoing connections for analyses and refuses loopback, private, link-local (including
+// cloud metadata endpoints) and other special purpose addresses. The check runs on the resolved address of
+// every connection, so it also covers redirects and host names that re-resolve to a different address.
+type NetworkGuard struct {
+	allowAll      bool
+	allowedHosts  map[string]bool
+	allowedRanges []netip.Prefix
+	dialer        *net.Dialer
+	hostDialer    *net.Dialer
+	transport     *http.Transport
+}
+
+func NewNetworkGuard(config NetworkGuardConfig) *NetworkGuard {
+	g := &NetworkGuard{
+		allowAll:     config.AllowPrivateNetworks,
+		allowedHosts: make(map[string]bool),
+	}
+
+	for _, entry := range config.AllowedHosts {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		if entry == "" {
+			continue
+		}
+		if prefix, err := netip.ParsePrefix(entry); err == nil {
+			g.allowedRanges = append(g.allowedRanges, prefix.Masked())
+			continue
+		}
+		if addr, err := netip.ParseAddr(entry); err == nil {
+			addr = addr.Unmap()
+			g.allowedRanges = append(g.allowedRanges, netip.PrefixFrom(addr, addr.BitLen()))
+			continue
+		}
+		g.allowedHosts[strings.TrimSuffix(entry, ".")] = true
+	}
+
+	g.dialer = &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second, Control: g.control}
+	g.hostDialer = &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+
+	// Requests are never sent through a proxy, which would hide the real destination from the guard
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = g.DialContext
+	g.transport = transport
+
+	return g
+}
+
+// Transport returns the shared HTTP transport whose connections are dialed through the guard.
+func (g *NetworkGuard) Transport() http.RoundTripper {
+	return g.transport
+}
+
+// DialContext connects to address unless it resolves to a blocked address. Allowlisted host names are
+// dialed without checking the addresses they resolve to.
+func (g *NetworkGuard) DialContext(ctx context.Context, network string, address string) (net.Conn, error) {
+	if host, _, err := net.SplitHostPort(address); err == nil && g.allowedHosts[strings.TrimSuffix(strings.ToLower(host), ".")] {
+		return g.hostDialer.DialContext(ctx, network, address)
+	}
+	return g.dialer.DialContext(ctx, network, address)
+}
+
+// control is called with the resolved address right before each connection attempt.
+func (g *NetworkGuard) control(network string, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrBlockedAddress, address)
+	}
+
+	addr, err := netip.ParseAddr(host)
+	if err != nil || !g.Allowed(addr) {
+		return fmt.Errorf("%w: %s", ErrBlockedAddress, host)
+	}
+	return nil
+}
+
+// Allowed reports whether connections to addr are permitted.
+func (g *NetworkGuard) Allowed(addr netip.Addr) bool {
+	if g.allowAll {
+		return true
+	}
+
+	addr = addr.Unmap()
+	for _, prefix := range g.allowedRanges {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+
+	return !isBlockedAddr(addr)
+}
+
+func isBlockedAddr(addr netip.Addr) bool {
+	if addr.IsLoopback() || addr.IsPrivate() || addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() ||
+		addr.IsInterfaceLocalMulticast() || addr.IsMulticast() || addr.IsUnspecified() {
+		return true
+	}
+
+	for _, prefix := range blockedPrefixes {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
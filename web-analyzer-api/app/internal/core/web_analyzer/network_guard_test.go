@@ -0,0 +1,117 @@
+package webanalyzer
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+	"web-analyzer-api/app/internal/contract"
+	"web-analyzer-api/app/internal/model"
+	"web-analyzer-api/app/internal/repositorymemory"
+	"web-analyzer-api/app/internal/util/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNetworkGuard_Allowed(t *testing.T) {
+	guard := NewNetworkGuard(NetworkGuardConfig{})
+
+	blocked := []string{
+		"127.0.0.1", "::1", "10.1.2.3", "172.16.0.1", "192.168.1.1", "169.254.169.254", "100.64.0.1",
+		"0.0.0.0", "::", "fd00:ec2::254", "fe80::1", "::ffff:127.0.0.1", "::ffff:169.254.169.254", "224.0.0.1",
+	}
+	for _, address := range blocked {
+		assert.False(t, guard.Allowed(netip.MustParseAddr(address)), address)
+	}
+
+	allowed := []string{"93.184.216.34", "8.8.8.8", "2606:4700:4700::1111"}
+	for _, address := range allowed {
+		assert.True(t, guard.Allowed(netip.MustParseAddr(address)), address)
+	}
+
+	t.Run("Allowlist", func(t *testing.T) {
+		guard := NewNetworkGuard(NetworkGuardConfig{AllowedHosts: []string{"10.20.0.0/16", " 192.168.1.5 ", "staging.internal"}})
+		assert.True(t, guard.Allowed(netip.MustParseAddr("10.20.3.4")))
+		assert.True(t, guard.Allowed(netip.MustParseAddr("192.168.1.5")))
+		assert.False(t, guard.Allowed(netip.MustParseAddr("10.21.0.1")))
+		assert.False(t, guard.Allowed(netip.MustParseAddr("192.168.1.6")))
+		assert.True(t, guard.allowedHosts["staging.internal"])
+	})
+
+	t.Run("Private networks allowed", func(t *testing.T) {
+		guard := NewNetworkGuard(NetworkGuardConfig{AllowPrivateNetworks: true})
+		assert.True(t, guard.Allowed(netip.MustParseAddr("169.254.169.254")))
+	})
+}
+
+func TestNetworkGuard_Transport(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer ts.Close()
+	port := ts.URL[strings.LastIndex(ts.URL, ":")+1:]
+
+	t.Run("Blocked address", func(t *testing.T) {
+		client := &http.Client{Transport: NewNetworkGuard(NetworkGuardConfig{}).Transport()}
+		_, err := client.Get(ts.URL)
+		assert.ErrorIs(t, err, ErrBlockedAddress)
+	})
+
+	t.Run("Blocked after resolving", func(t *testing.T) {
+		client := &http.Client{Transport: NewNetworkGuard(NetworkGuardConfig{}).Transport()}
+		_, err := client.Get("http://localhost:" + port)
+		assert.ErrorIs(t, err, ErrBlockedAddress)
+	})
+
+	t.Run("Allowlisted host name", func(t *testing.T) {
+		client := &http.Client{Transport: NewNetworkGuard(NetworkGuardConfig{AllowedHosts: []string{"LOCALHOST"}}).Transport()}
+		resp, err := client.Get("http://localhost:" + port)
+		require.NoError(t, err)
+		resp.Body.Close()
+	})
+}
+
+func TestCheckLink_BlockedAddress(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer ts.Close()
+
+	baseURL, lc := setupBaseURL()
+	client := newLinkCheckClient(model.AnalysisOptions{}, NewNetworkGuard(NetworkGuardConfig{}).Transport())
+
+	result := lc.CheckLink(context.Background(), client, ts.URL, baseURL, model.AnalysisOptions{})
+	require.NotNil(t, result)
+	assert.False(t, result.IsAccessible)
+	assert.Equal(t, LinkErrorBlocked, result.ErrorCategory)
+}
+
+func TestAnalyzeWebsite_BlockedRedirect(t *testing.T) {
+	log := logger.Get("info")
+
+	var ts *httptest.Server
+	ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Redirect from the allowlisted host name to the loopback address itself
+		http.Redirect(w, r, ts.URL+"/internal", http.StatusFound)
+	}))
+	defer ts.Close()
+	port := ts.URL[strings.LastIndex(ts.URL, ":")+1:]
+
+	guard := NewNetworkGuard(NetworkGuardConfig{AllowedHosts: []string{"localhost"}})
+	service := NewWebAnalyzerService(log, repositorymemory.NewWebAnalyzerRepo(log), repositorymemory.NewBatchRepo(log), repositorymemory.NewCrawlRepo(log), NewLinkChecker(log, guard, NewRobotsCache(log, guard, RobotsConfig{}), LinkCheckConfig{}), NewJobQueue(log, 1, 10), newTestWebhookDispatcher(log), guard, PageFetchConfig{}, CrawlConfig{})
+	defer service.Shutdown(context.Background())
+
+	pageURL, _ := url.Parse("http://localhost:" + port + "/")
+	id, err := service.AnalyzeWebsite(context.Background(), pageURL, "", contract.AnalysisOptions{})
+	require.NoError(t, err)
+
+	var result *contract.WebAnalyzeResponse
+	require.Eventually(t, func() bool {
+		result, err = service.GetAnalyzeData(context.Background(), id)
+		return err == nil && result.Status == StatusFailed
+	}, 5*time.Second, 20*time.Millisecond)
+
+	assert.Equal(t, "URL cannot be accessed. URL resolves to a private or reserved address.", result.ErrorDescription)
+	assert.Len(t, result.Redirects, 1)
+}
@@ -0,0 +1,233 @@
+package webanalyzer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+	"web-analyzer-api/app/internal/model"
+)
+
+const (
+	DefaultPageConnectTimeout = 10 * time.Second
+	DefaultPageHeaderTimeout  = 15 * time.Second
+	DefaultPageTimeout        = 30 * time.Second
+	DefaultPageMaxBodyBytes   = 10 << 20
+)
+
+var (
+	ErrUnexpectedStatus       = errors.New("unexpected status code")
+	ErrUnsupportedContentType = errors.New("unsupported content type")
+	ErrPageTooLarge           = errors.New("page exceeds the maximum size")
+	ErrPageTimeout            = errors.New("page fetch timed out")
+)
+
+// htmlContentTypes are the media types accepted for an analyzed page. A response without a
+// Content-Type header is accepted as well.
+var htmlContentTypes = map[string]bool{
+	"text/html":             true,
+	"application/xhtml+xml": true,
+}
+
+// PageFetchConfig limits the fetch of an analyzed page. Zero values mean the defaults.
+type PageFetchConfig struct {
+	// ConnectTimeout bounds establishing the connection of each request.
+	ConnectTimeout time.Duration
+	// HeaderTimeout bounds waiting for the response headers after the request is sent.
+	HeaderTimeout time.Duration
+	// Timeout bounds the whole fetch, including redirects and reading the body.
+	Timeout time.Duration
+	// MaxBodyBytes is the largest body that is read.
+	MaxBodyBytes int64
+}
+
+func (c PageFetchConfig) withDefaults() PageFetchConfig {
+	if c.ConnectTimeout <= 0 {
+		c.ConnectTimeout = DefaultPageConnectTimeout
+	}
+	if c.HeaderTimeout <= 0 {
+		c.HeaderTimeout = DefaultPageHeaderTimeout
+	}
+	if c.Timeout <= 0 {
+		c.Timeout = DefaultPageTimeout
+	}
+	if c.MaxBodyBytes <= 0 {
+		c.MaxBodyBytes = DefaultPageMaxBodyBytes
+	}
+	return c
+}
+
+type contentTypeError struct {
+	contentType string
+}
+
+func (e *contentTypeError) Error() string {
+	return ErrUnsupportedContentType.Error() + ": " + e.contentType
+}
+
+func (e *contentTypeError) Is(target error) bool {
+	return target == ErrUnsupportedContentType
+}
+
+type fetchedPage struct {
+	Body        []byte
+	ContentType string
+}
+
+// pageFetcher fetches analyzed pages through the network guard within the configured limits.
+type pageFetcher struct {
+	config PageFetchConfig
+	client *http.Client
+}
+
+func newPageFetcher(guard *NetworkGuard, config PageFetchConfig) *pageFetcher {
+	config = config.withDefaults()
+
+	transport := guard.transport.Clone()
+	transport.ResponseHeaderTimeout = config.HeaderTimeout
+	transport.DialContext = func(ctx context.Context, network string, address string) (net.Conn, error) {
+		ctx, cancel := context.WithTimeout(ctx, config.ConnectTimeout)
+		defer cancel()
+		return guard.DialContext(ctx, network, address)
+	}
+
+	return &pageFetcher{
+		config: config,
+		client: &http.Client{
+			Transport: transport,
+			// Redirects are followed by followRedirects so that every hop is recorded
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+	}
+}
+
+// fetch requests target, following redirects, and reads the HTML body. The redirect chain is returned
+// even when the fetch fails.
+func (f *pageFetcher) fetch(ctx context.Context, target string, userAgent string) (*fetchedPage, []model.RedirectHop, error) {
+	fetchCtx, cancel := context.WithTimeout(ctx, f.config.Timeout)
+	defer cancel()
+
+	resp, chain, err := f.get(fetchCtx, target, userAgent)
+	if err != nil {
+		return nil, chain, f.timeoutError(ctx, err)
+	}
+	defer resp.Body.Close()
+
+	contentType := resp.Header.Get("Content-Type")
+	if contentType != "" {
+		mediaType, _, err := mime.ParseMediaType(contentType)
+		if err != nil || !htmlContentTypes[mediaType] {
+			return nil, chain, &contentTypeError{contentType: contentType}
+		}
+	}
+
+	body, err := readLimited(resp, f.config.MaxBodyBytes)
+	if err != nil {
+		return nil, chain, f.timeoutError(ctx, err)
+	}
+
+	return &fetchedPage{Body: body, ContentType: contentType}, chain, nil
+}
+
+// fetchFile requests target, following redirects, and reads a body of any content type up to maxBytes.
+// It is used for the files describing a site, such as robots.txt and sitemaps.
+func (f *pageFetcher) fetchFile(ctx context.Context, target string, userAgent string, maxBytes int64) ([]byte, error) {
+	fetchCtx, cancel := context.WithTimeout(ctx, f.config.Timeout)
+	defer cancel()
+
+	resp, _, err := f.get(fetchCtx, target, userAgent)
+	if err != nil {
+		return nil, f.timeoutError(ctx, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := readLimited(resp, maxBytes)
+	if err != nil {
+		return nil, f.timeoutError(ctx, err)
+	}
+	return body, nil
+}
+
+// get requests target and follows its redirects. The last response is returned only when it is a 200 OK.
+func (f *pageFetcher) get(ctx context.Context, target string, userAgent string) (*http.Response, []model.RedirectHop, error) {
+	resp, chain, err := followRedirects(target, false, func(target string) (*http.Response, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+		if err != nil {
+			return nil, err
+		}
+		if userAgent != "" {
+			req.Header.Set("User-Agent", userAgent)
+		}
+		return f.client.Do(req)
+	})
+	if err != nil {
+		return nil, chain, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, chain, fmt.Errorf("%w: %d", ErrUnexpectedStatus, resp.StatusCode)
+	}
+	return resp, chain, nil
+}
+
+// readLimited reads the body of resp, failing with ErrPageTooLarge when it is larger than maxBytes.
+func readLimited(resp *http.Response, maxBytes int64) ([]byte, error) {
+	if resp.ContentLength > maxBytes {
+		return nil, ErrPageTooLarge
+	}
+
+	// One byte more than the limit is read to tell a body of exactly the maximum size from a larger one
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(body)) > maxBytes {
+		return nil, ErrPageTooLarge
+	}
+	return body, nil
+}
+
+// timeoutError reports err as ErrPageTimeout when one of the fetch limits, rather than the analysis
+// being stopped, ended the request.
+func (f *pageFetcher) timeoutError(ctx context.Context, err error) error {
+	if ctx.Err() != nil {
+		return err
+	}
+
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) {
+		return fmt.Errorf("%w: %w", ErrPageTimeout, err)
+	}
+	return err
+}
+
+// failureDescription returns the error description of an analysis whose page could not be fetched.
+func (f *pageFetcher) failureDescription(err error) string {
+	var contentTypeErr *contentTypeError
+
+	switch {
+	case errors.Is(err, ErrBlockedAddress):
+		return "URL cannot be accessed. URL resolves to a private or reserved address."
+	case errors.Is(err, ErrRedirectLoop):
+		return "URL cannot be accessed. URL redirects in a loop."
+	case errors.Is(err, ErrTooManyRedirects):
+		return "URL cannot be accessed. URL exceeded the maximum of " + strconv.Itoa(MaxRedirects) + " redirects."
+	case errors.Is(err, ErrPageTimeout):
+		return "URL cannot be accessed. The page was not fetched within the time limits (connect " + f.config.ConnectTimeout.String() +
+			", response headers " + f.config.HeaderTimeout.String() + ", total " + f.config.Timeout.String() + ")."
+	case errors.As(err, &contentTypeErr):
+		return "URL does not serve an HTML page (content type " + contentTypeErr.contentType + "). Only text/html and application/xhtml+xml can be analyzed."
+	case errors.Is(err, ErrPageTooLarge):
+		return "Page exceeds the maximum size of " + strconv.FormatInt(f.config.MaxBodyBytes, 10) + " bytes."
+	default:
+		return "URL cannot be accessed. URL is invalid or unreachable."
+	}
+}
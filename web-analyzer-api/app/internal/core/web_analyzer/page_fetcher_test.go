@@ -0,0 +1,143 @@
+package webanalyzer
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+	"web-analyzer-api/app/internal/contract"
+	"web-analyzer-api/app/internal/repositorymemory"
+	"web-analyzer-api/app/internal/util/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPageFetchConfig_Defaults(t *testing.T) {
+	config := PageFetchConfig{}.withDefaults()
+	assert.Equal(t, DefaultPageConnectTimeout, config.ConnectTimeout)
+	assert.Equal(t, DefaultPageHeaderTimeout, config.HeaderTimeout)
+	assert.Equal(t, DefaultPageTimeout, config.Timeout)
+	assert.Equal(t, int64(DefaultPageMaxBodyBytes), config.MaxBodyBytes)
+
+	config = PageFetchConfig{Timeout: time.Second, MaxBodyBytes: 10}.withDefaults()
+	assert.Equal(t, time.Second, config.Timeout)
+	assert.Equal(t, int64(10), config.MaxBodyBytes)
+}
+
+func TestPageFetcher_Fetch(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/page":
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.Write([]byte("<html></html>"))
+		case "/xhtml":
+			w.Header().Set("Content-Type", "application/xhtml+xml")
+			w.Write([]byte("<html></html>"))
+		case "/pdf":
+			w.Header().Set("Content-Type", "application/pdf")
+			w.Write([]byte("%PDF-1.7"))
+		case "/large":
+			w.Header().Set("Content-Type", "text/html")
+			w.Write([]byte(strings.Repeat("a", 64)))
+		case "/streamed":
+			// Flushing before writing the rest leaves the response without a Content-Length
+			w.Header().Set("Content-Type", "text/html")
+			w.(http.Flusher).Flush()
+			w.Write([]byte(strings.Repeat("a", 64)))
+		case "/slow-headers":
+			time.Sleep(200 * time.Millisecond)
+		case "/slow-body":
+			w.Header().Set("Content-Type", "text/html")
+			w.(http.Flusher).Flush()
+			time.Sleep(200 * time.Millisecond)
+		case "/missing":
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	fetcher := newPageFetcher(newTestNetworkGuard(), PageFetchConfig{MaxBodyBytes: 32})
+
+	t.Run("HTML page", func(t *testing.T) {
+		page, chain, err := fetcher.fetch(context.Background(), ts.URL+"/page", "")
+		require.NoError(t, err)
+		assert.Equal(t, "<html></html>", string(page.Body))
+		assert.Equal(t, "text/html; charset=utf-8", page.ContentType)
+		assert.Len(t, chain, 1)
+
+		_, _, err = fetcher.fetch(context.Background(), ts.URL+"/xhtml", "")
+		assert.NoError(t, err)
+	})
+
+	t.Run("Unsupported content type", func(t *testing.T) {
+		_, _, err := fetcher.fetch(context.Background(), ts.URL+"/pdf", "")
+		assert.ErrorIs(t, err, ErrUnsupportedContentType)
+		assert.Equal(t, "URL does not serve an HTML page (content type application/pdf). Only text/html and application/xhtml+xml can be analyzed.", fetcher.failureDescription(err))
+	})
+
+	t.Run("Body too large", func(t *testing.T) {
+		_, _, err := fetcher.fetch(context.Background(), ts.URL+"/large", "")
+		assert.ErrorIs(t, err, ErrPageTooLarge)
+
+		_, _, err = fetcher.fetch(context.Background(), ts.URL+"/streamed", "")
+		assert.ErrorIs(t, err, ErrPageTooLarge)
+		assert.Equal(t, "Page exceeds the maximum size of 32 bytes.", fetcher.failureDescription(err))
+	})
+
+	t.Run("Error status", func(t *testing.T) {
+		_, _, err := fetcher.fetch(context.Background(), ts.URL+"/missing", "")
+		assert.ErrorIs(t, err, ErrUnexpectedStatus)
+		assert.Equal(t, "URL cannot be accessed. URL is invalid or unreachable.", fetcher.failureDescription(err))
+	})
+
+	t.Run("Header timeout", func(t *testing.T) {
+		fetcher := newPageFetcher(newTestNetworkGuard(), PageFetchConfig{HeaderTimeout: 20 * time.Millisecond})
+		_, _, err := fetcher.fetch(context.Background(), ts.URL+"/slow-headers", "")
+		assert.ErrorIs(t, err, ErrPageTimeout)
+		assert.Equal(t, "URL cannot be accessed. The page was not fetched within the time limits (connect 10s, response headers 20ms, total 30s).", fetcher.failureDescription(err))
+	})
+
+	t.Run("Total timeout while reading the body", func(t *testing.T) {
+		fetcher := newPageFetcher(newTestNetworkGuard(), PageFetchConfig{Timeout: 50 * time.Millisecond})
+		_, _, err := fetcher.fetch(context.Background(), ts.URL+"/slow-body", "")
+		assert.ErrorIs(t, err, ErrPageTimeout)
+	})
+
+	t.Run("Stopped analysis is not a timeout", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		_, _, err := fetcher.fetch(ctx, ts.URL+"/slow-headers", "")
+		assert.Error(t, err)
+		assert.NotErrorIs(t, err, ErrPageTimeout)
+	})
+}
+
+func TestAnalyzeWebsite_UnsupportedContentType(t *testing.T) {
+	log := logger.Get("info")
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{}`))
+	}))
+	defer ts.Close()
+
+	service := NewWebAnalyzerService(log, repositorymemory.NewWebAnalyzerRepo(log), repositorymemory.NewBatchRepo(log), repositorymemory.NewCrawlRepo(log), NewLinkChecker(log, newTestNetworkGuard(), newTestRobotsCache(log), LinkCheckConfig{}), NewJobQueue(log, 1, 10), newTestWebhookDispatcher(log), newTestNetworkGuard(), PageFetchConfig{}, CrawlConfig{})
+	defer service.Shutdown(context.Background())
+
+	pageURL, _ := url.Parse(ts.URL)
+	id, err := service.AnalyzeWebsite(context.Background(), pageURL, "", contract.AnalysisOptions{})
+	require.NoError(t, err)
+
+	var result *contract.WebAnalyzeResponse
+	require.Eventually(t, func() bool {
+		result, err = service.GetAnalyzeData(context.Background(), id)
+		return err == nil && result.Status == StatusFailed
+	}, 5*time.Second, 20*time.Millisecond)
+
+	assert.Equal(t, "URL does not serve an HTML page (content type application/json). Only text/html and application/xhtml+xml can be analyzed.", result.ErrorDescription)
+}
@@ -0,0 +1,97 @@
+package webanalyzer
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"web-analyzer-api/app/internal/model"
+)
+
+// MaxRedirects is the number of redirects followed before a chain is abandoned.
+const MaxRedirects = 10
+
+const (
+	RedirectErrorLoop    = "redirect_loop"
+	RedirectErrorTooMany = "too_many_redirects"
+)
+
+var (
+	ErrRedirectLoop     = errors.New("redirect loop")
+	ErrTooManyRedirects = errors.New("too many redirects")
+)
+
+// fetchFunc requests a single URL without following redirects.
+type fetchFunc func(target string) (*http.Response, error)
+
+// followRedirects fetches target and, unless skipRedirects is set, every redirect after it. Each response
+// is recorded in the returned chain. The returned response is the last one and must be closed by the caller;
+// it is nil when an error is returned.
+func followRedirects(target string, skipRedirects bool, fetch fetchFunc) (*http.Response, []model.RedirectHop, error) {
+	var chain []model.RedirectHop
+	visited := map[string]bool{}
+	current := target
+
+	for {
+		resp, err := fetch(current)
+		if err != nil {
+			return nil, chain, err
+		}
+		chain = append(chain, model.RedirectHop{URL: current, StatusCode: resp.StatusCode})
+		visited[current] = true
+
+		next, ok := redirectTarget(current, resp)
+		if skipRedirects || !ok {
+			return resp, chain, nil
+		}
+		resp.Body.Close()
+
+		if visited[next] {
+			return nil, chain, ErrRedirectLoop
+		}
+		if len(chain) > MaxRedirects {
+			return nil, chain, ErrTooManyRedirects
+		}
+		current = next
+	}
+}
+
+// redirectTarget returns the absolute URL a redirect response points to.
+func redirectTarget(current string, resp *http.Response) (string, bool) {
+	switch resp.StatusCode {
+	case http.StatusMovedPermanently, http.StatusFound, http.StatusSeeOther, http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+	default:
+		return "", false
+	}
+
+	location := resp.Header.Get("Location")
+	if location == "" {
+		return "", false
+	}
+
+	currentURL, err := url.Parse(current)
+	if err != nil {
+		return "", false
+	}
+	locationURL, err := url.Parse(location)
+	if err != nil {
+		return "", false
+	}
+
+	return currentURL.ResolveReference(locationURL).String(), true
+}
+
+// isRedirectChain reports whether a recorded chain contains at least one redirect.
+func isRedirectChain(chain []model.RedirectHop) bool {
+	return len(chain) > 1 || (len(chain) == 1 && chain[0].StatusCode >= 300 && chain[0].StatusCode < 400)
+}
+
+func redirectErrorCode(err error) string {
+	switch {
+	case errors.Is(err, ErrRedirectLoop):
+		return RedirectErrorLoop
+	case errors.Is(err, ErrTooManyRedirects):
+		return RedirectErrorTooMany
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,144 @@
+package webanalyzer
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+	"web-analyzer-api/app/internal/contract"
+	"web-analyzer-api/app/internal/model"
+	"web-analyzer-api/app/internal/repositorymemory"
+	"web-analyzer-api/app/internal/util/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFollowRedirects(t *testing.T) {
+	responses := map[string]*http.Response{
+		"http://a.test/":      redirectResponse(http.StatusMovedPermanently, "https://a.test/"),
+		"https://a.test/":     redirectResponse(http.StatusFound, "/home"),
+		"https://a.test/home": {StatusCode: http.StatusOK, Header: http.Header{}},
+		"http://loop.test/":   redirectResponse(http.StatusFound, "http://loop.test/"),
+		"http://bare.test/":   {StatusCode: http.StatusFound, Header: http.Header{}},
+	}
+	fetch := func(target string) (*http.Response, error) {
+		resp, ok := responses[target]
+		if !ok {
+			return nil, errors.New("unreachable")
+		}
+		resp.Body = http.NoBody
+		return resp, nil
+	}
+
+	t.Run("Follows the chain", func(t *testing.T) {
+		resp, chain, err := followRedirects("http://a.test/", false, fetch)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, []model.RedirectHop{
+			{URL: "http://a.test/", StatusCode: http.StatusMovedPermanently},
+			{URL: "https://a.test/", StatusCode: http.StatusFound},
+			{URL: "https://a.test/home", StatusCode: http.StatusOK},
+		}, chain)
+		assert.True(t, isRedirectChain(chain))
+	})
+
+	t.Run("Skip redirects", func(t *testing.T) {
+		resp, chain, err := followRedirects("http://a.test/", true, fetch)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusMovedPermanently, resp.StatusCode)
+		assert.Len(t, chain, 1)
+	})
+
+	t.Run("Loop", func(t *testing.T) {
+		resp, chain, err := followRedirects("http://loop.test/", false, fetch)
+		assert.ErrorIs(t, err, ErrRedirectLoop)
+		assert.Nil(t, resp)
+		assert.Len(t, chain, 1)
+		assert.True(t, isRedirectChain(chain))
+		assert.Equal(t, RedirectErrorLoop, redirectErrorCode(err))
+	})
+
+	t.Run("Redirect without location", func(t *testing.T) {
+		resp, chain, err := followRedirects("http://bare.test/", false, fetch)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusFound, resp.StatusCode)
+		assert.Len(t, chain, 1)
+	})
+
+	t.Run("Fetch error keeps the chain so far", func(t *testing.T) {
+		responses["http://gone.test/"] = redirectResponse(http.StatusFound, "http://missing.test/")
+		_, chain, err := followRedirects("http://gone.test/", false, fetch)
+		assert.Error(t, err)
+		assert.Empty(t, redirectErrorCode(err))
+		assert.Len(t, chain, 1)
+	})
+}
+
+func redirectResponse(statusCode int, location string) *http.Response {
+	return &http.Response{StatusCode: statusCode, Header: http.Header{"Location": []string{location}}}
+}
+
+func TestAnalyzeWebsite_Redirects(t *testing.T) {
+	log := logger.Get("info")
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/old":
+			http.Redirect(w, r, "/site/", http.StatusMovedPermanently)
+		case "/site/":
+			w.Write([]byte(`<html><body><a href="page">page</a><a href="moved">moved</a></body></html>`))
+		case "/site/moved":
+			http.Redirect(w, r, "/site/page", http.StatusFound)
+		case "/loop":
+			http.Redirect(w, r, "/loop", http.StatusFound)
+		}
+	}))
+	defer ts.Close()
+
+	runAnalysis := func(t *testing.T, path string, status string) *contract.WebAnalyzeResponse {
+		service := NewWebAnalyzerService(log, repositorymemory.NewWebAnalyzerRepo(log), repositorymemory.NewBatchRepo(log), repositorymemory.NewCrawlRepo(log), NewLinkChecker(log, newTestNetworkGuard(), newTestRobotsCache(log), LinkCheckConfig{}), NewJobQueue(log, 1, 10), newTestWebhookDispatcher(log), newTestNetworkGuard(), PageFetchConfig{}, CrawlConfig{})
+		defer service.Shutdown(context.Background())
+
+		pageURL, _ := url.Parse(ts.URL + path)
+		id, err := service.AnalyzeWebsite(context.Background(), pageURL, "", contract.AnalysisOptions{})
+		require.NoError(t, err)
+
+		var result *contract.WebAnalyzeResponse
+		require.Eventually(t, func() bool {
+			result, err = service.GetAnalyzeData(context.Background(), id)
+			return err == nil && result.Status == status
+		}, 5*time.Second, 20*time.Millisecond)
+		return result
+	}
+
+	t.Run("Redirected page and link", func(t *testing.T) {
+		result := runAnalysis(t, "/old", StatusSuccess)
+
+		assert.Equal(t, ts.URL+"/site/", result.FinalURL)
+		assert.Equal(t, []contract.RedirectHop{
+			{URL: ts.URL + "/old", StatusCode: http.StatusMovedPermanently},
+			{URL: ts.URL + "/site/", StatusCode: http.StatusOK},
+		}, result.Redirects)
+
+		// Relative links resolve against the final URL, so none of them are broken
+		assert.Equal(t, 0, result.Links.Inaccessible)
+		assert.Equal(t, 1, result.Links.Redirected)
+		require.Len(t, result.Links.RedirectedDetails, 1)
+		redirect := result.Links.RedirectedDetails[0]
+		assert.Equal(t, ts.URL+"/site/moved", redirect.URL)
+		assert.Equal(t, ts.URL+"/site/page", redirect.FinalURL)
+		assert.Equal(t, http.StatusOK, redirect.StatusCode)
+		assert.Len(t, redirect.Chain, 2)
+	})
+
+	t.Run("Redirect loop", func(t *testing.T) {
+		result := runAnalysis(t, "/loop", StatusFailed)
+
+		assert.Equal(t, "URL cannot be accessed. URL redirects in a loop.", result.ErrorDescription)
+		assert.Equal(t, []contract.RedirectHop{{URL: ts.URL + "/loop", StatusCode: http.StatusFound}}, result.Redirects)
+	})
+}
@@ -0,0 +1,372 @@
+package webanalyzer
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"web-analyzer-api/app/internal/util/logger"
+)
+
+// ErrDisallowedByRobots is returned for requests that the robots.txt of their host disallows.
+var ErrDisallowedByRobots = errors.New("disallowed by robots.txt")
+
+const (
+	DefaultRobotsUserAgent = "web-analyzer"
+	DefaultRobotsCacheTTL  = time.Hour
+
+	// MaxCrawlDelay caps the Crawl-delay honored for a host so a single link cannot stall an analysis.
+	MaxCrawlDelay = 10 * time.Second
+
+	robotsFetchTimeout = 10 * time.Second
+	// robotsUnreachableTTL caps how long a host whose robots.txt could not be fetched stays fully disallowed.
+	robotsUnreachableTTL = time.Minute
+	// maxRobotsBytes is the part of a robots.txt file that is parsed, as suggested by RFC 9309.
+	maxRobotsBytes = 500 << 10
+)
+
+// disallowAll are the rules of a host whose robots.txt is unreachable. RFC 9309 asks crawlers to assume a
+// complete disallow in that case.
+var disallowAll = &robotsRules{rules: []robotsRule{{pattern: regexp.MustCompile("^/"), length: 1}}}
+
+// RobotsConfig configures how robots.txt files are fetched and matched. Zero values mean the defaults.
+type RobotsConfig struct {
+	// UserAgent is sent when fetching robots.txt and selects the matching group of rules for requests that
+	// do not set their own user agent.
+	UserAgent string
+	// CacheTTL is how long the rules of a host are reused.
+	CacheTTL time.Duration
+}
+
+// RobotsCache fetches robots.txt once per host and user agent and keeps the parsed rules for the configured TTL.
+type RobotsCache struct {
+	log     *logger.Logger
+	config  RobotsConfig
+	client  *http.Client
+	mu      sync.Mutex
+	entries map[string]*robotsEntry
+}
+
+type robotsEntry struct {
+	ready   chan struct{}
+	rules   *robotsRules
+	expires time.Time
+}
+
+func NewRobotsCache(log *logger.Logger, guard *NetworkGuard, config RobotsConfig) *RobotsCache {
+	if config.UserAgent == "" {
+		config.UserAgent = DefaultRobotsUserAgent
+	}
+	if config.CacheTTL <= 0 {
+		config.CacheTTL = DefaultRobotsCacheTTL
+	}
+
+	return &RobotsCache{
+		log:     log,
+		config:  config,
+		client:  &http.Client{Transport: guard.Transport(), Timeout: robotsFetchTimeout},
+		entries: make(map[string]*robotsEntry),
+	}
+}
+
+// Rules returns the robots.txt rules that apply to requests to target sent with userAgent, or with the
+// configured user agent when userAgent is empty. Concurrent callers for the same host and agent share a
+// single fetch. It returns the context error if ctx is done before the rules are known.
+func (c *RobotsCache) Rules(ctx context.Context, target *url.URL, userAgent string) (*robotsRules, error) {
+	if userAgent == "" {
+		userAgent = c.config.UserAgent
+	}
+	origin := target.Scheme + "://" + target.Host
+	key := origin + " " + productToken(userAgent)
+
+	c.mu.Lock()
+	entry, ok := c.entries[key]
+	if !ok || (isClosed(entry.ready) && time.Now().After(entry.expires)) {
+		c.pruneExpired()
+		entry = &robotsEntry{ready: make(chan struct{})}
+		c.entries[key] = entry
+		c.mu.Unlock()
+
+		// The fetch is not bound to ctx so that a cancelled analysis does not cache an empty result
+		rules, reachable := c.fetch(origin, userAgent)
+		ttl := c.config.CacheTTL
+		if !reachable {
+			ttl = min(ttl, robotsUnreachableTTL)
+		}
+		entry.rules = rules
+		entry.expires = time.Now().Add(ttl)
+		close(entry.ready)
+		return entry.rules, nil
+	}
+	c.mu.Unlock()
+
+	select {
+	case <-entry.ready:
+		return entry.rules, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// pruneExpired drops expired entries. It must be called with mu held.
+func (c *RobotsCache) pruneExpired() {
+	now := time.Now()
+	for key, entry := range c.entries {
+		if isClosed(entry.ready) && now.After(entry.expires) {
+			delete(c.entries, key)
+		}
+	}
+}
+
+// fetch downloads and parses the robots.txt of an origin. A client error such as 404 means there are no
+// rules, so all paths are allowed. A server error or a failed request means the host is unreachable, so
+// all paths are disallowed and reachable is false.
+func (c *RobotsCache) fetch(origin string, userAgent string) (rules *robotsRules, reachable bool) {
+	req, err := http.NewRequest(http.MethodGet, origin+"/robots.txt", nil)
+	if err != nil {
+		return disallowAll, false
+	}
+	req.Header.Set("User-Agent", userAgent)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		c.log.Debug("Failed to fetch robots.txt of " + origin + ": " + err.Error())
+		return disallowAll, false
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 500 {
+		c.log.Debug("robots.txt of " + origin + " is unavailable, status code: " + strconv.Itoa(resp.StatusCode))
+		return disallowAll, false
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		c.log.Debug("No robots.txt for " + origin + ", status code: " + strconv.Itoa(resp.StatusCode))
+		return nil, true
+	}
+
+	return parseRobots(io.LimitReader(resp.Body, maxRobotsBytes), productToken(userAgent)), true
+}
+
+func isClosed(ch chan struct{}) bool {
+	select {
+	case <-ch:
+		return true
+	default:
+		return false
+	}
+}
+
+// productToken returns the lower-cased name part of a user agent such as "web-analyzer/1.0".
+func productToken(userAgent string) string {
+	token, _, _ := strings.Cut(userAgent, "/")
+	return strings.ToLower(strings.TrimSpace(token))
+}
+
+type robotsRule struct {
+	pattern *regexp.Regexp
+	length  int
+	allow   bool
+}
+
+// robotsRules are the rules of the group matching our user agent. A nil value allows everything.
+// Sitemaps are listed outside of groups and apply to every user agent.
+type robotsRules struct {
+	rules      []robotsRule
+	crawlDelay time.Duration
+	sitemaps   []string
+}
+
+// Allowed reports whether target may be requested. The longest matching rule wins and allow wins a tie.
+func (r *robotsRules) Allowed(target *url.URL) bool {
+	if r == nil {
+		return true
+	}
+
+	path := target.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	if path == "/robots.txt" {
+		return true
+	}
+	if target.RawQuery != "" {
+		path += "?" + target.RawQuery
+	}
+
+	allowed, matched := true, -1
+	for _, rule := range r.rules {
+		if rule.length < matched || !rule.pattern.MatchString(path) {
+			continue
+		}
+		if rule.length > matched || rule.allow {
+			allowed = rule.allow
+		}
+		matched = rule.length
+	}
+	return allowed
+}
+
+// CrawlDelay returns the delay to keep between requests to the host, capped at MaxCrawlDelay.
+func (r *robotsRules) CrawlDelay() time.Duration {
+	if r == nil {
+		return 0
+	}
+	return min(r.crawlDelay, MaxCrawlDelay)
+}
+
+// Sitemaps returns the sitemap URLs listed in the file.
+func (r *robotsRules) Sitemaps() []string {
+	if r == nil {
+		return nil
+	}
+	return r.sitemaps
+}
+
+type robotsGroup struct {
+	agents     []string
+	rules      []robotsRule
+	crawlDelay time.Duration
+}
+
+// parseRobots parses a robots.txt file and returns the rules of the groups for agent, falling back to
+// the groups for "*".
+func parseRobots(r io.Reader, agent string) *robotsRules {
+	var (
+		groups   []*robotsGroup
+		current  *robotsGroup
+		sitemaps []string
+		// sawRule is set once the current group has a rule line, even an empty one that adds no rule
+		sawRule bool
+	)
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+
+		switch key {
+		case "user-agent":
+			// Consecutive user-agent lines share one group
+			if current == nil || sawRule {
+				current = &robotsGroup{}
+				groups = append(groups, current)
+				sawRule = false
+			}
+			current.agents = append(current.agents, strings.ToLower(value))
+		case "allow", "disallow":
+			if current == nil {
+				continue
+			}
+			sawRule = true
+			if value == "" {
+				continue
+			}
+			current.rules = append(current.rules, robotsRule{
+				pattern: compileRobotsPattern(value),
+				length:  len(value),
+				allow:   key == "allow",
+			})
+		case "crawl-delay":
+			if current == nil {
+				continue
+			}
+			sawRule = true
+			if seconds, err := strconv.ParseFloat(value, 64); err == nil && seconds > 0 {
+				current.crawlDelay = time.Duration(seconds * float64(time.Second))
+			}
+		case "sitemap":
+			if value != "" {
+				sitemaps = append(sitemaps, value)
+			}
+		}
+	}
+
+	var matched, wildcard robotsRules
+	var hasMatch bool
+	for _, group := range groups {
+		for _, groupAgent := range group.agents {
+			switch groupAgent {
+			case agent:
+				hasMatch = true
+				matched.rules = append(matched.rules, group.rules...)
+				matched.crawlDelay = max(matched.crawlDelay, group.crawlDelay)
+			case "*":
+				wildcard.rules = append(wildcard.rules, group.rules...)
+				wildcard.crawlDelay = max(wildcard.crawlDelay, group.crawlDelay)
+			default:
+				continue
+			}
+			break
+		}
+	}
+
+	if hasMatch {
+		matched.sitemaps = sitemaps
+		return &matched
+	}
+	wildcard.sitemaps = sitemaps
+	return &wildcard
+}
+
+// compileRobotsPattern turns a path pattern with "*" wildcards and an optional "$" end anchor into a
+// regular expression matching from the start of the path.
+func compileRobotsPattern(pattern string) *regexp.Regexp {
+	anchored := strings.HasSuffix(pattern, "$")
+	pattern = strings.TrimSuffix(pattern, "$")
+
+	parts := strings.Split(pattern, "*")
+	for i, part := range parts {
+		parts[i] = regexp.QuoteMeta(part)
+	}
+
+	expr := "^" + strings.Join(parts, ".*")
+	if anchored {
+		expr += "$"
+	}
+	return regexp.MustCompile(expr)
+}
+
+// hostPacer spaces out requests to the same host by the host's crawl delay, across all link checks.
+type hostPacer struct {
+	mu   sync.Mutex
+	next map[string]time.Time
+}
+
+func newHostPacer() *hostPacer {
+	return &hostPacer{next: make(map[string]time.Time)}
+}
+
+// wait blocks until a request to host may be sent. It returns the context error if ctx is done first.
+func (p *hostPacer) wait(ctx context.Context, host string, delay time.Duration) error {
+	if delay <= 0 {
+		return nil
+	}
+
+	p.mu.Lock()
+	now := time.Now()
+	at := p.next[host]
+	if at.Before(now) {
+		at = now
+	}
+	p.next[host] = at.Add(delay)
+	for knownHost, next := range p.next {
+		if next.Before(now) {
+			delete(p.next, knownHost)
+		}
+	}
+	p.mu.Unlock()
+
+	return sleepContext(ctx, time.Until(at))
+}
@@ -0,0 +1,296 @@
+package webanalyzer
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+	"web-analyzer-api/app/internal/contract"
+	"web-analyzer-api/app/internal/model"
+	"web-analyzer-api/app/internal/repositorymemory"
+	"web-analyzer-api/app/internal/util/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testRobots = `# Example robots.txt
+User-agent: *
+Disallow: /private/
+Allow: /private/public-*.html$
+Disallow: /*.pdf$
+Crawl-delay: 2
+Sitemap: https://example.test/sitemap_index.xml
+
+User-agent: other-bot
+User-agent: Web-Analyzer
+Disallow: /admin
+Allow: /admin/status
+Disallow:
+Crawl-delay: 60
+
+sitemap: https://cdn.example.test/sitemap-news.xml.gz # News
+`
+
+func TestParseRobots(t *testing.T) {
+	tests := []struct {
+		name     string
+		agent    string
+		path     string
+		expected bool
+	}{
+		{name: "No matching rule", agent: "some-bot", path: "/about", expected: true},
+		{name: "Disallowed prefix", agent: "some-bot", path: "/private/data", expected: false},
+		{name: "Longer allow wins", agent: "some-bot", path: "/private/public-1.html", expected: true},
+		{name: "End anchor", agent: "some-bot", path: "/private/public-1.html?x=1", expected: false},
+		{name: "Wildcard", agent: "some-bot", path: "/files/report.pdf", expected: false},
+		{name: "Wildcard not at the end", agent: "some-bot", path: "/files/report.pdf.html", expected: true},
+		{name: "robots.txt is always allowed", agent: "some-bot", path: "/robots.txt", expected: true},
+		{name: "Named group replaces the wildcard group", agent: "web-analyzer", path: "/private/data", expected: true},
+		{name: "Named group rule", agent: "web-analyzer", path: "/admin/users", expected: false},
+		{name: "Named group allow", agent: "web-analyzer", path: "/admin/status", expected: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules := parseRobots(strings.NewReader(testRobots), tt.agent)
+			target, err := url.Parse("http://example.test" + tt.path)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, rules.Allowed(target))
+		})
+	}
+
+	t.Run("Crawl delay", func(t *testing.T) {
+		assert.Equal(t, 2*time.Second, parseRobots(strings.NewReader(testRobots), "some-bot").CrawlDelay())
+		assert.Equal(t, MaxCrawlDelay, parseRobots(strings.NewReader(testRobots), "web-analyzer").CrawlDelay())
+	})
+
+	t.Run("Sitemaps", func(t *testing.T) {
+		expected := []string{"https://example.test/sitemap_index.xml", "https://cdn.example.test/sitemap-news.xml.gz"}
+		assert.Equal(t, expected, parseRobots(strings.NewReader(testRobots), "some-bot").Sitemaps())
+		assert.Equal(t, expected, parseRobots(strings.NewReader(testRobots), "web-analyzer").Sitemaps())
+	})
+
+	t.Run("Empty disallow ends its group", func(t *testing.T) {
+		robots := "User-agent: *\nDisallow:\n\nUser-agent: BadBot\nDisallow: /\n"
+		target, _ := url.Parse("http://example.test/about")
+		assert.True(t, parseRobots(strings.NewReader(robots), "web-analyzer").Allowed(target))
+		assert.False(t, parseRobots(strings.NewReader(robots), "badbot").Allowed(target))
+	})
+
+	t.Run("No rules", func(t *testing.T) {
+		var rules *robotsRules
+		target, _ := url.Parse("http://example.test/private/data")
+		assert.True(t, rules.Allowed(target))
+		assert.Zero(t, rules.CrawlDelay())
+		assert.Nil(t, rules.Sitemaps())
+	})
+}
+
+func TestProductToken(t *testing.T) {
+	assert.Equal(t, "web-analyzer", productToken("Web-Analyzer/1.0 (+https://example.test)"))
+	assert.Equal(t, "audit-bot", productToken(" audit-bot "))
+}
+
+func TestRobotsCache_Rules(t *testing.T) {
+	rulesFor := func(cache *RobotsCache, target *url.URL, userAgent string) *robotsRules {
+		rules, err := cache.Rules(context.Background(), target, userAgent)
+		require.NoError(t, err)
+		return rules
+	}
+
+	var fetches atomic.Int32
+	var userAgent atomic.Value
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			fetches.Add(1)
+			userAgent.Store(r.UserAgent())
+			w.Write([]byte("User-agent: acme-checker\nDisallow: /private\n"))
+		}
+	}))
+	defer ts.Close()
+
+	log := logger.Get("info")
+	cache := NewRobotsCache(log, newTestNetworkGuard(), RobotsConfig{UserAgent: "acme-checker/2.0"})
+	private, _ := url.Parse(ts.URL + "/private/page")
+	public, _ := url.Parse(ts.URL + "/public")
+
+	assert.False(t, rulesFor(cache, private, "").Allowed(private))
+	assert.True(t, rulesFor(cache, public, "").Allowed(public))
+	assert.Equal(t, int32(1), fetches.Load())
+	assert.Equal(t, "acme-checker/2.0", userAgent.Load())
+
+	t.Run("Request user agent selects the group", func(t *testing.T) {
+		fetches.Store(0)
+
+		assert.True(t, rulesFor(cache, private, "other-bot/1.0").Allowed(private))
+		assert.Equal(t, "other-bot/1.0", userAgent.Load())
+		assert.False(t, rulesFor(cache, private, "Acme-Checker/3.1").Allowed(private))
+		assert.True(t, rulesFor(cache, private, "other-bot/2.0").Allowed(private))
+		assert.Equal(t, int32(1), fetches.Load())
+	})
+
+	t.Run("Expired rules are fetched again", func(t *testing.T) {
+		cache := NewRobotsCache(log, newTestNetworkGuard(), RobotsConfig{CacheTTL: time.Millisecond})
+		fetches.Store(0)
+
+		rulesFor(cache, public, "")
+		time.Sleep(5 * time.Millisecond)
+		rulesFor(cache, public, "")
+		assert.Equal(t, int32(2), fetches.Load())
+	})
+
+	t.Run("Missing robots.txt allows everything", func(t *testing.T) {
+		ts := httptest.NewServer(http.NotFoundHandler())
+		defer ts.Close()
+
+		target, _ := url.Parse(ts.URL + "/private")
+		assert.True(t, rulesFor(cache, target, "").Allowed(target))
+	})
+
+	t.Run("Unavailable robots.txt disallows everything", func(t *testing.T) {
+		var fetches atomic.Int32
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fetches.Add(1)
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer ts.Close()
+
+		target, _ := url.Parse(ts.URL + "/public")
+		rules := rulesFor(cache, target, "")
+		assert.False(t, rules.Allowed(target))
+		robotsURL, _ := url.Parse(ts.URL + "/robots.txt")
+		assert.True(t, rules.Allowed(robotsURL))
+
+		// The outcome is cached for a shorter time than the configured TTL
+		rulesFor(cache, target, "")
+		assert.Equal(t, int32(1), fetches.Load())
+		entry := cache.entries[ts.URL+" acme-checker"]
+		assert.WithinDuration(t, time.Now().Add(robotsUnreachableTTL), entry.expires, time.Second)
+	})
+
+	t.Run("Unreachable host disallows everything", func(t *testing.T) {
+		ts := httptest.NewServer(http.NotFoundHandler())
+		ts.Close()
+
+		target, _ := url.Parse(ts.URL + "/public")
+		assert.False(t, rulesFor(cache, target, "").Allowed(target))
+	})
+
+	t.Run("Context done while another caller fetches", func(t *testing.T) {
+		release := make(chan struct{})
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			<-release
+			w.Write([]byte("User-agent: *\nDisallow: /\n"))
+		}))
+		defer ts.Close()
+
+		target, _ := url.Parse(ts.URL + "/private")
+		fetched := make(chan *robotsRules)
+		go func() {
+			rules, _ := cache.Rules(context.Background(), target, "")
+			fetched <- rules
+		}()
+		require.Eventually(t, func() bool {
+			cache.mu.Lock()
+			defer cache.mu.Unlock()
+			_, ok := cache.entries[ts.URL+" acme-checker"]
+			return ok
+		}, time.Second, time.Millisecond)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		rules, err := cache.Rules(ctx, target, "")
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Nil(t, rules)
+
+		close(release)
+		assert.False(t, (<-fetched).Allowed(target))
+	})
+}
+
+func TestHostPacer_Wait(t *testing.T) {
+	pacer := newHostPacer()
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		require.NoError(t, pacer.wait(context.Background(), "example.test", 30*time.Millisecond))
+	}
+	assert.GreaterOrEqual(t, time.Since(start), 60*time.Millisecond)
+
+	// Other hosts are not held back
+	start = time.Now()
+	require.NoError(t, pacer.wait(context.Background(), "other.test", 30*time.Millisecond))
+	assert.Less(t, time.Since(start), 30*time.Millisecond)
+
+	t.Run("Cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		pacer.wait(context.Background(), "busy.test", time.Minute)
+		assert.ErrorIs(t, pacer.wait(ctx, "busy.test", time.Minute), context.Canceled)
+	})
+}
+
+func TestCheckLink_Robots(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			w.Write([]byte("User-agent: *\nDisallow: /private\n"))
+		}
+	}))
+	defer ts.Close()
+
+	baseURL, lc := setupBaseURL()
+	client := newLinkCheckClient(model.AnalysisOptions{}, newTestNetworkGuard().Transport())
+
+	result := lc.CheckLink(context.Background(), client, ts.URL+"/private", baseURL, model.AnalysisOptions{RespectRobots: true})
+	require.NotNil(t, result)
+	assert.True(t, result.SkippedRobots)
+	assert.Zero(t, result.StatusCode)
+
+	result = lc.CheckLink(context.Background(), client, ts.URL+"/private", baseURL, model.AnalysisOptions{})
+	require.NotNil(t, result)
+	assert.False(t, result.SkippedRobots)
+	assert.True(t, result.IsAccessible)
+}
+
+func TestAnalyzeWebsite_Robots(t *testing.T) {
+	log := logger.Get("info")
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/":
+			w.Write([]byte(`<html><body><a href="/public">Public</a><a href="/private/a">A</a><a href="/private/b">B</a></body></html>`))
+		case "/robots.txt":
+			w.Write([]byte("User-agent: *\nDisallow: /private/\n"))
+		case "/public":
+			w.WriteHeader(http.StatusOK)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	service := NewWebAnalyzerService(log, repositorymemory.NewWebAnalyzerRepo(log), repositorymemory.NewBatchRepo(log), repositorymemory.NewCrawlRepo(log), NewLinkChecker(log, newTestNetworkGuard(), newTestRobotsCache(log), LinkCheckConfig{}), NewJobQueue(log, 1, 10), newTestWebhookDispatcher(log), newTestNetworkGuard(), PageFetchConfig{}, CrawlConfig{})
+	defer service.Shutdown(context.Background())
+
+	pageURL, _ := url.Parse(ts.URL + "/")
+	respectRobots := true
+	id, err := service.AnalyzeWebsite(context.Background(), pageURL, "", contract.AnalysisOptions{RespectRobots: &respectRobots})
+	require.NoError(t, err)
+
+	var result *contract.WebAnalyzeResponse
+	require.Eventually(t, func() bool {
+		result, err = service.GetAnalyzeData(context.Background(), id)
+		return err == nil && result.Status == StatusSuccess
+	}, 5*time.Second, 20*time.Millisecond)
+
+	assert.Equal(t, 0, result.Links.Inaccessible)
+	assert.Equal(t, 2, result.Links.SkippedRobots)
+	assert.ElementsMatch(t, []string{ts.URL + "/private/a", ts.URL + "/private/b"}, result.Links.SkippedRobotsDetails)
+	assert.True(t, *result.Options.RespectRobots)
+}
@@ -0,0 +1,108 @@
+package webanalyzer
+
+import (
+	"fmt"
+	"net/url"
+	"unicode/utf8"
+	"web-analyzer-api/app/internal/model"
+	htmlhelper "web-analyzer-api/app/internal/util/html"
+
+	"golang.org/x/net/html"
+)
+
+// SEO issue codes.
+const (
+	SEOIssueTitleMissing       = "title_missing"
+	SEOIssueTitleDuplicate     = "title_duplicate"
+	SEOIssueTitleLength        = "title_length"
+	SEOIssueDescriptionMissing = "description_missing"
+	SEOIssueDescriptionLength  = "description_length"
+	SEOIssueMultipleH1         = "multiple_h1"
+	SEOIssueCanonicalElsewhere = "canonical_elsewhere"
+	SEOIssueNoindex            = "noindex"
+)
+
+// Lengths, in characters, outside of which search engines tend to ignore or truncate titles and descriptions.
+const (
+	minTitleLength       = 10
+	maxTitleLength       = 60
+	minDescriptionLength = 50
+	maxDescriptionLength = 160
+)
+
+// analyzeSEO extracts the SEO metadata of the page at pageURL and checks it. headings are the heading counts of
+// the page.
+func analyzeSEO(doc *html.Node, pageURL *url.URL, headings map[string]int) model.SEOAnalysis {
+	metadata := htmlhelper.GetSEOMetadata(doc)
+	baseURL := htmlhelper.GetBaseURL(doc, pageURL)
+
+	seo := model.SEOAnalysis{
+		TitleCount:  len(metadata.Titles),
+		Description: metadata.Description,
+		Robots:      metadata.Robots,
+		Canonical:   resolveURL(metadata.Canonical, baseURL),
+		Hreflang:    make([]model.HreflangAlternate, 0, len(metadata.Alternates)),
+		OpenGraph:   metadata.OpenGraph,
+		TwitterCard: metadata.TwitterCard,
+		Viewport:    metadata.Viewport,
+		Lang:        metadata.Lang,
+		Issues:      []model.SEOIssue{},
+	}
+	for _, alternate := range metadata.Alternates {
+		seo.Hreflang = append(seo.Hreflang, model.HreflangAlternate{Lang: alternate.Hreflang, URL: resolveURL(alternate.URL, baseURL)})
+	}
+
+	addIssue := func(code string, format string, args ...any) {
+		seo.Issues = append(seo.Issues, model.SEOIssue{Code: code, Message: fmt.Sprintf(format, args...)})
+	}
+
+	var title string
+	if len(metadata.Titles) > 0 {
+		title = metadata.Titles[0]
+	}
+	switch length := utf8.RuneCountInString(title); {
+	case title == "":
+		addIssue(SEOIssueTitleMissing, "The page has no title")
+	case length < minTitleLength || length > maxTitleLength:
+		addIssue(SEOIssueTitleLength, "The title is %d characters long, outside of the recommended %d to %d", length, minTitleLength, maxTitleLength)
+	}
+	if len(metadata.Titles) > 1 {
+		addIssue(SEOIssueTitleDuplicate, "The page has %d title elements", len(metadata.Titles))
+	}
+
+	switch length := utf8.RuneCountInString(metadata.Description); {
+	case metadata.Description == "":
+		addIssue(SEOIssueDescriptionMissing, "The page has no meta description")
+	case length < minDescriptionLength || length > maxDescriptionLength:
+		addIssue(SEOIssueDescriptionLength, "The meta description is %d characters long, outside of the recommended %d to %d", length, minDescriptionLength, maxDescriptionLength)
+	}
+
+	if headings["h1"] > 1 {
+		addIssue(SEOIssueMultipleH1, "The page has %d h1 headings", headings["h1"])
+	}
+
+	if seo.Canonical != "" {
+		page, _ := htmlhelper.NormalizeLink(pageURL.String(), pageURL)
+		if canonical, ok := htmlhelper.NormalizeLink(seo.Canonical, pageURL); ok && canonical != page {
+			addIssue(SEOIssueCanonicalElsewhere, "The canonical URL points to %s", seo.Canonical)
+		}
+	}
+
+	if htmlhelper.IsNoindex(metadata.Robots) {
+		addIssue(SEOIssueNoindex, "Meta robots keeps the page out of search results")
+	}
+
+	return seo
+}
+
+// resolveURL returns link resolved against baseURL, or link unchanged when it cannot be parsed.
+func resolveURL(link string, baseURL *url.URL) string {
+	if link == "" {
+		return ""
+	}
+	resolved, err := baseURL.Parse(link)
+	if err != nil {
+		return link
+	}
+	return resolved.String()
+}
@@ -0,0 +1,133 @@
+package webanalyzer
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+	"web-analyzer-api/app/internal/contract"
+	"web-analyzer-api/app/internal/repositorymemory"
+	htmlhelper "web-analyzer-api/app/internal/util/html"
+	"web-analyzer-api/app/internal/util/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/html"
+)
+
+func seoIssueCodes(t *testing.T, page string, pageURL string) []string {
+	doc, err := html.Parse(strings.NewReader(page))
+	require.NoError(t, err)
+	u, _ := url.Parse(pageURL)
+
+	codes := []string{}
+	for _, issue := range analyzeSEO(doc, u, htmlhelper.GetHeadingsCount(doc)).Issues {
+		assert.NotEmpty(t, issue.Message)
+		codes = append(codes, issue.Code)
+	}
+	return codes
+}
+
+func TestAnalyzeSEO(t *testing.T) {
+	description := strings.Repeat("Describes the page. ", 4)
+
+	t.Run("No issues", func(t *testing.T) {
+		codes := seoIssueCodes(t, `<html><head><title>A well sized page title</title>
+			<meta name="description" content="`+description+`">
+			<link rel="canonical" href="/Page?a=1#top"></head><body><h1>One</h1></body></html>`, "http://example.test:80/Page?a=1")
+		assert.Empty(t, codes)
+	})
+
+	t.Run("Missing title and description", func(t *testing.T) {
+		codes := seoIssueCodes(t, `<html><head><title> </title></head><body></body></html>`, "http://example.test/")
+		assert.Equal(t, []string{SEOIssueTitleMissing, SEOIssueDescriptionMissing}, codes)
+	})
+
+	t.Run("Lengths out of range", func(t *testing.T) {
+		codes := seoIssueCodes(t, `<title>Short</title><meta name="description" content="Too short">`, "http://example.test/")
+		assert.Equal(t, []string{SEOIssueTitleLength, SEOIssueDescriptionLength}, codes)
+
+		codes = seoIssueCodes(t, `<title>`+strings.Repeat("é", 61)+`</title><meta name="description" content="`+strings.Repeat("x", 161)+`">`, "http://example.test/")
+		assert.Equal(t, []string{SEOIssueTitleLength, SEOIssueDescriptionLength}, codes)
+
+		// Lengths are counted in characters, not bytes
+		codes = seoIssueCodes(t, `<title>`+strings.Repeat("é", 60)+`</title><meta name="description" content="`+description+`">`, "http://example.test/")
+		assert.Empty(t, codes)
+	})
+
+	t.Run("Duplicate title, multiple h1, canonical elsewhere and noindex", func(t *testing.T) {
+		codes := seoIssueCodes(t, `<html><head><title>A well sized page title</title><title>Another title</title>
+			<meta name="description" content="`+description+`"><meta name="robots" content="noindex, follow">
+			<base href="http://example.test/docs/"><link rel="canonical" href="other"></head>
+			<body><h1>One</h1><h1>Two</h1></body></html>`, "http://example.test/docs/page")
+		assert.Equal(t, []string{SEOIssueTitleDuplicate, SEOIssueMultipleH1, SEOIssueCanonicalElsewhere, SEOIssueNoindex}, codes)
+	})
+
+	t.Run("URLs are resolved against the page", func(t *testing.T) {
+		doc, err := html.Parse(strings.NewReader(`<html lang="fr"><head><base href="/fr/">
+			<link rel="canonical" href="page"><link rel="alternate" hreflang="en" href="//example.test/en/page">
+			<meta property="og:url" content="/fr/page"></head></html>`))
+		require.NoError(t, err)
+		u, _ := url.Parse("https://example.test/fr/page?utm=x")
+
+		seo := analyzeSEO(doc, u, nil)
+		assert.Equal(t, "https://example.test/fr/page", seo.Canonical)
+		assert.Equal(t, "https://example.test/en/page", seo.Hreflang[0].URL)
+		assert.Equal(t, "/fr/page", seo.OpenGraph["url"])
+		assert.Equal(t, "fr", seo.Lang)
+		assert.Equal(t, SEOIssueCanonicalElsewhere, seo.Issues[len(seo.Issues)-1].Code)
+	})
+}
+
+func TestAnalyzeWebsite_SEO(t *testing.T) {
+	log := logger.Get("info")
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/old":
+			http.Redirect(w, r, "/", http.StatusMovedPermanently)
+		case "/":
+			fmt.Fprint(w, `<!DOCTYPE html><html lang="en"><head><title>Home</title>
+				<meta name="robots" content="noindex"><link rel="canonical" href="/">
+				<link rel="alternate" hreflang="de" href="/de/">
+				<meta property="og:title" content="Home"><meta name="twitter:card" content="summary">
+			</head><body><h1>Home</h1></body></html>`)
+		}
+	}))
+	defer ts.Close()
+
+	service := NewWebAnalyzerService(log, repositorymemory.NewWebAnalyzerRepo(log), repositorymemory.NewBatchRepo(log), repositorymemory.NewCrawlRepo(log), NewLinkChecker(log, newTestNetworkGuard(), newTestRobotsCache(log), LinkCheckConfig{}), NewJobQueue(log, 1, 10), newTestWebhookDispatcher(log), newTestNetworkGuard(), PageFetchConfig{}, CrawlConfig{})
+	defer service.Shutdown(context.Background())
+
+	pageURL, _ := url.Parse(ts.URL + "/old")
+	disabled := false
+	id, err := service.AnalyzeWebsite(context.Background(), pageURL, "", contract.AnalysisOptions{CheckLinks: &disabled})
+	require.NoError(t, err)
+
+	var result *contract.WebAnalyzeResponse
+	require.Eventually(t, func() bool {
+		result, err = service.GetAnalyzeData(context.Background(), id)
+		return err == nil && result.Status == StatusSuccess
+	}, 5*time.Second, 20*time.Millisecond)
+
+	// The canonical URL is compared with where the page was finally served from
+	require.NotNil(t, result.SEO)
+	assert.Equal(t, &contract.SEOAnalysis{
+		TitleCount:  1,
+		Robots:      "noindex",
+		Canonical:   ts.URL + "/",
+		Hreflang:    []contract.HreflangAlternate{{Lang: "de", URL: ts.URL + "/de/"}},
+		OpenGraph:   map[string]string{"title": "Home"},
+		TwitterCard: map[string]string{"card": "summary"},
+		Lang:        "en",
+		Issues: []contract.SEOIssue{
+			{Code: SEOIssueTitleLength, Message: "The title is 4 characters long, outside of the recommended 10 to 60"},
+			{Code: SEOIssueDescriptionMissing, Message: "The page has no meta description"},
+			{Code: SEOIssueNoindex, Message: "Meta robots keeps the page out of search results"},
+		},
+	}, result.SEO)
+}
@@ -0,0 +1,142 @@
+package webanalyzer
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+	htmlhelper "web-analyzer-api/app/internal/util/html"
+)
+
+const (
+	// maxSitemapBytes is the largest sitemap that is read, after decompression, as set by the sitemaps protocol.
+	maxSitemapBytes = 50 << 20
+	// maxSitemapFiles bounds the sitemaps read for a site, index files included.
+	maxSitemapFiles = 100
+	// maxSitemapPages bounds the pages collected from the sitemaps of a site.
+	maxSitemapPages = 50000
+)
+
+var ErrInvalidSitemap = errors.New("invalid sitemap")
+
+// sitemapDocument is either a urlset listing pages or a sitemapindex listing other sitemaps.
+type sitemapDocument struct {
+	XMLName  xml.Name
+	URLs     []sitemapLocation `xml:"url"`
+	Sitemaps []sitemapLocation `xml:"sitemap"`
+}
+
+type sitemapLocation struct {
+	Loc string `xml:"loc"`
+}
+
+// sitemapListing is what the sitemaps of a site list. Sitemaps are the files that were read and Pages the
+// normalized internal pages, in the order they are listed.
+type sitemapListing struct {
+	Sitemaps []string
+	Pages    []string
+}
+
+// parseSitemap returns the page and sitemap locations of a sitemap file. Gzip-compressed files are recognized
+// by their content rather than their name, as servers label them inconsistently.
+func parseSitemap(body []byte) ([]string, []string, error) {
+	if len(body) >= 2 && body[0] == 0x1f && body[1] == 0x8b {
+		reader, err := gzip.NewReader(bytes.NewReader(body))
+		if err != nil {
+			return nil, nil, fmt.Errorf("%w: %w", ErrInvalidSitemap, err)
+		}
+		if body, err = io.ReadAll(io.LimitReader(reader, maxSitemapBytes+1)); err != nil {
+			return nil, nil, fmt.Errorf("%w: %w", ErrInvalidSitemap, err)
+		}
+		if len(body) > maxSitemapBytes {
+			return nil, nil, ErrPageTooLarge
+		}
+	}
+
+	var doc sitemapDocument
+	if err := xml.Unmarshal(body, &doc); err != nil {
+		return nil, nil, fmt.Errorf("%w: %w", ErrInvalidSitemap, err)
+	}
+	if doc.XMLName.Local != "urlset" && doc.XMLName.Local != "sitemapindex" {
+		return nil, nil, fmt.Errorf("%w: unexpected root element %s", ErrInvalidSitemap, doc.XMLName.Local)
+	}
+
+	return sitemapLocations(doc.URLs), sitemapLocations(doc.Sitemaps), nil
+}
+
+func sitemapLocations(locations []sitemapLocation) []string {
+	var locs []string
+	for _, location := range locations {
+		if loc := strings.TrimSpace(location.Loc); loc != "" {
+			locs = append(locs, loc)
+		}
+	}
+	return locs
+}
+
+// collectSitemap reads the sitemaps of the site of start: the ones robots.txt lists and /sitemap.xml, and the
+// sitemaps their index files point to. Sitemaps that cannot be read are skipped. Only pages on the site of
+// start are kept.
+func (s *webAnalyzerService) collectSitemap(ctx context.Context, start *url.URL, userAgent string) sitemapListing {
+	origin := &url.URL{Scheme: start.Scheme, Host: start.Host}
+	listing := sitemapListing{Sitemaps: []string{}, Pages: []string{}}
+
+	var queue []string
+	queued := map[string]bool{}
+	enqueue := func(loc string) {
+		sitemapURL, err := origin.Parse(loc)
+		if err != nil || (sitemapURL.Scheme != "http" && sitemapURL.Scheme != "https") {
+			return
+		}
+		sitemapURL.Fragment = ""
+		if target := sitemapURL.String(); !queued[target] {
+			queued[target] = true
+			queue = append(queue, target)
+		}
+	}
+
+	robots, err := s.pages.fetchFile(ctx, origin.String()+"/robots.txt", userAgent, maxRobotsBytes)
+	if err == nil {
+		for _, loc := range parseRobots(bytes.NewReader(robots), "").Sitemaps() {
+			enqueue(loc)
+		}
+	}
+	enqueue("/sitemap.xml")
+
+	listed := map[string]bool{}
+	for read := 0; len(queue) > 0 && read < maxSitemapFiles && ctx.Err() == nil; read++ {
+		target := queue[0]
+		queue = queue[1:]
+
+		body, err := s.pages.fetchFile(ctx, target, userAgent, maxSitemapBytes)
+		if err != nil {
+			s.log.Debug("Skipping sitemap " + target + ": " + err.Error())
+			continue
+		}
+		pages, sitemaps, err := parseSitemap(body)
+		if err != nil {
+			s.log.Debug("Skipping sitemap " + target + ": " + err.Error())
+			continue
+		}
+		listing.Sitemaps = append(listing.Sitemaps, target)
+
+		for _, loc := range sitemaps {
+			enqueue(loc)
+		}
+		for _, loc := range pages {
+			page, ok := htmlhelper.NormalizeLink(loc, start)
+			if !ok || listed[page] || !htmlhelper.IsInternalLink(page, start) || len(listing.Pages) >= maxSitemapPages {
+				continue
+			}
+			listed[page] = true
+			listing.Pages = append(listing.Pages, page)
+		}
+	}
+
+	return listing
+}
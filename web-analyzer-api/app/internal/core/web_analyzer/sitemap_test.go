@@ -0,0 +1,170 @@
+package webanalyzer
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+	"web-analyzer-api/app/internal/contract"
+	"web-analyzer-api/app/internal/core/apperror"
+	"web-analyzer-api/app/internal/repositorymemory"
+	"web-analyzer-api/app/internal/util/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func gzipBytes(t *testing.T, content string) []byte {
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	_, err := writer.Write([]byte(content))
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+	return buf.Bytes()
+}
+
+func TestParseSitemap(t *testing.T) {
+	urlset := `<?xml version="1.0" encoding="UTF-8"?>
+		<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+			<url><loc> https://a.test/ </loc><lastmod>2026-01-01</lastmod></url>
+			<url><loc>https://a.test/about?lang=en&amp;x=1</loc></url>
+			<url><loc></loc></url>
+		</urlset>`
+
+	t.Run("URL set", func(t *testing.T) {
+		pages, sitemaps, err := parseSitemap([]byte(urlset))
+		require.NoError(t, err)
+		assert.Equal(t, []string{"https://a.test/", "https://a.test/about?lang=en&x=1"}, pages)
+		assert.Empty(t, sitemaps)
+	})
+
+	t.Run("Sitemap index", func(t *testing.T) {
+		pages, sitemaps, err := parseSitemap([]byte(`<sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+			<sitemap><loc>https://a.test/sitemap-1.xml</loc></sitemap>
+			<sitemap><loc>https://a.test/sitemap-2.xml.gz</loc></sitemap>
+		</sitemapindex>`))
+		require.NoError(t, err)
+		assert.Empty(t, pages)
+		assert.Equal(t, []string{"https://a.test/sitemap-1.xml", "https://a.test/sitemap-2.xml.gz"}, sitemaps)
+	})
+
+	t.Run("Gzip compressed", func(t *testing.T) {
+		pages, _, err := parseSitemap(gzipBytes(t, urlset))
+		require.NoError(t, err)
+		assert.Len(t, pages, 2)
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		for _, body := range []string{"<html><body></body></html>", "not xml", "\x1f\x8bbroken"} {
+			_, _, err := parseSitemap([]byte(body))
+			assert.ErrorIs(t, err, ErrInvalidSitemap, body)
+		}
+	})
+}
+
+func TestCrawlSite_Sitemap(t *testing.T) {
+	log := logger.Get("info")
+
+	var ts *httptest.Server
+	ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/robots.txt":
+			fmt.Fprintf(w, "User-agent: *\nDisallow:\nSitemap: %s/sitemap_index.xml\n", ts.URL)
+		case "/sitemap_index.xml":
+			fmt.Fprint(w, `<sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+				<sitemap><loc>/sitemap-pages.xml.gz</loc></sitemap><sitemap><loc>/sitemap-missing.xml</loc></sitemap>
+			</sitemapindex>`)
+		case "/sitemap.xml":
+			fmt.Fprintf(w, `<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9"><url><loc>%s/</loc></url></urlset>`, ts.URL)
+		case "/sitemap-pages.xml.gz":
+			w.Header().Set("Content-Type", "application/gzip")
+			w.Write(gzipBytes(t, fmt.Sprintf(`<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+				<url><loc>%[1]s/about</loc></url><url><loc>%[1]s/old</loc></url><url><loc>%[1]s/gone</loc></url>
+				<url><loc>http://external.test/</loc></url><url><loc>%[1]s/contact</loc></url>
+			</urlset>`, ts.URL)))
+		case "/":
+			fmt.Fprint(w, `<html><body><a href="/about">About</a><a href="/hidden">Hidden</a></body></html>`)
+		case "/about":
+			fmt.Fprint(w, `<html><body><a href="/">Home</a><a href="/team">Team</a><a href="/contact">Contact</a></body></html>`)
+		case "/contact":
+			fmt.Fprint(w, `<html><body><a href="/about">About</a></body></html>`)
+		case "/old":
+			http.Redirect(w, r, "/about", http.StatusMovedPermanently)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	service := NewWebAnalyzerService(log, repositorymemory.NewWebAnalyzerRepo(log), repositorymemory.NewBatchRepo(log), repositorymemory.NewCrawlRepo(log), NewLinkChecker(log, newTestNetworkGuard(), newTestRobotsCache(log), LinkCheckConfig{}), NewJobQueue(log, 2, 10), newTestWebhookDispatcher(log), newTestNetworkGuard(), PageFetchConfig{}, CrawlConfig{})
+	defer service.Shutdown(context.Background())
+
+	disabled := false
+	startURL, _ := url.Parse(ts.URL)
+	started, err := service.CrawlSite(context.Background(), startURL, CrawlSourceSitemap, 0, 0, contract.AnalysisOptions{CheckLinks: &disabled})
+	require.NoError(t, err)
+
+	var result *contract.CrawlResponse
+	require.Eventually(t, func() bool {
+		result, err = service.GetCrawl(context.Background(), started.CrawlID)
+		return err == nil && result.Done
+	}, 5*time.Second, 20*time.Millisecond)
+
+	assert.Equal(t, CrawlSourceSitemap, result.Source)
+	assert.Equal(t, CrawlStatusCompleted, result.Status)
+	assert.Zero(t, result.MaxDepth)
+
+	// Only the listed pages of the site are analyzed, links are not followed
+	var pages []string
+	for _, page := range result.Pages {
+		pages = append(pages, page.URL)
+		assert.Zero(t, page.Depth)
+	}
+	assert.Equal(t, []string{ts.URL + "/", ts.URL + "/about", ts.URL + "/old", ts.URL + "/gone", ts.URL + "/contact"}, pages)
+
+	require.NotNil(t, result.Sitemap)
+	assert.Equal(t, []string{ts.URL + "/sitemap_index.xml", ts.URL + "/sitemap.xml", ts.URL + "/sitemap-pages.xml.gz"}, result.Sitemap.Sitemaps)
+	assert.Equal(t, 5, result.Sitemap.Pages)
+	assert.Zero(t, result.Sitemap.NotAnalyzed)
+	require.Len(t, result.Sitemap.Errors, 1)
+	assert.Equal(t, ts.URL+"/gone", result.Sitemap.Errors[0].URL)
+	assert.NotEmpty(t, result.Sitemap.Errors[0].Error)
+	assert.Equal(t, []contract.CrawlSitemapPage{{
+		AnalyzeID:  result.Pages[2].AnalyzeID,
+		URL:        ts.URL + "/old",
+		StatusCode: http.StatusMovedPermanently,
+		FinalURL:   ts.URL + "/about",
+	}}, result.Sitemap.Redirects)
+	assert.ElementsMatch(t, []string{ts.URL + "/hidden", ts.URL + "/team"}, result.Sitemap.NotInSitemap)
+
+	// Listed pages nothing links to are unreachable from the start page, while pages linked from other
+	// analyzed pages are reachable
+	assert.ElementsMatch(t, []string{ts.URL + "/old", ts.URL + "/gone"}, result.Unreachable)
+
+	t.Run("Listed pages past the page limit", func(t *testing.T) {
+		started, err := service.CrawlSite(context.Background(), startURL, CrawlSourceSitemap, 0, 2, contract.AnalysisOptions{CheckLinks: &disabled})
+		require.NoError(t, err)
+
+		var result *contract.CrawlResponse
+		require.Eventually(t, func() bool {
+			result, err = service.GetCrawl(context.Background(), started.CrawlID)
+			return err == nil && result.Done
+		}, 5*time.Second, 20*time.Millisecond)
+
+		assert.Len(t, result.Pages, 2)
+		assert.Equal(t, 5, result.Sitemap.Pages)
+		assert.Equal(t, 3, result.Sitemap.NotAnalyzed)
+	})
+
+	t.Run("Depth limit does not apply", func(t *testing.T) {
+		_, err := service.CrawlSite(context.Background(), startURL, CrawlSourceSitemap, 1, 0, contract.AnalysisOptions{})
+		var appErr *apperror.AppError
+		require.ErrorAs(t, err, &appErr)
+		assert.Equal(t, http.StatusBadRequest, appErr.StatusCode)
+	})
+}
@@ -0,0 +1,143 @@
+package webanalyzer
+
+import (
+	"context"
+	"net/url"
+	"web-analyzer-api/app/internal/contract"
+	"web-analyzer-api/app/internal/core/apperror"
+	"web-analyzer-api/app/internal/model"
+)
+
+// MaxBatchSize is the largest number of URLs accepted in a single batch.
+const MaxBatchSize = 500
+
+// AnalyzeBatch saves a queued analysis for every URL with the same options and groups them in a batch.
+// The analyses are handed over to the job queue in the background as backlog slots free up, so a batch
+// may hold more URLs than the backlog.
+func (s *webAnalyzerService) AnalyzeBatch(ctx context.Context, baseURLs []*url.URL, callbackURL string, options contract.AnalysisOptions) (*contract.BatchAnalyzeResponse, error) {
+	if callbackURL != "" && !s.webhooks.Enabled() {
+		return nil, apperror.BadRequest("Webhook callbacks are not enabled on this server")
+	}
+
+	analysisOptions := toModelOptions(options)
+	analysisIds := make([]string, 0, len(baseURLs))
+	for _, baseURL := range baseURLs {
+		analysisId, err := s.saveQueuedAnalysis(baseURL, callbackURL, analysisOptions)
+		if err != nil {
+			s.abandonBatch(analysisIds)
+			return nil, err
+		}
+		analysisIds = append(analysisIds, analysisId)
+	}
+
+	batchId, err := s.batches.SaveBatch(model.Batch{AnalysisIDs: analysisIds})
+	if err != nil {
+		s.log.Error("Failed to save batch: " + err.Error())
+		s.abandonBatch(analysisIds)
+		return nil, apperror.InternalServerError("Failed to save batch")
+	}
+
+	s.crawler.wg.Add(1)
+	go s.queueBatch(analysisIds, baseURLs)
+
+	return &contract.BatchAnalyzeResponse{BatchID: batchId, AnalyzeIDs: analysisIds}, nil
+}
+
+// queueBatch submits the analyses of a batch in order, waiting for room in the backlog like crawls do.
+// Analyses cancelled meanwhile are skipped. Analyses not submitted before a shutdown stay queued and are
+// recovered on the next startup.
+func (s *webAnalyzerService) queueBatch(analysisIds []string, baseURLs []*url.URL) {
+	defer s.crawler.wg.Done()
+
+	for i, analysisId := range analysisIds {
+		if err := s.reserveSlot(s.crawler.ctx); err != nil {
+			s.log.Info("Stopped queueing batch: " + err.Error())
+			return
+		}
+
+		analysis, err := s.repo.GetById(analysisId)
+		if err != nil {
+			s.log.Error("Failed to get analysis data: " + err.Error())
+		} else if analysis == nil || analysis.Status != StatusQueued {
+			s.jobQueue.Release()
+			continue
+		}
+
+		if err := s.submitAnalysis(analysisId, baseURLs[i]); err != nil {
+			return
+		}
+	}
+}
+
+// abandonBatch cancels the analyses already saved for a batch that could not be created.
+func (s *webAnalyzerService) abandonBatch(analysisIds []string) {
+	for _, analysisId := range analysisIds {
+		s.UpdateAnalysisStatus(analysisId, StatusCancelled, "Batch could not be created.")
+	}
+}
+
+func (s *webAnalyzerService) GetBatch(ctx context.Context, batchId string) (*contract.BatchResponse, error) {
+	batch, err := s.batches.GetBatch(batchId)
+	if err != nil {
+		s.log.Error("Failed to get batch: " + err.Error())
+		return nil, apperror.InternalServerError("Failed to get batch")
+	}
+
+	if batch == nil {
+		return nil, apperror.NotFound("Batch not found")
+	}
+
+	response := contract.BatchResponse{
+		BatchID:   batch.ID,
+		Done:      true,
+		CreatedAt: batch.CreatedAt,
+		Summary: contract.BatchSummary{
+			Statuses:     map[string]int{},
+			HTMLVersions: map[string]int{},
+		},
+		Items: make([]contract.BatchItem, 0, len(batch.AnalysisIDs)),
+	}
+
+	for _, analysisId := range batch.AnalysisIDs {
+		analysis, err := s.repo.GetById(analysisId)
+		if err != nil {
+			s.log.Error("Failed to get analysis data: " + err.Error())
+			return nil, apperror.InternalServerError("Failed to get analysis data")
+		}
+		if analysis == nil {
+			s.log.Warn("Analysis of batch not found: analyzeId - " + analysisId)
+			continue
+		}
+
+		item := contract.BatchItem{
+			AnalyzeID:         analysis.ID,
+			URL:               analysis.URL,
+			Status:            analysis.Status,
+			InaccessibleLinks: analysis.Links.Inaccessible,
+			HasLoginForm:      analysis.HasLoginForm,
+		}
+		if analysis.ErrorDescription != nil {
+			item.ErrorDescription = *analysis.ErrorDescription
+		}
+		if analysis.Status == StatusQueued {
+			item.QueuePosition = s.jobQueue.Position(analysis.ID)
+		}
+		response.Items = append(response.Items, item)
+
+		summary := &response.Summary
+		summary.Total++
+		summary.Statuses[analysis.Status]++
+		if !isFinalStatus(analysis.Status) {
+			response.Done = false
+		}
+		if analysis.Status == StatusSuccess {
+			summary.BrokenLinks += analysis.Links.Inaccessible
+			if analysis.HasLoginForm {
+				summary.PagesWithLoginForm++
+			}
+			summary.HTMLVersions[analysis.HTMLVersion]++
+		}
+	}
+
+	return &response, nil
+}
@@ -0,0 +1,148 @@
+package webanalyzer
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+	"web-analyzer-api/app/internal/contract"
+	"web-analyzer-api/app/internal/core/apperror"
+	"web-analyzer-api/app/internal/model"
+	"web-analyzer-api/app/internal/repositorymemory"
+	"web-analyzer-api/app/internal/util/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnalyzeBatch(t *testing.T) {
+	log := logger.Get("info")
+
+	setupBatchTest := func(backlog int) *webAnalyzerService {
+		// Workers are intentionally not started so submitted jobs stay in the backlog
+		return &webAnalyzerService{
+			log:         log,
+			repo:        repositorymemory.NewWebAnalyzerRepo(log),
+			batches:     repositorymemory.NewBatchRepo(log),
+			linkChecker: NewLinkChecker(log, newTestNetworkGuard(), newTestRobotsCache(log), LinkCheckConfig{}),
+			jobQueue:    NewJobQueue(log, 1, backlog),
+			events:      newEventBroker(),
+			webhooks:    newTestWebhookDispatcher(log),
+			pages:       newPageFetcher(newTestNetworkGuard(), PageFetchConfig{}),
+			crawler:     newCrawlTracker(),
+		}
+	}
+
+	parseURLs := func(rawURLs ...string) []*url.URL {
+		urls := make([]*url.URL, len(rawURLs))
+		for i, rawURL := range rawURLs {
+			urls[i], _ = url.Parse(rawURL)
+		}
+		return urls
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		service := setupBatchTest(10)
+
+		result, err := service.AnalyzeBatch(context.Background(), parseURLs("http://a.test", "http://b.test"), "", contract.AnalysisOptions{})
+		require.NoError(t, err)
+		assert.NotEmpty(t, result.BatchID)
+		require.Len(t, result.AnalyzeIDs, 2)
+		require.Eventually(t, func() bool { return service.jobQueue.Len() == 2 }, time.Second, 10*time.Millisecond)
+
+		batch, err := service.GetBatch(context.Background(), result.BatchID)
+		require.NoError(t, err)
+		assert.False(t, batch.Done)
+		require.Len(t, batch.Items, 2)
+		assert.Equal(t, "http://a.test", batch.Items[0].URL)
+		assert.Equal(t, StatusQueued, batch.Items[0].Status)
+		assert.Equal(t, 1, batch.Items[0].QueuePosition)
+		assert.Equal(t, 2, batch.Items[1].QueuePosition)
+		assert.Equal(t, map[string]int{StatusQueued: 2}, batch.Summary.Statuses)
+	})
+
+	t.Run("Batch larger than the backlog", func(t *testing.T) {
+		service := setupBatchTest(2)
+		ctx := context.Background()
+
+		result, err := service.AnalyzeBatch(ctx, parseURLs("http://a.test", "http://b.test", "http://c.test", "http://d.test"), "", contract.AnalysisOptions{})
+		require.NoError(t, err)
+		require.Len(t, result.AnalyzeIDs, 4)
+		require.Eventually(t, func() bool { return service.jobQueue.Len() == 2 }, time.Second, 10*time.Millisecond)
+
+		// The rest waits as queued until the backlog has room
+		found, _ := service.repo.GetById(result.AnalyzeIDs[3])
+		assert.Equal(t, StatusQueued, found.Status)
+		assert.Zero(t, service.jobQueue.Position(result.AnalyzeIDs[3]))
+
+		// Analyses cancelled before their turn are skipped
+		require.NoError(t, service.CancelAnalysis(ctx, result.AnalyzeIDs[2]))
+		require.NoError(t, service.CancelAnalysis(ctx, result.AnalyzeIDs[0]))
+		require.Eventually(t, func() bool {
+			return service.jobQueue.Position(result.AnalyzeIDs[3]) == 2
+		}, 5*time.Second, 20*time.Millisecond)
+
+		found, _ = service.repo.GetById(result.AnalyzeIDs[2])
+		assert.Equal(t, StatusCancelled, found.Status)
+		assert.Equal(t, 2, service.jobQueue.Len())
+	})
+
+	t.Run("Callback without webhook secret", func(t *testing.T) {
+		service := setupBatchTest(10)
+
+		_, err := service.AnalyzeBatch(context.Background(), parseURLs("http://a.test"), "https://ci.test/hook", contract.AnalysisOptions{})
+		var appErr *apperror.AppError
+		require.ErrorAs(t, err, &appErr)
+		assert.Equal(t, http.StatusBadRequest, appErr.StatusCode)
+	})
+}
+
+func TestGetBatch(t *testing.T) {
+	log := logger.Get("info")
+	repo := repositorymemory.NewWebAnalyzerRepo(log)
+	batches := repositorymemory.NewBatchRepo(log)
+	service := &webAnalyzerService{log: log, repo: repo, batches: batches, jobQueue: NewJobQueue(log, 1, 10)}
+
+	errorDescription := "URL cannot be accessed. URL is invalid or unreachable."
+	analyses := []model.WebAnalyzer{
+		{URL: "http://a.test", Status: StatusSuccess, HTMLVersion: "HTML5", HasLoginForm: true, Links: model.LinkAnalysis{Inaccessible: 2}},
+		{URL: "http://b.test", Status: StatusSuccess, HTMLVersion: "HTML5", Links: model.LinkAnalysis{Inaccessible: 1}},
+		{URL: "http://c.test", Status: StatusSuccess, HTMLVersion: "HTML 4.01"},
+		{URL: "http://d.test", Status: StatusFailed, ErrorDescription: &errorDescription},
+		// Partial results of a cancelled analysis are not part of the summary
+		{URL: "http://e.test", Status: StatusCancelled, HasLoginForm: true, Links: model.LinkAnalysis{Inaccessible: 5}},
+	}
+
+	var analysisIds []string
+	for _, analysis := range analyses {
+		id, _ := repo.Save(analysis)
+		analysisIds = append(analysisIds, id)
+	}
+	batchId, _ := batches.SaveBatch(model.Batch{AnalysisIDs: analysisIds})
+
+	t.Run("Summary of finished batch", func(t *testing.T) {
+		result, err := service.GetBatch(context.Background(), batchId)
+		require.NoError(t, err)
+
+		assert.Equal(t, batchId, result.BatchID)
+		assert.True(t, result.Done)
+		require.Len(t, result.Items, 5)
+		assert.Equal(t, analysisIds[3], result.Items[3].AnalyzeID)
+		assert.Equal(t, errorDescription, result.Items[3].ErrorDescription)
+		assert.Equal(t, 2, result.Items[0].InaccessibleLinks)
+
+		assert.Equal(t, 5, result.Summary.Total)
+		assert.Equal(t, map[string]int{StatusSuccess: 3, StatusFailed: 1, StatusCancelled: 1}, result.Summary.Statuses)
+		assert.Equal(t, 3, result.Summary.BrokenLinks)
+		assert.Equal(t, 1, result.Summary.PagesWithLoginForm)
+		assert.Equal(t, map[string]int{"HTML5": 2, "HTML 4.01": 1}, result.Summary.HTMLVersions)
+	})
+
+	t.Run("Not found", func(t *testing.T) {
+		_, err := service.GetBatch(context.Background(), "missing")
+		var appErr *apperror.AppError
+		require.ErrorAs(t, err, &appErr)
+		assert.Equal(t, http.StatusNotFound, appErr.StatusCode)
+	})
+}
@@ -0,0 +1,531 @@
+package webanalyzer
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+	"web-analyzer-api/app/internal/contract"
+	"web-analyzer-api/app/internal/core/apperror"
+	"web-analyzer-api/app/internal/model"
+	htmlhelper "web-analyzer-api/app/internal/util/html"
+
+	"golang.org/x/net/html"
+)
+
+const (
+	CrawlStatusRunning     = "running"
+	CrawlStatusCompleted   = "completed"
+	CrawlStatusInterrupted = "interrupted"
+
+	// CrawlSourceLinks crawls follow the internal links of the start page, CrawlSourceSitemap crawls analyze
+	// the pages listed by the sitemaps of the site.
+	CrawlSourceLinks   = "links"
+	CrawlSourceSitemap = "sitemap"
+
+	DefaultCrawlMaxDepth = 3
+	DefaultCrawlMaxPages = 100
+
+	// queueRetryDelay is how long crawls and batches wait for room in the analysis backlog before they retry.
+	queueRetryDelay = time.Second
+)
+
+// CrawlConfig limits the crawls clients may request. Zero values mean the defaults.
+type CrawlConfig struct {
+	// MaxDepth is the largest number of links followed from the start page.
+	MaxDepth int
+	// MaxPages is the largest number of pages analyzed by a crawl, the start page included.
+	MaxPages int
+}
+
+func (c CrawlConfig) withDefaults() CrawlConfig {
+	if c.MaxDepth <= 0 {
+		c.MaxDepth = DefaultCrawlMaxDepth
+	}
+	if c.MaxPages <= 0 {
+		c.MaxPages = DefaultCrawlMaxPages
+	}
+	return c
+}
+
+// crawledPage is the outcome of the analysis of a crawled page. Links is nil unless the analysis succeeded.
+type crawledPage struct {
+	FinalURL string
+	Links    []string
+}
+
+// crawlTracker hands the outcome of page analyses over to the crawls waiting for them, and stops the
+// crawls and the batches still being queued on shutdown.
+type crawlTracker struct {
+	ctx     context.Context
+	stop    context.CancelFunc
+	wg      sync.WaitGroup
+	mu      sync.Mutex
+	waiting map[string]chan crawledPage
+}
+
+func newCrawlTracker() *crawlTracker {
+	ctx, stop := context.WithCancel(context.Background())
+	return &crawlTracker{ctx: ctx, stop: stop, waiting: make(map[string]chan crawledPage)}
+}
+
+// watch registers an analysis a crawl waits for. It must be called before the analysis is submitted.
+func (t *crawlTracker) watch(analysisId string) <-chan crawledPage {
+	done := make(chan crawledPage, 1)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.waiting[analysisId] = done
+	return done
+}
+
+// finish reports the end of an analysis. Only the first report of a watched analysis is delivered.
+func (t *crawlTracker) finish(analysisId string, page crawledPage) {
+	if t == nil {
+		return
+	}
+
+	t.mu.Lock()
+	done, ok := t.waiting[analysisId]
+	delete(t.waiting, analysisId)
+	t.mu.Unlock()
+
+	if ok {
+		done <- page
+	}
+}
+
+// shutdown stops all crawls and waits until they have recorded their status or ctx is done.
+func (t *crawlTracker) shutdown(ctx context.Context) error {
+	if t == nil {
+		return nil
+	}
+	t.stop()
+
+	stopped := make(chan struct{})
+	go func() {
+		t.wg.Wait()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// CrawlSite queues the analysis of baseURL and then of the internal pages it links to, level by level,
+// until maxDepth or maxPages is reached. With the sitemap source the pages listed by the sitemaps of the
+// site are analyzed instead, up to maxPages, and no links are followed.
+func (s *webAnalyzerService) CrawlSite(ctx context.Context, baseURL *url.URL, source string, maxDepth int, maxPages int, options contract.AnalysisOptions) (*contract.CrawlAnalyzeResponse, error) {
+	limits := s.crawlConfig.withDefaults()
+	if source == "" {
+		source = CrawlSourceLinks
+	}
+	if source != CrawlSourceLinks && source != CrawlSourceSitemap {
+		return nil, apperror.BadRequest("Invalid source. Allowed values: links, sitemap")
+	}
+	if source == CrawlSourceSitemap && maxDepth != 0 {
+		return nil, apperror.BadRequest("max_depth does not apply to sitemap crawls")
+	}
+	if maxDepth < 0 || maxDepth > limits.MaxDepth {
+		return nil, apperror.BadRequest("Invalid max_depth. Must be between 1 and " + strconv.Itoa(limits.MaxDepth))
+	}
+	if maxPages < 0 || maxPages > limits.MaxPages {
+		return nil, apperror.BadRequest("Invalid max_pages. Must be between 1 and " + strconv.Itoa(limits.MaxPages))
+	}
+	if maxDepth == 0 && source == CrawlSourceLinks {
+		maxDepth = limits.MaxDepth
+	}
+	if maxPages == 0 {
+		maxPages = limits.MaxPages
+	}
+
+	if err := s.jobQueue.Reserve(); err != nil {
+		s.log.Warn("Rejecting crawl request: " + err.Error())
+		return nil, queueUnavailableError(err)
+	}
+
+	analysisOptions := toModelOptions(options)
+	analysisId, err := s.saveQueuedAnalysis(baseURL, "", analysisOptions)
+	if err != nil {
+		s.jobQueue.Release()
+		return nil, err
+	}
+
+	startURL, _ := htmlhelper.NormalizeLink(baseURL.String(), baseURL)
+	crawl := model.Crawl{
+		URL:      startURL,
+		Source:   source,
+		Status:   CrawlStatusRunning,
+		MaxDepth: maxDepth,
+		MaxPages: maxPages,
+		Pages:    []model.CrawlPage{{URL: startURL, AnalysisID: analysisId}},
+	}
+	crawl.ID, err = s.crawls.SaveCrawl(crawl)
+	if err != nil {
+		s.log.Error("Failed to save crawl: " + err.Error())
+		s.jobQueue.Release()
+		s.UpdateAnalysisStatus(analysisId, StatusCancelled, "Crawl could not be created.")
+		return nil, apperror.InternalServerError("Failed to save crawl")
+	}
+
+	done := s.crawler.watch(analysisId)
+	if err := s.submitAnalysis(analysisId, baseURL); err != nil {
+		s.crawler.finish(analysisId, crawledPage{})
+		s.finishCrawl(&crawl, CrawlStatusInterrupted)
+		return nil, err
+	}
+
+	s.crawler.wg.Add(1)
+	go s.runCrawl(crawl, analysisOptions, done)
+
+	return &contract.CrawlAnalyzeResponse{CrawlID: crawl.ID, AnalyzeID: analysisId}, nil
+}
+
+// runCrawl waits for the analysis of every page of a crawl and queues the pages they link to that are
+// within its limits, after the pages listed by the sitemaps for a sitemap crawl. The crawl is completed
+// once no analysis is left.
+func (s *webAnalyzerService) runCrawl(crawl model.Crawl, options model.AnalysisOptions, start <-chan crawledPage) {
+	defer s.crawler.wg.Done()
+	ctx := s.crawler.ctx
+
+	type pageResult struct {
+		index int
+		page  crawledPage
+	}
+	results := make(chan pageResult)
+	wait := func(index int, done <-chan crawledPage) {
+		select {
+		case page := <-done:
+			select {
+			case results <- pageResult{index: index, page: page}:
+			case <-ctx.Done():
+			}
+		case <-ctx.Done():
+		}
+	}
+
+	seen := map[string]bool{crawl.URL: true}
+	pending := 1
+	go wait(0, start)
+
+	// queue submits the analysis of a page. It returns false when the crawl cannot go on.
+	queue := func(page model.CrawlPage) bool {
+		seen[page.URL] = true
+
+		analysisId, done, err := s.submitCrawlPage(ctx, page.URL, options)
+		if err != nil {
+			if errors.Is(err, ErrQueueClosed) || ctx.Err() != nil {
+				return false
+			}
+			s.log.Warn("Skipping crawled page " + page.URL + ": " + err.Error())
+			return true
+		}
+
+		page.AnalysisID = analysisId
+		crawl.Pages = append(crawl.Pages, page)
+		pending++
+		go wait(len(crawl.Pages)-1, done)
+		return true
+	}
+
+	// Pages found by crawling are checked against the sitemaps for a sitemap crawl
+	var listed map[string]bool
+	notListed := func(page string) {
+		if listed != nil && !listed[page] {
+			// Reported pages are marked as listed so they are reported once
+			listed[page] = true
+			crawl.Sitemap.NotInSitemap = append(crawl.Sitemap.NotInSitemap, page)
+		}
+	}
+
+	if crawl.Source == CrawlSourceSitemap {
+		startURL, _ := url.Parse(crawl.URL)
+		listing := s.collectSitemap(ctx, startURL, options.UserAgent)
+		s.log.Info("Sitemaps of " + crawl.URL + " list " + strconv.Itoa(len(listing.Pages)) + " pages")
+
+		crawl.Sitemap = model.CrawlSitemap{Sitemaps: listing.Sitemaps, Pages: len(listing.Pages), NotInSitemap: []string{}}
+		listed = make(map[string]bool, len(listing.Pages))
+		for _, page := range listing.Pages {
+			listed[page] = true
+		}
+		crawl.Pages[0].InSitemap = listed[crawl.URL]
+		notListed(crawl.URL)
+
+		for _, page := range listing.Pages {
+			if len(crawl.Pages) >= crawl.MaxPages {
+				break
+			}
+			if seen[page] {
+				continue
+			}
+			if !queue(model.CrawlPage{URL: page, InSitemap: true}) {
+				s.finishCrawl(&crawl, CrawlStatusInterrupted)
+				return
+			}
+		}
+
+		// No more pages are queued once the listed ones are, so the rest will not be analyzed
+		queued := make(map[string]bool, len(crawl.Pages))
+		for _, page := range crawl.Pages {
+			queued[page.URL] = true
+		}
+		for _, page := range listing.Pages {
+			if !queued[page] {
+				crawl.Sitemap.NotAnalyzed++
+			}
+		}
+
+		if err := s.crawls.UpdateCrawl(crawl); err != nil {
+			s.log.Error("Failed to update crawl: " + err.Error())
+		}
+	}
+
+	for pending > 0 {
+		var result pageResult
+		select {
+		case result = <-results:
+		case <-ctx.Done():
+			s.finishCrawl(&crawl, CrawlStatusInterrupted)
+			return
+		}
+		pending--
+
+		// Links to where the page redirected to lead to the same page
+		if finalURL, err := url.Parse(result.page.FinalURL); err == nil && result.page.FinalURL != "" {
+			if normalized, ok := htmlhelper.NormalizeLink(finalURL.String(), finalURL); ok {
+				seen[normalized] = true
+			}
+		}
+
+		crawl.Pages[result.index].Links = result.page.Links
+		for _, link := range result.page.Links {
+			notListed(link)
+		}
+
+		depth := crawl.Pages[result.index].Depth
+		for _, link := range result.page.Links {
+			if depth >= crawl.MaxDepth || len(crawl.Pages) >= crawl.MaxPages {
+				break
+			}
+			if seen[link] {
+				continue
+			}
+			if !queue(model.CrawlPage{URL: link, Depth: depth + 1}) {
+				s.finishCrawl(&crawl, CrawlStatusInterrupted)
+				return
+			}
+		}
+
+		if err := s.crawls.UpdateCrawl(crawl); err != nil {
+			s.log.Error("Failed to update crawl: " + err.Error())
+		}
+	}
+
+	s.finishCrawl(&crawl, CrawlStatusCompleted)
+}
+
+// submitCrawlPage queues the analysis of a crawled page, waiting for room in the backlog when it is full.
+func (s *webAnalyzerService) submitCrawlPage(ctx context.Context, page string, options model.AnalysisOptions) (string, <-chan crawledPage, error) {
+	pageURL, err := url.Parse(page)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if err := s.reserveSlot(ctx); err != nil {
+		return "", nil, err
+	}
+
+	analysisId, err := s.saveQueuedAnalysis(pageURL, "", options)
+	if err != nil {
+		s.jobQueue.Release()
+		return "", nil, err
+	}
+
+	done := s.crawler.watch(analysisId)
+	if err := s.submitAnalysis(analysisId, pageURL); err != nil {
+		// Submitting a reserved slot only fails once the queue is closed
+		s.crawler.finish(analysisId, crawledPage{})
+		return "", nil, ErrQueueClosed
+	}
+
+	return analysisId, done, nil
+}
+
+func (s *webAnalyzerService) finishCrawl(crawl *model.Crawl, status string) {
+	crawl.Status = status
+	if err := s.crawls.UpdateCrawl(*crawl); err != nil {
+		s.log.Error("Failed to update crawl: " + err.Error())
+		return
+	}
+	s.log.Info("Crawl " + status + ": " + crawl.URL + " (" + strconv.Itoa(len(crawl.Pages)) + " pages)")
+}
+
+// crawlLinks returns the unique internal pages an analyzed page links to, leaving out the page itself.
+func crawlLinks(doc *html.Node, pageURL *url.URL) []string {
+	self, _ := htmlhelper.NormalizeLink(pageURL.String(), pageURL)
+	linkBaseURL := htmlhelper.GetBaseURL(doc, pageURL)
+
+	links := []string{}
+	seen := map[string]bool{self: true}
+	for _, link := range htmlhelper.GetLinks(doc) {
+		page, ok := htmlhelper.NormalizeLink(link, linkBaseURL)
+		if !ok || seen[page] || !htmlhelper.IsInternalLink(page, pageURL) {
+			continue
+		}
+		seen[page] = true
+		links = append(links, page)
+	}
+	return links
+}
+
+func (s *webAnalyzerService) GetCrawl(ctx context.Context, crawlId string) (*contract.CrawlResponse, error) {
+	crawl, err := s.crawls.GetCrawl(crawlId)
+	if err != nil {
+		s.log.Error("Failed to get crawl: " + err.Error())
+		return nil, apperror.InternalServerError("Failed to get crawl")
+	}
+
+	if crawl == nil {
+		return nil, apperror.NotFound("Crawl not found")
+	}
+
+	response := contract.CrawlResponse{
+		CrawlID:   crawl.ID,
+		URL:       crawl.URL,
+		Source:    crawl.Source,
+		Status:    crawl.Status,
+		Done:      crawl.Status != CrawlStatusRunning,
+		MaxDepth:  crawl.MaxDepth,
+		MaxPages:  crawl.MaxPages,
+		CreatedAt: crawl.CreatedAt,
+		UpdatedAt: crawl.UpdatedAt,
+		Summary:   contract.CrawlSummary{Statuses: map[string]int{}},
+		Pages:     make([]contract.CrawlPage, 0, len(crawl.Pages)),
+	}
+
+	var sitemap *contract.CrawlSitemap
+	if crawl.Source == CrawlSourceSitemap {
+		sitemap = &contract.CrawlSitemap{
+			Sitemaps:     crawl.Sitemap.Sitemaps,
+			Pages:        crawl.Sitemap.Pages,
+			NotAnalyzed:  crawl.Sitemap.NotAnalyzed,
+			Errors:       []contract.CrawlSitemapPage{},
+			Redirects:    []contract.CrawlSitemapPage{},
+			NotInSitemap: crawl.Sitemap.NotInSitemap,
+		}
+		if sitemap.Sitemaps == nil {
+			sitemap.Sitemaps = []string{}
+		}
+		if sitemap.NotInSitemap == nil {
+			sitemap.NotInSitemap = []string{}
+		}
+		response.Sitemap = sitemap
+	}
+
+	analyzed := map[string]bool{}
+	for _, page := range crawl.Pages {
+		analysis, err := s.repo.GetById(page.AnalysisID)
+		if err != nil {
+			s.log.Error("Failed to get analysis data: " + err.Error())
+			return nil, apperror.InternalServerError("Failed to get analysis data")
+		}
+		if analysis == nil {
+			s.log.Warn("Analysis of crawl not found: analyzeId - " + page.AnalysisID)
+			continue
+		}
+
+		item := contract.CrawlPage{
+			AnalyzeID:         analysis.ID,
+			URL:               page.URL,
+			Depth:             page.Depth,
+			Status:            analysis.Status,
+			Title:             analysis.Title,
+			HTMLVersion:       analysis.HTMLVersion,
+			InaccessibleLinks: analysis.Links.Inaccessible,
+			HasLoginForm:      analysis.HasLoginForm,
+			Links:             page.Links,
+		}
+		if item.Links == nil {
+			item.Links = []string{}
+		}
+		if analysis.ErrorDescription != nil {
+			item.ErrorDescription = *analysis.ErrorDescription
+		}
+		response.Pages = append(response.Pages, item)
+
+		if sitemap != nil && page.InSitemap {
+			addSitemapIssues(sitemap, *analysis, page.URL)
+		}
+
+		response.Summary.Pages++
+		response.Summary.Statuses[analysis.Status]++
+		if analysis.Status == StatusSuccess {
+			analyzed[page.URL] = true
+			response.Summary.BrokenLinks += analysis.Links.Inaccessible
+		}
+	}
+
+	response.Unreachable = unreachablePages(*crawl, analyzed)
+	response.Summary.Unreachable = len(response.Unreachable)
+
+	return &response, nil
+}
+
+// addSitemapIssues reports the analysis of a page listed by the sitemaps when the page failed or redirected.
+func addSitemapIssues(sitemap *contract.CrawlSitemap, analysis model.WebAnalyzer, pageURL string) {
+	if analysis.Status == StatusFailed {
+		issue := contract.CrawlSitemapPage{AnalyzeID: analysis.ID, URL: pageURL}
+		if analysis.ErrorDescription != nil {
+			issue.Error = *analysis.ErrorDescription
+		}
+		sitemap.Errors = append(sitemap.Errors, issue)
+	}
+
+	if len(analysis.Redirects) > 0 {
+		sitemap.Redirects = append(sitemap.Redirects, contract.CrawlSitemapPage{
+			AnalyzeID:  analysis.ID,
+			URL:        pageURL,
+			StatusCode: analysis.Redirects[0].StatusCode,
+			FinalURL:   analysis.FinalURL,
+		})
+	}
+}
+
+// unreachablePages returns the pages of a crawl that cannot be reached from its start page: pages that
+// could not be analyzed and pages no chain of links leads to. Only the links of analyzed pages are followed.
+func unreachablePages(crawl model.Crawl, analyzed map[string]bool) []string {
+	links := make(map[string][]string, len(crawl.Pages))
+	for _, page := range crawl.Pages {
+		links[page.URL] = page.Links
+	}
+
+	reached := map[string]bool{crawl.URL: true}
+	queue := []string{crawl.URL}
+	for len(queue) > 0 {
+		page := queue[0]
+		queue = queue[1:]
+		if !analyzed[page] {
+			continue
+		}
+		for _, link := range links[page] {
+			if !reached[link] {
+				reached[link] = true
+				queue = append(queue, link)
+			}
+		}
+	}
+
+	unreachable := []string{}
+	for _, page := range crawl.Pages {
+		if !reached[page.URL] || !analyzed[page.URL] {
+			unreachable = append(unreachable, page.URL)
+		}
+	}
+	return unreachable
+}
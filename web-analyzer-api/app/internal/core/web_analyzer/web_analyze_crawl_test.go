@@ -0,0 +1,166 @@
+package webanalyzer
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+	"web-analyzer-api/app/internal/contract"
+	"web-analyzer-api/app/internal/core"
+	"web-analyzer-api/app/internal/core/apperror"
+	"web-analyzer-api/app/internal/model"
+	"web-analyzer-api/app/internal/repositorymemory"
+	"web-analyzer-api/app/internal/util/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/html"
+)
+
+func TestCrawlSite(t *testing.T) {
+	log := logger.Get("info")
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/":
+			fmt.Fprint(w, `<html><head><title>Home</title></head><body>
+				<a href="/a">A</a><a href="/b#top">B</a><a href="/">Home</a><a href="http://external.test/">External</a>
+			</body></html>`)
+		case "/a":
+			fmt.Fprint(w, `<html><head><title>A</title></head><body><a href="/c">C</a><a href="/b">B</a></body></html>`)
+		case "/b":
+			fmt.Fprint(w, `<html><head><title>B</title></head><body><a href="/missing">Missing</a></body></html>`)
+		case "/c":
+			fmt.Fprint(w, `<html><head><title>C</title></head><body><a href="/d">D</a></body></html>`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	setupCrawlTest := func() core.WebAnalyzerService {
+		return NewWebAnalyzerService(log, repositorymemory.NewWebAnalyzerRepo(log), repositorymemory.NewBatchRepo(log), repositorymemory.NewCrawlRepo(log), NewLinkChecker(log, newTestNetworkGuard(), newTestRobotsCache(log), LinkCheckConfig{}), NewJobQueue(log, 2, 10), newTestWebhookDispatcher(log), newTestNetworkGuard(), PageFetchConfig{}, CrawlConfig{MaxDepth: 3, MaxPages: 10})
+	}
+	disabled := false
+	options := contract.AnalysisOptions{CheckLinks: &disabled}
+	startURL, _ := url.Parse(ts.URL)
+
+	waitForCrawl := func(t *testing.T, service core.WebAnalyzerService, crawlId string) *contract.CrawlResponse {
+		var result *contract.CrawlResponse
+		require.Eventually(t, func() bool {
+			var err error
+			result, err = service.GetCrawl(context.Background(), crawlId)
+			return err == nil && result.Done
+		}, 5*time.Second, 20*time.Millisecond)
+		return result
+	}
+
+	t.Run("Depth limit", func(t *testing.T) {
+		service := setupCrawlTest()
+		defer service.Shutdown(context.Background())
+
+		started, err := service.CrawlSite(context.Background(), startURL, "", 2, 0, options)
+		require.NoError(t, err)
+		assert.NotEmpty(t, started.AnalyzeID)
+
+		result := waitForCrawl(t, service, started.CrawlID)
+		assert.Equal(t, CrawlStatusCompleted, result.Status)
+		assert.Equal(t, ts.URL+"/", result.URL)
+		assert.Equal(t, 2, result.MaxDepth)
+		assert.Equal(t, 10, result.MaxPages)
+		assert.Equal(t, started.AnalyzeID, result.Pages[0].AnalyzeID)
+
+		pages := map[string]contract.CrawlPage{}
+		for _, page := range result.Pages {
+			pages[strings.TrimPrefix(page.URL, ts.URL)] = page
+		}
+		require.Len(t, pages, 5)
+		assert.Equal(t, 0, pages["/"].Depth)
+		assert.Equal(t, "Home", pages["/"].Title)
+		assert.ElementsMatch(t, []string{ts.URL + "/a", ts.URL + "/b"}, pages["/"].Links)
+		assert.Equal(t, 1, pages["/b"].Depth)
+		assert.Equal(t, 2, pages["/c"].Depth)
+		assert.Equal(t, []string{ts.URL + "/d"}, pages["/c"].Links)
+		assert.Equal(t, StatusFailed, pages["/missing"].Status)
+		assert.Equal(t, []string{}, pages["/missing"].Links)
+
+		// Pages at the depth limit are analyzed, the pages they link to are not
+		assert.NotContains(t, pages, "/d")
+
+		assert.Equal(t, 5, result.Summary.Pages)
+		assert.Equal(t, map[string]int{StatusSuccess: 4, StatusFailed: 1}, result.Summary.Statuses)
+		assert.Equal(t, []string{ts.URL + "/missing"}, result.Unreachable)
+		assert.Equal(t, 1, result.Summary.Unreachable)
+	})
+
+	t.Run("Page limit", func(t *testing.T) {
+		service := setupCrawlTest()
+		defer service.Shutdown(context.Background())
+
+		started, err := service.CrawlSite(context.Background(), startURL, "", 0, 2, options)
+		require.NoError(t, err)
+
+		result := waitForCrawl(t, service, started.CrawlID)
+		assert.Equal(t, CrawlStatusCompleted, result.Status)
+		assert.Equal(t, 3, result.MaxDepth)
+		require.Len(t, result.Pages, 2)
+		assert.Equal(t, ts.URL+"/", result.Pages[0].URL)
+		assert.Equal(t, 1, result.Pages[1].Depth)
+	})
+
+	t.Run("Limits above the server maximum", func(t *testing.T) {
+		service := setupCrawlTest()
+		defer service.Shutdown(context.Background())
+
+		for _, limits := range [][2]int{{4, 0}, {0, 11}} {
+			_, err := service.CrawlSite(context.Background(), startURL, "", limits[0], limits[1], options)
+			var appErr *apperror.AppError
+			require.ErrorAs(t, err, &appErr)
+			assert.Equal(t, http.StatusBadRequest, appErr.StatusCode)
+		}
+	})
+
+	t.Run("Not found", func(t *testing.T) {
+		service := setupCrawlTest()
+		defer service.Shutdown(context.Background())
+
+		_, err := service.GetCrawl(context.Background(), "missing")
+		var appErr *apperror.AppError
+		require.ErrorAs(t, err, &appErr)
+		assert.Equal(t, http.StatusNotFound, appErr.StatusCode)
+	})
+}
+
+func TestCrawlLinks(t *testing.T) {
+	pageURL, _ := url.Parse("http://myapp.test/docs/")
+	doc, err := html.Parse(strings.NewReader(`<html><body>
+		<a href="guide">Guide</a><a href="/docs/guide#install">Install</a><a href="./">Self</a>
+		<a href="HTTP://MYAPP.test/about">About</a><a href="https://other.test/">Other</a><a href="mailto:me@myapp.test">Mail</a>
+	</body></html>`))
+	require.NoError(t, err)
+
+	assert.ElementsMatch(t, []string{"http://myapp.test/docs/guide", "http://myapp.test/about"}, crawlLinks(doc, pageURL))
+}
+
+func TestUnreachablePages(t *testing.T) {
+	crawl := model.Crawl{
+		URL: "http://a.test/",
+		Pages: []model.CrawlPage{
+			{URL: "http://a.test/", Links: []string{"http://a.test/about"}},
+			{URL: "http://a.test/about", Links: []string{"http://a.test/team"}},
+			{URL: "http://a.test/team"},
+			{URL: "http://a.test/orphan", Links: []string{"http://a.test/"}},
+		},
+	}
+
+	analyzed := map[string]bool{"http://a.test/": true, "http://a.test/about": true, "http://a.test/team": true, "http://a.test/orphan": true}
+	assert.Equal(t, []string{"http://a.test/orphan"}, unreachablePages(crawl, analyzed))
+
+	// The links of pages that were not analyzed are not followed
+	analyzed["http://a.test/about"] = false
+	assert.Equal(t, []string{"http://a.test/about", "http://a.test/team", "http://a.test/orphan"}, unreachablePages(crawl, analyzed))
+}
@@ -0,0 +1,143 @@
+package webanalyzer
+
+import (
+	"context"
+	"web-analyzer-api/app/internal/contract"
+	"web-analyzer-api/app/internal/core/apperror"
+	"web-analyzer-api/app/internal/model"
+	"web-analyzer-api/app/internal/repository"
+)
+
+// DiffAnalyses compares two successful analyses that checked links with the same options. When a URL is
+// given the latest two successful runs of that URL are compared, otherwise the analyses identified by
+// FromID and ToID.
+func (s *webAnalyzerService) DiffAnalyses(ctx context.Context, req contract.AnalysisDiffRequest) (*contract.AnalysisDiffResponse, error) {
+	var from, to *model.WebAnalyzer
+
+	if req.URL != "" {
+		result, err := s.repo.List(repository.ListFilter{
+			Statuses: []string{StatusSuccess},
+			URL:      req.URL,
+			SortBy:   repository.SortByCreatedAt,
+			SortDesc: true,
+			Limit:    2,
+		})
+		if err != nil {
+			s.log.Error("Failed to list analyses: " + err.Error())
+			return nil, apperror.InternalServerError("Failed to list analyses")
+		}
+
+		if len(result.Items) < 2 {
+			return nil, apperror.NotFound("At least two successful analyses are required to compare URL: " + req.URL)
+		}
+		to, from = &result.Items[0], &result.Items[1]
+	} else {
+		var err error
+		if from, err = s.getComparableAnalysis(req.FromID); err != nil {
+			return nil, err
+		}
+		if to, err = s.getComparableAnalysis(req.ToID); err != nil {
+			return nil, err
+		}
+	}
+
+	// Links left unchecked by one of the runs would be reported as fixed or broken
+	if !sameLinkCheck(from.Options, to.Options) {
+		return nil, apperror.Conflict("Analyses " + from.ID + " and " + to.ID + " checked links with different options and cannot be compared")
+	}
+
+	diff := diffAnalyses(*from, *to)
+	return &diff, nil
+}
+
+func (s *webAnalyzerService) getComparableAnalysis(analyzeId string) (*model.WebAnalyzer, error) {
+	analysis, err := s.repo.GetById(analyzeId)
+	if err != nil {
+		s.log.Error("Failed to get analysis data: " + err.Error())
+		return nil, apperror.InternalServerError("Failed to get analysis data")
+	}
+
+	if analysis == nil {
+		return nil, apperror.NotFound("Analysis result not found: " + analyzeId)
+	}
+
+	// Only complete results can be compared, partial ones would report missing links as fixed
+	if analysis.Status != StatusSuccess {
+		return nil, apperror.Conflict("Analysis " + analyzeId + " has not completed successfully, status: " + analysis.Status)
+	}
+
+	return analysis, nil
+}
+
+func diffAnalyses(from model.WebAnalyzer, to model.WebAnalyzer) contract.AnalysisDiffResponse {
+	diff := contract.AnalysisDiffResponse{
+		From:     toAnalysisRef(from),
+		To:       toAnalysisRef(to),
+		Headings: map[string]contract.HeadingDiff{},
+		Links: contract.LinkDiff{
+			InternalDelta:     to.Links.Internal - from.Links.Internal,
+			ExternalDelta:     to.Links.External - from.Links.External,
+			InaccessibleDelta: to.Links.Inaccessible - from.Links.Inaccessible,
+			NewlyBroken:       subtractLinks(to.Links.InaccessibleDetails, from.Links.InaccessibleDetails),
+			NewlyFixed:        subtractLinks(from.Links.InaccessibleDetails, to.Links.InaccessibleDetails),
+		},
+	}
+
+	if from.Title != to.Title {
+		diff.Title = &contract.StringChange{From: from.Title, To: to.Title}
+	}
+
+	if from.HTMLVersion != to.HTMLVersion {
+		diff.HTMLVersion = &contract.StringChange{From: from.HTMLVersion, To: to.HTMLVersion}
+	}
+
+	if from.HasLoginForm != to.HasLoginForm {
+		diff.HasLoginForm = &contract.BoolChange{From: from.HasLoginForm, To: to.HasLoginForm}
+	}
+
+	for level := range from.Headings {
+		addHeadingDiff(diff.Headings, level, from.Headings[level], to.Headings[level])
+	}
+	for level := range to.Headings {
+		addHeadingDiff(diff.Headings, level, from.Headings[level], to.Headings[level])
+	}
+
+	diff.Changed = diff.Title != nil || diff.HTMLVersion != nil || diff.HasLoginForm != nil || len(diff.Headings) > 0 ||
+		diff.Links.InternalDelta != 0 || diff.Links.ExternalDelta != 0 || diff.Links.InaccessibleDelta != 0 ||
+		len(diff.Links.NewlyBroken) > 0 || len(diff.Links.NewlyFixed) > 0
+
+	return diff
+}
+
+func toAnalysisRef(analysis model.WebAnalyzer) contract.AnalysisRef {
+	ref := contract.AnalysisRef{AnalyzeID: analysis.ID, URL: analysis.URL}
+	if !analysis.CreatedAt.IsZero() {
+		createdAt := analysis.CreatedAt
+		ref.CreatedAt = &createdAt
+	}
+	return ref
+}
+
+func addHeadingDiff(headings map[string]contract.HeadingDiff, level string, from int, to int) {
+	if from != to {
+		headings[level] = contract.HeadingDiff{From: from, To: to, Delta: to - from}
+	}
+}
+
+// subtractLinks returns the links of a whose URL does not appear in b, in the order of a.
+func subtractLinks(a []model.InaccessibleLink, b []model.InaccessibleLink) []contract.InaccessibleLink {
+	exclude := make(map[string]bool, len(b))
+	for _, link := range b {
+		exclude[link.URL] = true
+	}
+
+	result := []contract.InaccessibleLink{}
+	for _, link := range a {
+		if exclude[link.URL] {
+			continue
+		}
+		exclude[link.URL] = true
+		result = append(result, toContractInaccessibleLink(link))
+	}
+	return result
+}
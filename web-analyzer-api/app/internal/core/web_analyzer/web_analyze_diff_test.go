@@ -0,0 +1,160 @@
+package webanalyzer
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+	"web-analyzer-api/app/internal/contract"
+	"web-analyzer-api/app/internal/core/apperror"
+	"web-analyzer-api/app/internal/model"
+	"web-analyzer-api/app/internal/repositorymemory"
+	"web-analyzer-api/app/internal/util/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffAnalyses_Structure(t *testing.T) {
+	from := model.WebAnalyzer{
+		ID:          "from-id",
+		URL:         "https://test.com",
+		HTMLVersion: "HTML 4.01",
+		Title:       "Old title",
+		Headings:    map[string]int{"h1": 1, "h2": 3, "h3": 2},
+		Links: model.LinkAnalysis{
+			Internal:     10,
+			External:     4,
+			Inaccessible: 2,
+			InaccessibleDetails: []model.InaccessibleLink{
+				{URL: "https://test.com/fixed", StatusCode: 404},
+				{URL: "https://test.com/still-broken", StatusCode: 500},
+			},
+		},
+	}
+	to := model.WebAnalyzer{
+		ID:           "to-id",
+		URL:          "https://test.com",
+		HTMLVersion:  "HTML5",
+		Title:        "New title",
+		Headings:     map[string]int{"h1": 1, "h2": 1, "h4": 1},
+		HasLoginForm: true,
+		Links: model.LinkAnalysis{
+			Internal:     12,
+			External:     4,
+			Inaccessible: 2,
+			InaccessibleDetails: []model.InaccessibleLink{
+				{URL: "https://test.com/still-broken", StatusCode: 503},
+				{URL: "https://other.com/new", StatusCode: 0},
+			},
+		},
+	}
+
+	diff := diffAnalyses(from, to)
+
+	assert.True(t, diff.Changed)
+	assert.Equal(t, "from-id", diff.From.AnalyzeID)
+	assert.Equal(t, "to-id", diff.To.AnalyzeID)
+	assert.Equal(t, &contract.StringChange{From: "Old title", To: "New title"}, diff.Title)
+	assert.Equal(t, &contract.StringChange{From: "HTML 4.01", To: "HTML5"}, diff.HTMLVersion)
+	assert.Equal(t, &contract.BoolChange{From: false, To: true}, diff.HasLoginForm)
+	assert.Equal(t, map[string]contract.HeadingDiff{
+		"h2": {From: 3, To: 1, Delta: -2},
+		"h3": {From: 2, To: 0, Delta: -2},
+		"h4": {From: 0, To: 1, Delta: 1},
+	}, diff.Headings)
+	assert.Equal(t, 2, diff.Links.InternalDelta)
+	assert.Equal(t, 0, diff.Links.ExternalDelta)
+	assert.Equal(t, 0, diff.Links.InaccessibleDelta)
+	assert.Equal(t, []contract.InaccessibleLink{{URL: "https://other.com/new", StatusCode: 0}}, diff.Links.NewlyBroken)
+	assert.Equal(t, []contract.InaccessibleLink{{URL: "https://test.com/fixed", StatusCode: 404}}, diff.Links.NewlyFixed)
+}
+
+func TestDiffAnalyses_Unchanged(t *testing.T) {
+	analysis := model.WebAnalyzer{
+		Title:    "Same",
+		Headings: map[string]int{"h1": 1},
+		Links: model.LinkAnalysis{
+			Inaccessible:        1,
+			InaccessibleDetails: []model.InaccessibleLink{{URL: "https://test.com/broken", StatusCode: 404}},
+		},
+	}
+
+	diff := diffAnalyses(analysis, analysis)
+
+	assert.False(t, diff.Changed)
+	assert.Nil(t, diff.Title)
+	assert.Nil(t, diff.HTMLVersion)
+	assert.Nil(t, diff.HasLoginForm)
+	assert.Empty(t, diff.Headings)
+	assert.Empty(t, diff.Links.NewlyBroken)
+	assert.Empty(t, diff.Links.NewlyFixed)
+}
+
+func TestDiffAnalysesService(t *testing.T) {
+	log := logger.Get("info")
+	repo := repositorymemory.NewWebAnalyzerRepo(log)
+	service := &webAnalyzerService{log: log, repo: repo, linkChecker: new(MockLinkChecker), jobQueue: NewJobQueue(log, 1, 10), events: newEventBroker(), webhooks: newTestWebhookDispatcher(log)}
+
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	save := func(analysis model.WebAnalyzer) string {
+		id, err := repo.Save(analysis)
+		require.NoError(t, err)
+		return id
+	}
+	oldest := save(model.WebAnalyzer{URL: "https://test.com", Title: "v1", Status: StatusSuccess, CreatedAt: base})
+	previous := save(model.WebAnalyzer{URL: "https://test.com", Title: "v2", Status: StatusSuccess, CreatedAt: base.Add(time.Hour)})
+	save(model.WebAnalyzer{URL: "https://other.com", Title: "other", Status: StatusSuccess, CreatedAt: base.Add(2 * time.Hour)})
+	save(model.WebAnalyzer{URL: "https://test.com", Status: StatusFailed, CreatedAt: base.Add(3 * time.Hour)})
+	latest := save(model.WebAnalyzer{URL: "https://test.com", Title: "v3", Status: StatusSuccess, CreatedAt: base.Add(4 * time.Hour)})
+	running := save(model.WebAnalyzer{URL: "https://test.com", Status: StatusPending, CreatedAt: base.Add(5 * time.Hour)})
+	noLinkCheck := save(model.WebAnalyzer{URL: "https://options.com", Status: StatusSuccess, Options: model.AnalysisOptions{SkipLinkCheck: true}})
+	fewerLinks := save(model.WebAnalyzer{URL: "https://options.com", Status: StatusSuccess, Options: model.AnalysisOptions{MaxLinks: 10}})
+	moreWorkers := save(model.WebAnalyzer{URL: "https://options.com", Title: "v4", Status: StatusSuccess, Options: model.AnalysisOptions{LinkWorkers: 20, LinkTimeout: DefaultLinkTimeout}})
+
+	t.Run("Latest two successful runs of a URL", func(t *testing.T) {
+		diff, err := service.DiffAnalyses(context.Background(), contract.AnalysisDiffRequest{URL: "https://test.com"})
+
+		require.NoError(t, err)
+		assert.Equal(t, previous, diff.From.AnalyzeID)
+		assert.Equal(t, latest, diff.To.AnalyzeID)
+		assert.Equal(t, &contract.StringChange{From: "v2", To: "v3"}, diff.Title)
+	})
+
+	t.Run("Explicit analyses", func(t *testing.T) {
+		diff, err := service.DiffAnalyses(context.Background(), contract.AnalysisDiffRequest{FromID: oldest, ToID: latest})
+
+		require.NoError(t, err)
+		assert.Equal(t, &contract.StringChange{From: "v1", To: "v3"}, diff.Title)
+	})
+
+	t.Run("Link workers and default timeout may differ", func(t *testing.T) {
+		diff, err := service.DiffAnalyses(context.Background(), contract.AnalysisDiffRequest{FromID: latest, ToID: moreWorkers})
+
+		require.NoError(t, err)
+		assert.Equal(t, &contract.StringChange{From: "v3", To: "v4"}, diff.Title)
+	})
+
+	tests := []struct {
+		name       string
+		req        contract.AnalysisDiffRequest
+		statusCode int
+	}{
+		{"URL with a single run", contract.AnalysisDiffRequest{URL: "https://other.com"}, http.StatusNotFound},
+		{"Unknown URL", contract.AnalysisDiffRequest{URL: "https://unknown.com"}, http.StatusNotFound},
+		{"Unknown analysis", contract.AnalysisDiffRequest{FromID: oldest, ToID: "missing"}, http.StatusNotFound},
+		{"Unfinished analysis", contract.AnalysisDiffRequest{FromID: oldest, ToID: running}, http.StatusConflict},
+		{"Links not checked", contract.AnalysisDiffRequest{FromID: latest, ToID: noLinkCheck}, http.StatusConflict},
+		{"Different link limit", contract.AnalysisDiffRequest{FromID: latest, ToID: fewerLinks}, http.StatusConflict},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diff, err := service.DiffAnalyses(context.Background(), tt.req)
+
+			assert.Nil(t, diff)
+			var appErr *apperror.AppError
+			require.ErrorAs(t, err, &appErr)
+			assert.Equal(t, tt.statusCode, appErr.StatusCode)
+		})
+	}
+}
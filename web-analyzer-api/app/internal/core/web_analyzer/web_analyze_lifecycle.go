@@ -0,0 +1,163 @@
+package webanalyzer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+const (
+	RecoveryPolicyResume = "resume"
+	RecoveryPolicyFail   = "fail"
+)
+
+const (
+	interruptedBeforeStart = "Analysis was interrupted by a server shutdown before it started."
+	interruptedTimeout     = 5 * time.Second
+)
+
+// stoppedStatus maps the cause of a stopped job context to the status and reason recorded for the analysis.
+func stoppedStatus(ctx context.Context) (string, string) {
+	if errors.Is(context.Cause(ctx), ErrAnalysisInterrupted) {
+		return StatusInterrupted, "Analysis was interrupted by a server shutdown"
+	}
+	return StatusCancelled, "Analysis was cancelled"
+}
+
+// Start starts the analysis workers of a service created with NewUnstartedWebAnalyzerService. It is called
+// once stale analyses have been recovered, so that resumed analyses are not picked up while recovery is still
+// updating them. Later calls do nothing.
+func (s *webAnalyzerService) Start() {
+	s.started.Do(func() {
+		s.jobQueue.Start(s.processAnalysisJob)
+	})
+}
+
+// Shutdown stops accepting analyses and waits for running ones until ctx is done. Analyses that do
+// not finish in time are interrupted and recorded with status interrupted so they can be recovered.
+// Running crawls stop queueing pages and are recorded as interrupted. Webhook deliveries still in flight
+// get the rest of the grace period.
+func (s *webAnalyzerService) Shutdown(ctx context.Context) error {
+	if err := s.crawler.shutdown(ctx); err != nil {
+		s.log.Warn("Grace period elapsed with crawls still stopping")
+	}
+
+	err := s.stopJobs(ctx)
+
+	if webhookErr := s.webhooks.Shutdown(ctx); webhookErr != nil {
+		s.log.Warn("Grace period elapsed with webhook deliveries pending")
+		err = errors.Join(err, webhookErr)
+	}
+
+	return err
+}
+
+func (s *webAnalyzerService) stopJobs(ctx context.Context) error {
+	s.log.Info("Stopping analysis workers")
+
+	for _, id := range s.jobQueue.Close() {
+		s.UpdateAnalysisStatus(id, StatusInterrupted, interruptedBeforeStart)
+	}
+
+	if err := s.jobQueue.Wait(ctx); err == nil {
+		s.log.Info("All running analyses completed")
+		return nil
+	}
+
+	// Grace period is over: running jobs record their partial results as interrupted
+	interrupted := s.jobQueue.InterruptAll(ErrAnalysisInterrupted)
+	s.log.Warn(fmt.Sprintf("Grace period elapsed, interrupting %d running analyses", len(interrupted)))
+
+	waitCtx, cancel := context.WithTimeout(context.Background(), interruptedTimeout)
+	defer cancel()
+
+	if err := s.jobQueue.Wait(waitCtx); err != nil {
+		// Jobs that did not react in time are checkpointed here so they are not left pending
+		for _, id := range interrupted {
+			s.UpdateAnalysisStatus(id, StatusInterrupted, "Analysis was interrupted by a server shutdown.")
+		}
+		return err
+	}
+
+	return nil
+}
+
+// RecoverStaleAnalyses handles analyses left unfinished by a previous run. They are either put back
+// on the queue or marked as failed, depending on the policy. Crawls left running are interrupted.
+func (s *webAnalyzerService) RecoverStaleAnalyses(policy string) error {
+	if policy != RecoveryPolicyResume && policy != RecoveryPolicyFail {
+		return fmt.Errorf("unsupported stale analysis policy: %s", policy)
+	}
+
+	if err := s.recoverStaleCrawls(); err != nil {
+		return err
+	}
+
+	stale, err := s.repo.FindByStatus(StatusQueued, StatusPending, StatusInterrupted)
+	if err != nil {
+		return fmt.Errorf("find stale analyses: %w", err)
+	}
+
+	if len(stale) == 0 {
+		return nil
+	}
+	s.log.Info(fmt.Sprintf("Recovering %d stale analyses", len(stale)), "policy", policy)
+
+	for _, analysis := range stale {
+		if policy == RecoveryPolicyFail {
+			s.UpdateAnalysisStatus(analysis.ID, StatusFailed, "Analysis was interrupted by a server restart.")
+			continue
+		}
+
+		baseURL, err := url.Parse(analysis.URL)
+		if err != nil {
+			s.UpdateAnalysisStatus(analysis.ID, StatusFailed, "Analysis was interrupted by a server restart.")
+			continue
+		}
+
+		if err := s.jobQueue.Reserve(); err != nil {
+			s.log.Warn("Cannot resume analysis: " + err.Error())
+			s.UpdateAnalysisStatus(analysis.ID, StatusFailed, "Analysis was interrupted by a server restart and could not be resumed.")
+			continue
+		}
+
+		analysis.Status = StatusQueued
+		analysis.ErrorDescription = nil
+		if _, err := s.repo.Update(analysis); err != nil {
+			s.jobQueue.Release()
+			s.log.Error("Failed to update analysis status: " + err.Error())
+			continue
+		}
+
+		if err := s.jobQueue.Submit(analysis.ID, baseURL); err != nil {
+			s.UpdateAnalysisStatus(analysis.ID, StatusInterrupted, interruptedBeforeStart)
+		}
+	}
+
+	return nil
+}
+
+// recoverStaleCrawls marks the crawls left running by a previous run as interrupted. Their page analyses are
+// recovered like any other, but no crawl follows their links anymore.
+func (s *webAnalyzerService) recoverStaleCrawls() error {
+	stale, err := s.crawls.FindCrawlsByStatus(CrawlStatusRunning)
+	if err != nil {
+		return fmt.Errorf("find stale crawls: %w", err)
+	}
+
+	if len(stale) == 0 {
+		return nil
+	}
+	s.log.Info(fmt.Sprintf("Interrupting %d stale crawls", len(stale)))
+
+	for _, crawl := range stale {
+		crawl.Status = CrawlStatusInterrupted
+		if err := s.crawls.UpdateCrawl(crawl); err != nil {
+			s.log.Error("Failed to update crawl status: " + err.Error())
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,190 @@
+package webanalyzer
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+	"web-analyzer-api/app/internal/contract"
+	"web-analyzer-api/app/internal/core/apperror"
+	"web-analyzer-api/app/internal/model"
+	"web-analyzer-api/app/internal/repositorymemory"
+	"web-analyzer-api/app/internal/util/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStoppedStatus(t *testing.T) {
+	ctx, cancel := context.WithCancelCause(context.Background())
+	cancel(ErrAnalysisInterrupted)
+	status, _ := stoppedStatus(ctx)
+	assert.Equal(t, StatusInterrupted, status)
+
+	ctx, cancel = context.WithCancelCause(context.Background())
+	cancel(ErrAnalysisCancelled)
+	status, _ = stoppedStatus(ctx)
+	assert.Equal(t, StatusCancelled, status)
+}
+
+func TestStart(t *testing.T) {
+	log := logger.Get("info")
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("<html><head><title>Test</title></head><body></body></html>"))
+	}))
+	defer ts.Close()
+
+	repo := repositorymemory.NewWebAnalyzerRepo(log)
+	service := NewUnstartedWebAnalyzerService(log, repo, repositorymemory.NewBatchRepo(log), repositorymemory.NewCrawlRepo(log), NewLinkChecker(log, newTestNetworkGuard(), newTestRobotsCache(log), LinkCheckConfig{}), NewJobQueue(log, 1, 10), newTestWebhookDispatcher(log), newTestNetworkGuard(), PageFetchConfig{}, CrawlConfig{})
+	defer service.Shutdown(context.Background())
+	pageURL, _ := url.Parse(ts.URL + "/")
+
+	id, err := service.AnalyzeWebsite(context.Background(), pageURL, "", contract.AnalysisOptions{})
+	require.NoError(t, err)
+
+	// Analyses wait in the backlog until the workers are started
+	time.Sleep(50 * time.Millisecond)
+	found, _ := repo.GetById(id)
+	assert.Equal(t, StatusQueued, found.Status)
+
+	service.Start()
+	require.Eventually(t, func() bool {
+		found, _ := repo.GetById(id)
+		return found.Status == StatusSuccess
+	}, 5*time.Second, 20*time.Millisecond)
+}
+
+func TestShutdown(t *testing.T) {
+	log := logger.Get("info")
+
+	t.Run("Idle workers and queued analyses", func(t *testing.T) {
+		repo := repositorymemory.NewWebAnalyzerRepo(log)
+		queue := NewJobQueue(log, 1, 10)
+		// Workers are intentionally not started so submitted jobs stay in the backlog
+		service := &webAnalyzerService{log: log, repo: repo, linkChecker: NewLinkChecker(log, newTestNetworkGuard(), newTestRobotsCache(log), LinkCheckConfig{}), pages: newPageFetcher(newTestNetworkGuard(), PageFetchConfig{}), jobQueue: queue, events: newEventBroker(), webhooks: newTestWebhookDispatcher(log)}
+
+		baseURL, _ := url.Parse("http://test.com")
+		id, _ := repo.Save(model.WebAnalyzer{URL: baseURL.String(), Status: StatusQueued})
+		require.NoError(t, queue.Submit(id, baseURL))
+
+		assert.NoError(t, service.Shutdown(context.Background()))
+
+		found, _ := repo.GetById(id)
+		assert.Equal(t, StatusInterrupted, found.Status)
+
+		_, err := service.AnalyzeWebsite(context.Background(), baseURL, "", contract.AnalysisOptions{})
+		var appErr *apperror.AppError
+		assert.ErrorAs(t, err, &appErr)
+		assert.Equal(t, http.StatusServiceUnavailable, appErr.StatusCode)
+		assert.Contains(t, appErr.Message, "shutting down")
+	})
+
+	t.Run("Running analysis exceeds grace period", func(t *testing.T) {
+		slowReached := make(chan struct{})
+		var slowOnce sync.Once
+
+		var ts *httptest.Server
+		ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/":
+				fmt.Fprintf(w, `<html><body><a href="%s/slow">slow</a></body></html>`, ts.URL)
+			case "/slow":
+				slowOnce.Do(func() { close(slowReached) })
+				<-r.Context().Done()
+			}
+		}))
+		defer ts.Close()
+
+		repo := repositorymemory.NewWebAnalyzerRepo(log)
+		service := NewWebAnalyzerService(log, repo, repositorymemory.NewBatchRepo(log), repositorymemory.NewCrawlRepo(log), NewLinkChecker(log, newTestNetworkGuard(), newTestRobotsCache(log), LinkCheckConfig{}), NewJobQueue(log, 1, 10), newTestWebhookDispatcher(log), newTestNetworkGuard(), PageFetchConfig{}, CrawlConfig{})
+		pageURL, _ := url.Parse(ts.URL + "/")
+
+		id, err := service.AnalyzeWebsite(context.Background(), pageURL, "", contract.AnalysisOptions{})
+		require.NoError(t, err)
+		<-slowReached
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		assert.NoError(t, service.Shutdown(ctx))
+
+		found, _ := repo.GetById(id)
+		assert.Equal(t, StatusInterrupted, found.Status)
+		assert.Equal(t, 1, found.Links.Internal)
+		assert.Contains(t, *found.ErrorDescription, "interrupted")
+	})
+}
+
+func TestRecoverStaleAnalyses(t *testing.T) {
+	log := logger.Get("info")
+
+	setupRecoveryTest := func() (*webAnalyzerService, map[string]string) {
+		repo := repositorymemory.NewWebAnalyzerRepo(log)
+		// Workers are intentionally not started so resumed jobs stay in the backlog
+		service := &webAnalyzerService{log: log, repo: repo, crawls: repositorymemory.NewCrawlRepo(log), linkChecker: NewLinkChecker(log, newTestNetworkGuard(), newTestRobotsCache(log), LinkCheckConfig{}), pages: newPageFetcher(newTestNetworkGuard(), PageFetchConfig{}), jobQueue: NewJobQueue(log, 1, 2), events: newEventBroker(), webhooks: newTestWebhookDispatcher(log)}
+
+		ids := map[string]string{}
+		for _, status := range []string{StatusQueued, StatusPending, StatusInterrupted, StatusSuccess} {
+			ids[status], _ = repo.Save(model.WebAnalyzer{URL: "http://test.com", Status: status})
+		}
+		return service, ids
+	}
+
+	t.Run("Resume policy", func(t *testing.T) {
+		service, ids := setupRecoveryTest()
+
+		assert.NoError(t, service.RecoverStaleAnalyses(RecoveryPolicyResume))
+
+		// The backlog holds two jobs, the remaining stale analysis cannot be resumed
+		statuses := map[string]int{}
+		for _, status := range []string{StatusQueued, StatusPending, StatusInterrupted} {
+			found, _ := service.repo.GetById(ids[status])
+			statuses[found.Status]++
+		}
+		assert.Equal(t, map[string]int{StatusQueued: 2, StatusFailed: 1}, statuses)
+		assert.Equal(t, 2, service.jobQueue.Len())
+
+		found, _ := service.repo.GetById(ids[StatusSuccess])
+		assert.Equal(t, StatusSuccess, found.Status)
+	})
+
+	t.Run("Fail policy", func(t *testing.T) {
+		service, ids := setupRecoveryTest()
+
+		assert.NoError(t, service.RecoverStaleAnalyses(RecoveryPolicyFail))
+
+		for _, status := range []string{StatusQueued, StatusPending, StatusInterrupted} {
+			found, _ := service.repo.GetById(ids[status])
+			assert.Equal(t, StatusFailed, found.Status)
+			assert.NotNil(t, found.ErrorDescription)
+		}
+		assert.Equal(t, 0, service.jobQueue.Len())
+	})
+
+	t.Run("Running crawls are interrupted", func(t *testing.T) {
+		service, ids := setupRecoveryTest()
+		running, _ := service.crawls.SaveCrawl(model.Crawl{URL: "http://test.com", Status: CrawlStatusRunning, Pages: []model.CrawlPage{{URL: "http://test.com", AnalysisID: ids[StatusPending]}}})
+		completed, _ := service.crawls.SaveCrawl(model.Crawl{URL: "http://test.com", Status: CrawlStatusCompleted})
+
+		assert.NoError(t, service.RecoverStaleAnalyses(RecoveryPolicyResume))
+
+		crawl, err := service.GetCrawl(context.Background(), running)
+		require.NoError(t, err)
+		assert.Equal(t, CrawlStatusInterrupted, crawl.Status)
+		assert.True(t, crawl.Done)
+		require.Len(t, crawl.Pages, 1)
+
+		found, _ := service.crawls.GetCrawl(completed)
+		assert.Equal(t, CrawlStatusCompleted, found.Status)
+	})
+
+	t.Run("Unsupported policy", func(t *testing.T) {
+		service, _ := setupRecoveryTest()
+
+		assert.Error(t, service.RecoverStaleAnalyses("ignore"))
+	})
+}
@@ -2,10 +2,10 @@ package webanalyzer
 
 import (
 	"context"
-	"net/http"
+	"errors"
 	"net/url"
-	"strconv"
 	"sync"
+	"time"
 	"web-analyzer-api/app/internal/contract"
 	"web-analyzer-api/app/internal/core"
 	"web-analyzer-api/app/internal/core/apperror"
@@ -18,43 +18,142 @@ import (
 )
 
 const (
-	StatusPending = "pending"
-	StatusSuccess = "success"
-	StatusFailed  = "failed"
+	StatusQueued      = "queued"
+	StatusPending     = "pending"
+	StatusSuccess     = "success"
+	StatusFailed      = "failed"
+	StatusCancelled   = "cancelled"
+	StatusInterrupted = "interrupted"
 )
 
+var (
+	ErrAnalysisCancelled   = errors.New("analysis cancelled")
+	ErrAnalysisInterrupted = errors.New("analysis interrupted by shutdown")
+)
+
+const (
+	SortOrderAsc  = "asc"
+	SortOrderDesc = "desc"
+
+	DefaultListLimit = 20
+	MaxListLimit     = 100
+)
+
+// queueFullRetryAfter is the Retry-After hint returned when the analysis backlog is full.
+const queueFullRetryAfter = 30 * time.Second
+
 type webAnalyzerService struct {
 	log         *logger.Logger
 	repo        repository.WebAnalyzerRepository
+	batches     repository.BatchRepository
+	crawls      repository.CrawlRepository
 	linkChecker core.LinkChecker
+	jobQueue    *JobQueue
+	events      *eventBroker
+	webhooks    *WebhookDispatcher
+	pages       *pageFetcher
+	crawler     *crawlTracker
+	crawlConfig CrawlConfig
+	started     sync.Once
+}
+
+// NewWebAnalyzerService returns a service whose analysis workers are running.
+func NewWebAnalyzerService(logger *logger.Logger, repo repository.WebAnalyzerRepository, batches repository.BatchRepository, crawls repository.CrawlRepository, linkChecker core.LinkChecker, jobQueue *JobQueue, webhooks *WebhookDispatcher, guard *NetworkGuard, fetchConfig PageFetchConfig, crawlConfig CrawlConfig) core.WebAnalyzerService {
+	s := NewUnstartedWebAnalyzerService(logger, repo, batches, crawls, linkChecker, jobQueue, webhooks, guard, fetchConfig, crawlConfig)
+	s.Start()
+	return s
 }
 
-func NewWebAnalyzerService(logger *logger.Logger, repo repository.WebAnalyzerRepository, linkChecker core.LinkChecker) core.WebAnalyzerService {
+// NewUnstartedWebAnalyzerService returns a service whose analysis workers only run once Start is called, so
+// that stale analyses can be recovered before any of them is picked up.
+func NewUnstartedWebAnalyzerService(logger *logger.Logger, repo repository.WebAnalyzerRepository, batches repository.BatchRepository, crawls repository.CrawlRepository, linkChecker core.LinkChecker, jobQueue *JobQueue, webhooks *WebhookDispatcher, guard *NetworkGuard, fetchConfig PageFetchConfig, crawlConfig CrawlConfig) core.WebAnalyzerService {
 	return &webAnalyzerService{
 		log:         logger,
 		repo:        repo,
+		batches:     batches,
+		crawls:      crawls,
 		linkChecker: linkChecker,
+		jobQueue:    jobQueue,
+		events:      newEventBroker(),
+		webhooks:    webhooks,
+		pages:       newPageFetcher(guard, fetchConfig),
+		crawler:     newCrawlTracker(),
+		crawlConfig: crawlConfig,
 	}
 }
 
-func (s *webAnalyzerService) AnalyzeWebsite(ctx context.Context, baseURL *url.URL) (analysisId string, err error) {
+func (s *webAnalyzerService) AnalyzeWebsite(ctx context.Context, baseURL *url.URL, callbackURL string, options contract.AnalysisOptions) (analysisId string, err error) {
+	if callbackURL != "" && !s.webhooks.Enabled() {
+		return "", apperror.BadRequest("Webhook callbacks are not enabled on this server")
+	}
+
+	if err := s.jobQueue.Reserve(); err != nil {
+		s.log.Warn("Rejecting analysis request: " + err.Error())
+		return "", queueUnavailableError(err)
+	}
+
+	analysisId, err = s.saveQueuedAnalysis(baseURL, callbackURL, toModelOptions(options))
+	if err != nil {
+		s.jobQueue.Release()
+		return "", err
+	}
+
+	if err := s.submitAnalysis(analysisId, baseURL); err != nil {
+		return "", err
+	}
+
+	return analysisId, nil
+}
+
+// saveQueuedAnalysis persists a new analysis for which a queue slot has already been reserved.
+func (s *webAnalyzerService) saveQueuedAnalysis(baseURL *url.URL, callbackURL string, options model.AnalysisOptions) (string, error) {
 	analysis := model.WebAnalyzer{
-		URL:    baseURL.String(),
-		Status: StatusPending,
+		URL:         baseURL.String(),
+		Status:      StatusQueued,
+		CallbackURL: callbackURL,
+		Options:     options,
 	}
 
-	analysisId, err = s.repo.Save(analysis)
+	analysisId, err := s.repo.Save(analysis)
 	if err != nil {
 		s.log.Error("Failed to save initial analysis: " + err.Error())
 		return "", apperror.BadRequest("Failed to save initial analysis data")
 	}
 
-	// Call background analysis
-	go s.processAnalysisJob(context.Background(), analysisId, baseURL)
-
 	return analysisId, nil
 }
 
+// reserveSlot claims a backlog slot, waiting while the backlog is full.
+func (s *webAnalyzerService) reserveSlot(ctx context.Context) error {
+	for {
+		err := s.jobQueue.Reserve()
+		if !errors.Is(err, ErrQueueFull) {
+			return err
+		}
+		if err := sleepContext(ctx, queueRetryDelay); err != nil {
+			return err
+		}
+	}
+}
+
+// submitAnalysis hands a saved analysis over to the analysis workers, consuming its reserved slot.
+func (s *webAnalyzerService) submitAnalysis(analysisId string, baseURL *url.URL) error {
+	if err := s.jobQueue.Submit(analysisId, baseURL); err != nil {
+		s.log.Warn("Rejecting analysis request: " + err.Error())
+		s.UpdateAnalysisStatus(analysisId, StatusInterrupted, interruptedBeforeStart)
+		return queueUnavailableError(err)
+	}
+
+	return nil
+}
+
+func queueUnavailableError(err error) *apperror.AppError {
+	if errors.Is(err, ErrQueueClosed) {
+		return apperror.ServiceUnavailable("Service is shutting down, please retry later", queueFullRetryAfter)
+	}
+	return apperror.ServiceUnavailable("Analysis queue is full, please retry later", queueFullRetryAfter)
+}
+
 func (s *webAnalyzerService) GetAnalyzeData(ctx context.Context, analyzeId string) (*contract.WebAnalyzeResponse, error) {
 	result, err := s.repo.GetById(analyzeId)
 	if err != nil {
@@ -67,13 +166,80 @@ func (s *webAnalyzerService) GetAnalyzeData(ctx context.Context, analyzeId strin
 		return nil, apperror.NotFound("Analysis result not found")
 	}
 
+	analysis := toWebAnalyzeResponse(*result)
+
+	if result.Status == StatusQueued {
+		analysis.QueuePosition = s.jobQueue.Position(analyzeId)
+	}
+
+	return &analysis, nil
+}
+
+func (s *webAnalyzerService) ListAnalyses(ctx context.Context, req contract.ListAnalysesRequest) (*contract.ListAnalysesResponse, error) {
+	filter := repository.ListFilter{
+		Statuses:     req.Statuses,
+		URLContains:  req.URL,
+		HostContains: req.Host,
+		CreatedFrom:  req.CreatedFrom,
+		CreatedTo:    req.CreatedTo,
+		SortBy:       req.Sort,
+		SortDesc:     req.Order != SortOrderAsc,
+		Cursor:       req.Cursor,
+		Limit:        req.Limit,
+	}
+
+	if filter.SortBy == "" {
+		filter.SortBy = repository.SortByCreatedAt
+	}
+	if filter.Limit <= 0 {
+		filter.Limit = DefaultListLimit
+	}
+	if filter.Limit > MaxListLimit {
+		filter.Limit = MaxListLimit
+	}
+
+	result, err := s.repo.List(filter)
+	if err != nil {
+		if errors.Is(err, repository.ErrInvalidCursor) {
+			return nil, apperror.BadRequest("Invalid cursor")
+		}
+		s.log.Error("Failed to list analyses: " + err.Error())
+		return nil, apperror.InternalServerError("Failed to list analyses")
+	}
+
+	response := contract.ListAnalysesResponse{
+		Items:      make([]contract.WebAnalyzeResponse, len(result.Items)),
+		NextCursor: result.NextCursor,
+	}
+	for i, item := range result.Items {
+		response.Items[i] = toWebAnalyzeResponse(item)
+		if item.Status == StatusQueued {
+			response.Items[i].QueuePosition = s.jobQueue.Position(item.ID)
+		}
+	}
+
+	return &response, nil
+}
+
+func toWebAnalyzeResponse(result model.WebAnalyzer) contract.WebAnalyzeResponse {
 	var inaccessibleDetails []contract.InaccessibleLink
 	if result.Links.InaccessibleDetails != nil {
 		inaccessibleDetails = make([]contract.InaccessibleLink, len(result.Links.InaccessibleDetails))
 		for i, detail := range result.Links.InaccessibleDetails {
-			inaccessibleDetails[i] = contract.InaccessibleLink{
-				URL:        detail.URL,
-				StatusCode: detail.StatusCode,
+			inaccessibleDetails[i] = toContractInaccessibleLink(detail)
+		}
+	}
+
+	var redirectedDetails []contract.LinkRedirect
+	if result.Links.Redirected != nil {
+		redirectedDetails = make([]contract.LinkRedirect, len(result.Links.Redirected))
+		for i, redirect := range result.Links.Redirected {
+			redirectedDetails[i] = contract.LinkRedirect{
+				URL:        redirect.URL,
+				FinalURL:   redirect.FinalURL,
+				StatusCode: redirect.StatusCode,
+				Chain:      toContractRedirects(redirect.Chain),
+				Error:      redirect.Error,
 			}
 		}
 	}
@@ -83,23 +249,131 @@ func (s *webAnalyzerService) GetAnalyzeData(ctx context.Context, analyzeId strin
 		errorDescription = *result.ErrorDescription
 	}
 
-	analysis := contract.WebAnalyzeResponse{
+	response := contract.WebAnalyzeResponse{
+		AnalyzeID:   result.ID,
 		URL:         result.URL,
 		HTMLVersion: result.HTMLVersion,
 		Title:       result.Title,
 		Headings:    result.Headings,
 		Links: contract.LinkAnalysis{
-			Internal:            result.Links.Internal,
-			External:            result.Links.External,
-			Inaccessible:        result.Links.Inaccessible,
-			InaccessibleDetails: inaccessibleDetails,
+			Internal:             result.Links.Internal,
+			External:             result.Links.External,
+			InternalOccurrences:  result.Links.InternalOccurrences,
+			ExternalOccurrences:  result.Links.ExternalOccurrences,
+			Inaccessible:         result.Links.Inaccessible,
+			InaccessibleDetails:  inaccessibleDetails,
+			Redirected:           len(result.Links.Redirected),
+			RedirectedDetails:    redirectedDetails,
+			ErrorCategories:      result.Links.ErrorCategories,
+			SkippedRobots:        len(result.Links.SkippedRobots),
+			SkippedRobotsDetails: result.Links.SkippedRobots,
+			Resources:            toContractResources(result.Links.Resources),
 		},
 		HasLoginForm:     result.HasLoginForm,
+		SEO:              toContractSEO(result.SEO),
 		Status:           result.Status,
 		ErrorDescription: errorDescription,
+		FinalURL:         result.FinalURL,
+		Redirects:        toContractRedirects(result.Redirects),
+		Encoding:         result.Encoding,
+		Options:          toContractOptions(result.Options),
+		UpdatedAt:        result.UpdatedAt,
 	}
 
-	return &analysis, nil
+	if !result.CreatedAt.IsZero() {
+		createdAt := result.CreatedAt
+		response.CreatedAt = &createdAt
+	}
+
+	return response
+}
+
+// addInaccessible records a link that failed its check and publishes it.
+func (s *webAnalyzerService) addInaccessible(analysisId string, analysis *model.LinkAnalysis, inaccessible model.InaccessibleLink) {
+	analysis.Inaccessible++
+	analysis.InaccessibleDetails = append(analysis.InaccessibleDetails, inaccessible)
+	if analysis.ErrorCategories == nil {
+		analysis.ErrorCategories = map[string]int{}
+	}
+	analysis.ErrorCategories[inaccessible.Category]++
+
+	link := toContractInaccessibleLink(inaccessible)
+	s.events.publish(contract.AnalysisEvent{
+		Type:      EventLinkInaccessible,
+		AnalyzeID: analysisId,
+		Status:    StatusPending,
+		Link:      &link,
+	})
+}
+
+func toContractInaccessibleLink(link model.InaccessibleLink) contract.InaccessibleLink {
+	return contract.InaccessibleLink{
+		URL:        link.URL,
+		StatusCode: link.StatusCode,
+		Category:   link.Category,
+		Message:    link.Message,
+		Kinds:      link.Kinds,
+	}
+}
+
+func toContractResources(resources map[string]model.ResourceStats) map[string]contract.ResourceStats {
+	if resources == nil {
+		return nil
+	}
+
+	stats := make(map[string]contract.ResourceStats, len(resources))
+	for kind, resource := range resources {
+		stats[kind] = contract.ResourceStats{Internal: resource.Internal, External: resource.External, Broken: resource.Broken}
+	}
+	return stats
+}
+
+func toContractSEO(seo *model.SEOAnalysis) *contract.SEOAnalysis {
+	if seo == nil {
+		return nil
+	}
+
+	hreflang := make([]contract.HreflangAlternate, len(seo.Hreflang))
+	for i, alternate := range seo.Hreflang {
+		hreflang[i] = contract.HreflangAlternate{Lang: alternate.Lang, URL: alternate.URL}
+	}
+	issues := make([]contract.SEOIssue, len(seo.Issues))
+	for i, issue := range seo.Issues {
+		issues[i] = contract.SEOIssue{Code: issue.Code, Message: issue.Message}
+	}
+
+	openGraph, twitterCard := seo.OpenGraph, seo.TwitterCard
+	if openGraph == nil {
+		openGraph = map[string]string{}
+	}
+	if twitterCard == nil {
+		twitterCard = map[string]string{}
+	}
+
+	return &contract.SEOAnalysis{
+		TitleCount:  seo.TitleCount,
+		Description: seo.Description,
+		Robots:      seo.Robots,
+		Canonical:   seo.Canonical,
+		Hreflang:    hreflang,
+		OpenGraph:   openGraph,
+		TwitterCard: twitterCard,
+		Viewport:    seo.Viewport,
+		Lang:        seo.Lang,
+		Issues:      issues,
+	}
+}
+
+func toContractRedirects(chain []model.RedirectHop) []contract.RedirectHop {
+	if chain == nil {
+		return nil
+	}
+
+	hops := make([]contract.RedirectHop, len(chain))
+	for i, hop := range chain {
+		hops[i] = contract.RedirectHop{URL: hop.URL, StatusCode: hop.StatusCode}
+	}
+	return hops
 }
 
 func (s *webAnalyzerService) UpdateAnalysisStatus(analyzeId string, status string, errorDescription string) {
@@ -125,9 +399,101 @@ func (s *webAnalyzerService) UpdateAnalysisStatus(analyzeId string, status strin
 		s.log.Error("Failed to update analysis status: " + err.Error())
 		return
 	}
+
+	s.publishStatus(analyzeId, status, errorDescription)
+	s.notifyWebhook(*analysis)
+	if isFinalStatus(status) || status == StatusInterrupted {
+		s.crawler.finish(analyzeId, crawledPage{FinalURL: analysis.FinalURL})
+	}
+}
+
+// WatchAnalysis returns the current state of an analysis and a channel of its progress events. The
+// channel is closed after the final event, when ctx is done, or when the subscriber falls too far
+// behind. For an analysis that has already ended the returned channel is closed.
+func (s *webAnalyzerService) WatchAnalysis(ctx context.Context, analyzeId string) (*contract.WebAnalyzeResponse, <-chan contract.AnalysisEvent, error) {
+	// Subscribe before reading the snapshot so no event between the two is missed
+	sub := s.events.subscribe(analyzeId)
+
+	snapshot, err := s.GetAnalyzeData(ctx, analyzeId)
+	if err != nil {
+		s.events.unsubscribe(analyzeId, sub)
+		return nil, nil, err
+	}
+
+	if isFinalEvent(statusEvent(snapshot.Status)) {
+		s.events.unsubscribe(analyzeId, sub)
+		return snapshot, sub.events, nil
+	}
+
+	go func() {
+		<-ctx.Done()
+		s.events.unsubscribe(analyzeId, sub)
+	}()
+
+	return snapshot, sub.events, nil
+}
+
+func (s *webAnalyzerService) publishStatus(analyzeId string, status string, message string) {
+	if eventType := statusEvent(status); eventType != "" {
+		s.events.publish(contract.AnalysisEvent{Type: eventType, AnalyzeID: analyzeId, Status: status, Message: message})
+	}
+}
+
+func (s *webAnalyzerService) CancelAnalysis(ctx context.Context, analyzeId string) error {
+	analysis, err := s.repo.GetById(analyzeId)
+	if err != nil {
+		s.log.Error("Failed to get analysis data: " + err.Error())
+		return apperror.InternalServerError("Failed to get analysis data")
+	}
+
+	if analysis == nil {
+		s.log.Warn("Analysis not found for cancel: analyzeId - " + analyzeId)
+		return apperror.NotFound("Analysis result not found")
+	}
+
+	if isFinalStatus(analysis.Status) {
+		return apperror.Conflict("Analysis has already finished with status: " + analysis.Status)
+	}
+
+	if s.jobQueue.Remove(analyzeId) {
+		s.log.Info("Cancelled queued analysis: analyzeId - " + analyzeId)
+		s.UpdateAnalysisStatus(analyzeId, StatusCancelled, "Analysis was cancelled before it started.")
+		return nil
+	}
+
+	// The running job records the cancellation itself, keeping the partial results collected so far
+	if s.jobQueue.Interrupt(analyzeId, ErrAnalysisCancelled) {
+		s.log.Info("Cancelling running analysis: analyzeId - " + analyzeId)
+		return nil
+	}
+
+	// Neither waiting nor running: the job finished meanwhile or was orphaned
+	analysis, err = s.repo.GetById(analyzeId)
+	if err != nil {
+		s.log.Error("Failed to get analysis data: " + err.Error())
+		return apperror.InternalServerError("Failed to get analysis data")
+	}
+	if analysis == nil || isFinalStatus(analysis.Status) {
+		return apperror.Conflict("Analysis has already finished")
+	}
+
+	s.UpdateAnalysisStatus(analyzeId, StatusCancelled, "Analysis was cancelled.")
+	return nil
+}
+
+func isFinalStatus(status string) bool {
+	switch status {
+	case StatusSuccess, StatusFailed, StatusCancelled:
+		return true
+	default:
+		return false
+	}
 }
 
 func (s *webAnalyzerService) processAnalysisJob(ctx context.Context, analysisId string, baseURL *url.URL) {
+	var crawled crawledPage
+	defer func() { s.crawler.finish(analysisId, crawled) }()
+
 	s.log.Info("Starting background analysis for: " + baseURL.String())
 	analysis, err := s.repo.GetById(analysisId)
 	if err != nil {
@@ -136,78 +502,137 @@ func (s *webAnalyzerService) processAnalysisJob(ctx context.Context, analysisId
 		return
 	}
 
-	resp, err := http.Get(baseURL.String())
+	analysis.Status = StatusPending
+	if _, err = s.repo.Update(*analysis); err != nil {
+		s.log.Error("Failed to update analysis status: " + err.Error())
+	}
+	s.publishStatus(analysisId, StatusPending, "")
+
+	page, chain, err := s.pages.fetch(ctx, baseURL.String(), analysis.Options.UserAgent)
+	if isRedirectChain(chain) {
+		analysis.FinalURL = chain[len(chain)-1].URL
+		analysis.Redirects = chain
+		if _, err := s.repo.Update(*analysis); err != nil {
+			s.log.Error("Failed to update analysis redirects: " + err.Error())
+		}
+	}
 	if err != nil {
+		if ctx.Err() != nil {
+			status, reason := stoppedStatus(ctx)
+			s.log.Warn("Analysis stopped while fetching URL: " + baseURL.String())
+			s.UpdateAnalysisStatus(analysisId, status, reason+" before the page was fetched.")
+			return
+		}
 		s.log.Error("Failed to fetch URL: " + err.Error())
-		s.UpdateAnalysisStatus(analysisId, StatusFailed, "URL cannot be accessed. URL is invalid or unreachable.")
+		s.UpdateAnalysisStatus(analysisId, StatusFailed, s.pages.failureDescription(err))
 		return
 	}
-	defer resp.Body.Close()
+	s.events.publish(contract.AnalysisEvent{Type: EventFetched, AnalyzeID: analysisId, Status: StatusPending})
 
-	if resp.StatusCode != http.StatusOK {
-		s.log.Error("URL returned error status: " + strconv.Itoa(resp.StatusCode))
-		s.UpdateAnalysisStatus(analysisId, StatusFailed, "URL cannot be accessed. URL is invalid or unreachable.")
-		return
-	}
-
-	doc, err := html.Parse(resp.Body)
+	content, encoding := decodeHTML(page.Body, page.ContentType)
+	analysis.Encoding = encoding
+	doc, err := html.Parse(content)
 	if err != nil {
 		s.log.Error("Failed to parse HTML: " + err.Error())
 		s.UpdateAnalysisStatus(analysisId, StatusFailed, "Failed to parse HTML content.")
 		return
 	}
+	s.events.publish(contract.AnalysisEvent{Type: EventParsed, AnalyzeID: analysisId, Status: StatusPending})
 
 	//Start fill analysis data
 
+	// Links on a redirected page are relative to where it was finally served from
+	pageURL := baseURL
+	if analysis.FinalURL != "" {
+		if finalURL, err := url.Parse(analysis.FinalURL); err == nil {
+			pageURL = finalURL
+		}
+	}
+
 	// 1. Start link analysis from the parsed HTML document
-	analysis.Links = s.analyzeLinks(ctx, doc, baseURL)
+	analysis.Links = s.analyzeLinks(ctx, analysisId, doc, pageURL, analysis.Options)
 	// End link analysis from the parsed HTML document
 
 	// 2. Start metadata extraction from the parsed HTML document
-	s.analyzeMetadata(doc, analysis)
+	s.analyzeMetadata(doc, pageURL, analysis)
 	// End metadata extraction from the parsed HTML document
 
 	analysis.Status = StatusSuccess
+	if ctx.Err() != nil {
+		// Links checked before the job was stopped are kept as partial results
+		status, reason := stoppedStatus(ctx)
+		s.log.Warn("Analysis stopped during link checks: " + baseURL.String())
+		errorDescription := reason + " before all links were checked. Results are partial."
+		analysis.Status = status
+		analysis.ErrorDescription = &errorDescription
+	}
 	//End fill analysis data
 
 	_, err = s.repo.Update(*analysis)
 	if err != nil {
 		s.log.Error("Failed to update analysis result: " + err.Error())
 	}
+
+	crawled.FinalURL = analysis.FinalURL
+	if analysis.Status == StatusSuccess {
+		crawled.Links = crawlLinks(doc, pageURL)
+	}
+
+	var message string
+	if analysis.ErrorDescription != nil {
+		message = *analysis.ErrorDescription
+	}
+	s.publishStatus(analysisId, analysis.Status, message)
+	s.notifyWebhook(*analysis)
 	s.log.Info("Background analysis completed for: " + baseURL.String())
 }
 
-func (s *webAnalyzerService) analyzeMetadata(doc *html.Node, analysis *model.WebAnalyzer) {
+func (s *webAnalyzerService) analyzeMetadata(doc *html.Node, pageURL *url.URL, analysis *model.WebAnalyzer) {
 	analysis.HTMLVersion = htmlhelper.GetHTMLVersion(doc)
 	analysis.Title = htmlhelper.GetTitle(doc)
 	analysis.Headings = htmlhelper.GetHeadingsCount(doc)
 	analysis.HasLoginForm = htmlhelper.HasLoginForm(doc)
+	seo := analyzeSEO(doc, pageURL, analysis.Headings)
+	analysis.SEO = &seo
 }
 
-func (s *webAnalyzerService) analyzeLinks(ctx context.Context, doc *html.Node, baseURL *url.URL) model.LinkAnalysis {
-	links := htmlhelper.GetLinks(doc)
+func (s *webAnalyzerService) analyzeLinks(ctx context.Context, analysisId string, doc *html.Node, baseURL *url.URL, options model.AnalysisOptions) model.LinkAnalysis {
+	linkBaseURL := htmlhelper.GetBaseURL(doc, baseURL)
+	anchorLinks := htmlhelper.GetLinks(doc)
+	pageLinks := htmlhelper.CountLinks(anchorLinks, linkBaseURL)
+	pageResources := htmlhelper.CountResources(htmlhelper.GetResources(doc), linkBaseURL)
+
+	links := make([]string, len(pageResources))
+	kinds := make(map[string][]string, len(pageResources))
+	for i, resource := range pageResources {
+		links[i] = resource.URL
+		kinds[resource.URL] = resource.Kinds
+	}
+	linksToCheck := selectLinksToCheck(links, baseURL, options)
+	broken := map[string]bool{}
+	accessible := map[string]bool{}
+	skipped := map[string]bool{}
 
 	analysis := model.LinkAnalysis{
 		InaccessibleDetails: []model.InaccessibleLink{},
+		Redirected:          []model.LinkRedirect{},
+		SkippedRobots:       []string{},
 	}
 
-	linksChan := make(chan string, len(links))
-	resultsChan := make(chan model.LinkCheckResult, len(links))
+	linksChan := make(chan string, len(linksToCheck))
+	resultsChan := make(chan model.LinkCheckResult, len(linksToCheck))
 
-	numWorkers := 10
-	if len(links) < numWorkers {
-		numWorkers = len(links)
-	}
+	numWorkers := min(linkWorkers(options), len(linksToCheck))
 
 	wg := &sync.WaitGroup{}
 	//Start check links workers in background
 	for i := 0; i < numWorkers; i++ {
 		wg.Add(1)
-		go s.linkChecker.RunWorker(ctx, linksChan, resultsChan, baseURL, wg)
+		go s.linkChecker.RunWorker(ctx, linksChan, resultsChan, baseURL, options, wg)
 	}
 	//End check links workers in background
 
-	for _, link := range links {
+	for _, link := range linksToCheck {
 		linksChan <- link
 	}
 
@@ -218,21 +643,83 @@ func (s *webAnalyzerService) analyzeLinks(ctx context.Context, doc *html.Node, b
 		close(resultsChan)
 	}()
 
+	progress := contract.LinkProgress{Total: len(linksToCheck)}
+	s.events.publish(contract.AnalysisEvent{Type: EventLinksProgress, AnalyzeID: analysisId, Status: StatusPending, Progress: &progress})
+
 	for result := range resultsChan {
-		if !result.IsAccessible {
-			analysis.Inaccessible++
-			analysis.InaccessibleDetails = append(analysis.InaccessibleDetails, model.InaccessibleLink{
+		if result.SkippedRobots {
+			analysis.SkippedRobots = append(analysis.SkippedRobots, result.URL)
+			skipped[result.URL] = true
+		}
+		if result.Redirects != nil {
+			analysis.Redirected = append(analysis.Redirected, model.LinkRedirect{
 				URL:        result.URL,
+				FinalURL:   result.FinalURL,
 				StatusCode: result.StatusCode,
+				Chain:      result.Redirects,
+				Error:      result.RedirectError,
 			})
 		}
+		if result.IsAccessible {
+			accessible[result.URL] = true
+		}
+		if !result.IsAccessible && !result.SkippedRobots {
+			broken[result.URL] = true
+			s.addInaccessible(analysisId, &analysis, model.InaccessibleLink{
+				URL:        result.URL,
+				StatusCode: result.StatusCode,
+				Category:   result.ErrorCategory,
+				Message:    result.ErrorMessage,
+				Kinds:      kinds[result.URL],
+			})
+		}
+
+		progress.Checked++
+		current := progress
+		s.events.publish(contract.AnalysisEvent{Type: EventLinksProgress, AnalyzeID: analysisId, Status: StatusPending, Progress: &current})
 	}
 
-	for _, link := range links {
-		if htmlhelper.IsInternalLink(link, baseURL) {
+	if !options.SkipLinkCheck && ctx.Err() == nil {
+		fragmentLinks := collectFragmentLinks(anchorLinks, linkBaseURL, baseURL)
+		for _, link := range s.findMissingFragments(ctx, fragmentLinks, doc, baseURL, accessible, skipped, options) {
+			s.addInaccessible(analysisId, &analysis, model.InaccessibleLink{
+				URL:      link.URL,
+				Category: LinkErrorMissingFragment,
+				Message:  "anchor \"" + link.Fragment + "\" not found",
+				Kinds:    []string{htmlhelper.ResourceAnchor},
+			})
+		}
+	}
+
+	for _, link := range pageLinks {
+		if htmlhelper.IsInternalLink(link.URL, baseURL) {
 			analysis.Internal++
+			analysis.InternalOccurrences += link.Occurrences
 		} else {
 			analysis.External++
+			analysis.ExternalOccurrences += link.Occurrences
+		}
+	}
+
+	for _, resource := range pageResources {
+		if !isCheckableLink(resource.URL) {
+			continue
+		}
+		internal := htmlhelper.IsInternalLink(resource.URL, baseURL)
+		for _, kind := range resource.Kinds {
+			if analysis.Resources == nil {
+				analysis.Resources = map[string]model.ResourceStats{}
+			}
+			stats := analysis.Resources[kind]
+			if internal {
+				stats.Internal++
+			} else {
+				stats.External++
+			}
+			if broken[resource.URL] {
+				stats.Broken++
+			}
+			analysis.Resources[kind] = stats
 		}
 	}
 
@@ -2,14 +2,21 @@ package webanalyzer
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"net/http"
+	"net/http/httptest"
 	"net/url"
 	"sync"
 	"testing"
+	"time"
 
+	"web-analyzer-api/app/internal/contract"
 	core "web-analyzer-api/app/internal/core"
 	"web-analyzer-api/app/internal/core/apperror"
 	"web-analyzer-api/app/internal/model"
+	"web-analyzer-api/app/internal/repository"
+	"web-analyzer-api/app/internal/repositorymemory"
 	"web-analyzer-api/app/internal/util/logger"
 
 	"github.com/stretchr/testify/assert"
@@ -39,29 +46,64 @@ func (m *MockWebAnalyzerRepository) Update(analysis model.WebAnalyzer) (string,
 	return args.String(0), args.Error(1)
 }
 
+func (m *MockWebAnalyzerRepository) FindByStatus(statuses ...string) ([]model.WebAnalyzer, error) {
+	args := m.Called(statuses)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.WebAnalyzer), args.Error(1)
+}
+
+func (m *MockWebAnalyzerRepository) List(filter repository.ListFilter) (*repository.ListResult, error) {
+	args := m.Called(filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*repository.ListResult), args.Error(1)
+}
+
 // Mock LinkChecker
 type MockLinkChecker struct {
 	mock.Mock
 }
 
-func (m *MockLinkChecker) CheckLink(ctx context.Context, client *http.Client, link string, baseURL *url.URL) *model.LinkCheckResult {
-	args := m.Called(ctx, client, link, baseURL)
+func (m *MockLinkChecker) CheckLink(ctx context.Context, client *http.Client, link string, baseURL *url.URL, options model.AnalysisOptions) *model.LinkCheckResult {
+	args := m.Called(ctx, client, link, baseURL, options)
 	if args.Get(0) == nil {
 		return nil
 	}
 	return args.Get(0).(*model.LinkCheckResult)
 }
 
-func (m *MockLinkChecker) RunWorker(ctx context.Context, linksChan <-chan string, resultsChan chan<- model.LinkCheckResult, baseURL *url.URL, wg *sync.WaitGroup) {
-	m.Called(ctx, linksChan, resultsChan, baseURL, wg)
+func (m *MockLinkChecker) RunWorker(ctx context.Context, linksChan <-chan string, resultsChan chan<- model.LinkCheckResult, baseURL *url.URL, options model.AnalysisOptions, wg *sync.WaitGroup) {
+	m.Called(ctx, linksChan, resultsChan, baseURL, options, wg)
 	wg.Done()
 }
 
+func (m *MockLinkChecker) Acquire(ctx context.Context, target *url.URL, options model.AnalysisOptions) (func(), error) {
+	args := m.Called(ctx, target, options)
+	release, _ := args.Get(0).(func())
+	return release, args.Error(1)
+}
+
+func newTestWebhookDispatcher(log *logger.Logger) *WebhookDispatcher {
+	return NewWebhookDispatcher(log, repositorymemory.NewWebhookDeliveryRepo(log), newTestNetworkGuard(), WebhookConfig{})
+}
+
+// newTestNetworkGuard allows loopback addresses so that tests can reach httptest servers.
+func newTestNetworkGuard() *NetworkGuard {
+	return NewNetworkGuard(NetworkGuardConfig{AllowedHosts: []string{"127.0.0.1", "::1"}})
+}
+
+func newTestRobotsCache(log *logger.Logger) *RobotsCache {
+	return NewRobotsCache(log, newTestNetworkGuard(), RobotsConfig{})
+}
+
 func setupTest() (service core.WebAnalyzerService, repo *MockWebAnalyzerRepository, linkChecker *MockLinkChecker) {
 	log := logger.Get("info")
 	mockRepo := new(MockWebAnalyzerRepository)
 	mockLinkChecker := new(MockLinkChecker)
-	service = NewWebAnalyzerService(log, mockRepo, mockLinkChecker)
+	service = NewWebAnalyzerService(log, mockRepo, repositorymemory.NewBatchRepo(log), repositorymemory.NewCrawlRepo(log), mockLinkChecker, NewJobQueue(log, 1, 10), newTestWebhookDispatcher(log), newTestNetworkGuard(), PageFetchConfig{}, CrawlConfig{})
 	return service, mockRepo, mockLinkChecker
 }
 
@@ -142,12 +184,12 @@ func TestAnalyzeWebsite(t *testing.T) {
 
 	t.Run("Success Path", func(t *testing.T) {
 		mockRepo.On("Save", mock.MatchedBy(func(a model.WebAnalyzer) bool {
-			return a.URL == "http://test.com" && a.Status == StatusPending
+			return a.URL == "http://test.com" && a.Status == StatusQueued
 		})).Return("new-id", nil).Once()
 
 		mockRepo.On("GetById", "new-id").Return(nil, apperror.InternalServerError("stop background job")).Maybe()
 
-		id, err := service.AnalyzeWebsite(context.Background(), baseURL)
+		id, err := service.AnalyzeWebsite(context.Background(), baseURL, "", contract.AnalysisOptions{})
 
 		assert.NoError(t, err)
 		assert.Equal(t, "new-id", id)
@@ -157,10 +199,374 @@ func TestAnalyzeWebsite(t *testing.T) {
 	t.Run("Save Error", func(t *testing.T) {
 		mockRepo.On("Save", mock.Anything).Return("", apperror.BadRequest("save error")).Once()
 
-		id, err := service.AnalyzeWebsite(context.Background(), baseURL)
+		id, err := service.AnalyzeWebsite(context.Background(), baseURL, "", contract.AnalysisOptions{})
 
 		assert.Error(t, err)
 		assert.Empty(t, id)
 		mockRepo.AssertExpectations(t)
 	})
+
+	t.Run("Queue Full", func(t *testing.T) {
+		log := logger.Get("info")
+		fullRepo := new(MockWebAnalyzerRepository)
+		queue := NewJobQueue(log, 1, 1)
+		fullService := NewWebAnalyzerService(log, fullRepo, repositorymemory.NewBatchRepo(log), repositorymemory.NewCrawlRepo(log), new(MockLinkChecker), queue, newTestWebhookDispatcher(log), newTestNetworkGuard(), PageFetchConfig{}, CrawlConfig{})
+		assert.NoError(t, queue.Reserve())
+
+		id, err := fullService.AnalyzeWebsite(context.Background(), baseURL, "", contract.AnalysisOptions{})
+
+		assert.Empty(t, id)
+		var appErr *apperror.AppError
+		assert.ErrorAs(t, err, &appErr)
+		assert.Equal(t, http.StatusServiceUnavailable, appErr.StatusCode)
+		assert.Equal(t, queueFullRetryAfter, appErr.RetryAfter)
+		fullRepo.AssertNotCalled(t, "Save", mock.Anything)
+	})
+}
+
+func TestAnalyzeWebsite_NonHTTPLinks(t *testing.T) {
+	log := logger.Get("info")
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/" {
+			fmt.Fprint(w, `<html><body>
+				<a href="tel:+4930123456">Call</a><a href="sms:+4930123456?body=hi">Text</a><a href="TEL:+4930123456">Call</a>
+				<a href="/missing">Missing</a>
+			</body></html>`)
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer ts.Close()
+
+	service := NewWebAnalyzerService(log, repositorymemory.NewWebAnalyzerRepo(log), repositorymemory.NewBatchRepo(log), repositorymemory.NewCrawlRepo(log), NewLinkChecker(log, newTestNetworkGuard(), newTestRobotsCache(log), LinkCheckConfig{}), NewJobQueue(log, 1, 10), newTestWebhookDispatcher(log), newTestNetworkGuard(), PageFetchConfig{}, CrawlConfig{})
+	defer service.Shutdown(context.Background())
+
+	pageURL, _ := url.Parse(ts.URL + "/")
+	id, err := service.AnalyzeWebsite(context.Background(), pageURL, "", contract.AnalysisOptions{})
+	assert.NoError(t, err)
+
+	var result *contract.WebAnalyzeResponse
+	assert.Eventually(t, func() bool {
+		result, err = service.GetAnalyzeData(context.Background(), id)
+		return err == nil && result.Status == StatusSuccess
+	}, 5*time.Second, 20*time.Millisecond)
+
+	// tel: and sms: links are not requested, so they are not reported as broken
+	assert.Equal(t, 1, result.Links.Inaccessible)
+	assert.Equal(t, ts.URL+"/missing", result.Links.InaccessibleDetails[0].URL)
+}
+
+func TestAnalyzeWebsite_DuplicateLinks(t *testing.T) {
+	log := logger.Get("info")
+
+	requests := map[string]int{}
+	var mu sync.Mutex
+
+	var ts *httptest.Server
+	ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/" {
+			fmt.Fprintf(w, `<html><head><base href="/docs/"></head><body>
+				<a href="guide">Guide</a><a href="/docs/guide#intro">Intro</a><a href="%s/docs/%%67uide">Guide</a>
+				<a href="/missing">Missing</a><a href="../missing">Missing</a>
+				<a href="http://external.invalid/">External</a><a href="http://EXTERNAL.invalid:80">External</a>
+			</body></html>`, ts.URL)
+			return
+		}
+
+		mu.Lock()
+		requests[r.Method+" "+r.URL.Path]++
+		mu.Unlock()
+		switch r.URL.Path {
+		case "/missing":
+			w.WriteHeader(http.StatusNotFound)
+		case "/docs/guide":
+			fmt.Fprint(w, `<html><body><h2 id="intro">Intro</h2></body></html>`)
+		}
+	}))
+	defer ts.Close()
+
+	service := NewWebAnalyzerService(log, repositorymemory.NewWebAnalyzerRepo(log), repositorymemory.NewBatchRepo(log), repositorymemory.NewCrawlRepo(log), NewLinkChecker(log, newTestNetworkGuard(), newTestRobotsCache(log), LinkCheckConfig{}), NewJobQueue(log, 1, 10), newTestWebhookDispatcher(log), newTestNetworkGuard(), PageFetchConfig{}, CrawlConfig{})
+	defer service.Shutdown(context.Background())
+
+	pageURL, _ := url.Parse(ts.URL + "/")
+	excluded := false
+	id, err := service.AnalyzeWebsite(context.Background(), pageURL, "", contract.AnalysisOptions{IncludeExternalLinks: &excluded})
+	assert.NoError(t, err)
+
+	var result *contract.WebAnalyzeResponse
+	assert.Eventually(t, func() bool {
+		result, err = service.GetAnalyzeData(context.Background(), id)
+		return err == nil && result.Status == StatusSuccess
+	}, 5*time.Second, 20*time.Millisecond)
+
+	assert.Equal(t, 2, result.Links.Internal)
+	assert.Equal(t, 5, result.Links.InternalOccurrences)
+	assert.Equal(t, 1, result.Links.External)
+	assert.Equal(t, 2, result.Links.ExternalOccurrences)
+	assert.Equal(t, 1, result.Links.Inaccessible)
+	assert.Equal(t, ts.URL+"/missing", result.Links.InaccessibleDetails[0].URL)
+
+	mu.Lock()
+	defer mu.Unlock()
+	// Each unique link is checked once, the 404 with a HEAD and a GET, and the guide is fetched for its anchor
+	assert.Equal(t, map[string]int{"HEAD /docs/guide": 1, "GET /docs/guide": 1, "HEAD /missing": 1, "GET /missing": 1}, requests)
+}
+
+func TestAnalyzeWebsite_Resources(t *testing.T) {
+	log := logger.Get("info")
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/":
+			fmt.Fprint(w, `<html><head><link rel="stylesheet" href="/main.css"><script src="/missing.js"></script></head><body>
+				<a href="/logo.png">Logo</a><img src="/logo.png" srcset="/broken.png 2x, data:image/png;base64,AAAA 3x">
+				<iframe src="http://external.invalid/embed"></iframe><form action="/login"></form>
+			</body></html>`)
+		case "/missing.js", "/broken.png":
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	service := NewWebAnalyzerService(log, repositorymemory.NewWebAnalyzerRepo(log), repositorymemory.NewBatchRepo(log), repositorymemory.NewCrawlRepo(log), NewLinkChecker(log, newTestNetworkGuard(), newTestRobotsCache(log), LinkCheckConfig{}), NewJobQueue(log, 1, 10), newTestWebhookDispatcher(log), newTestNetworkGuard(), PageFetchConfig{}, CrawlConfig{})
+	defer service.Shutdown(context.Background())
+
+	pageURL, _ := url.Parse(ts.URL + "/")
+	excluded := false
+	id, err := service.AnalyzeWebsite(context.Background(), pageURL, "", contract.AnalysisOptions{IncludeExternalLinks: &excluded})
+	assert.NoError(t, err)
+
+	var result *contract.WebAnalyzeResponse
+	assert.Eventually(t, func() bool {
+		result, err = service.GetAnalyzeData(context.Background(), id)
+		return err == nil && result.Status == StatusSuccess
+	}, 5*time.Second, 20*time.Millisecond)
+
+	assert.Equal(t, 1, result.Links.Internal)
+	assert.Equal(t, map[string]contract.ResourceStats{
+		"anchor":     {Internal: 1},
+		"image":      {Internal: 2, Broken: 1},
+		"script":     {Internal: 1, Broken: 1},
+		"stylesheet": {Internal: 1},
+		"iframe":     {External: 1},
+		"form":       {Internal: 1},
+	}, result.Links.Resources)
+	assert.Equal(t, 2, result.Links.Inaccessible)
+	assert.ElementsMatch(t, []contract.InaccessibleLink{
+		{URL: ts.URL + "/missing.js", StatusCode: http.StatusNotFound, Category: LinkErrorHTTP4xx, Message: "404 Not Found", Kinds: []string{"script"}},
+		{URL: ts.URL + "/broken.png", StatusCode: http.StatusNotFound, Category: LinkErrorHTTP4xx, Message: "404 Not Found", Kinds: []string{"image"}},
+	}, result.Links.InaccessibleDetails)
+}
+
+func TestGetAnalyzeData_QueuePosition(t *testing.T) {
+	log := logger.Get("info")
+	mockRepo := new(MockWebAnalyzerRepository)
+	queue := NewJobQueue(log, 1, 10)
+	// Workers are intentionally not started so submitted jobs stay in the backlog
+	service := &webAnalyzerService{log: log, repo: mockRepo, linkChecker: new(MockLinkChecker), jobQueue: queue, events: newEventBroker(), webhooks: newTestWebhookDispatcher(log)}
+
+	baseURL, _ := url.Parse("http://test.com")
+	queue.Submit("first-id", baseURL)
+	queue.Submit("second-id", baseURL)
+
+	mockRepo.On("GetById", "second-id").Return(&model.WebAnalyzer{ID: "second-id", Status: StatusQueued}, nil).Once()
+
+	resp, err := service.GetAnalyzeData(context.Background(), "second-id")
+
+	assert.NoError(t, err)
+	assert.Equal(t, StatusQueued, resp.Status)
+	assert.Equal(t, 2, resp.QueuePosition)
+}
+
+func TestCancelAnalysis(t *testing.T) {
+	log := logger.Get("info")
+	baseURL, _ := url.Parse("http://test.com")
+
+	setupCancelTest := func() (*webAnalyzerService, *MockWebAnalyzerRepository) {
+		mockRepo := new(MockWebAnalyzerRepository)
+		// Workers are intentionally not started so submitted jobs stay in the backlog
+		service := &webAnalyzerService{log: log, repo: mockRepo, linkChecker: new(MockLinkChecker), jobQueue: NewJobQueue(log, 1, 10), events: newEventBroker(), webhooks: newTestWebhookDispatcher(log)}
+		return service, mockRepo
+	}
+
+	t.Run("Queued analysis", func(t *testing.T) {
+		service, mockRepo := setupCancelTest()
+		service.jobQueue.Submit("queued-id", baseURL)
+
+		mockRepo.On("GetById", "queued-id").Return(&model.WebAnalyzer{ID: "queued-id", Status: StatusQueued}, nil)
+		mockRepo.On("Update", mock.MatchedBy(func(a model.WebAnalyzer) bool {
+			return a.Status == StatusCancelled
+		})).Return("queued-id", nil).Once()
+
+		err := service.CancelAnalysis(context.Background(), "queued-id")
+
+		assert.NoError(t, err)
+		assert.Equal(t, 0, service.jobQueue.Len())
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Orphaned analysis", func(t *testing.T) {
+		service, mockRepo := setupCancelTest()
+
+		mockRepo.On("GetById", "orphan-id").Return(&model.WebAnalyzer{ID: "orphan-id", Status: StatusPending}, nil)
+		mockRepo.On("Update", mock.MatchedBy(func(a model.WebAnalyzer) bool {
+			return a.Status == StatusCancelled
+		})).Return("orphan-id", nil).Once()
+
+		err := service.CancelAnalysis(context.Background(), "orphan-id")
+
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Finished analysis", func(t *testing.T) {
+		service, mockRepo := setupCancelTest()
+		mockRepo.On("GetById", "done-id").Return(&model.WebAnalyzer{ID: "done-id", Status: StatusSuccess}, nil).Once()
+
+		err := service.CancelAnalysis(context.Background(), "done-id")
+
+		var appErr *apperror.AppError
+		assert.ErrorAs(t, err, &appErr)
+		assert.Equal(t, http.StatusConflict, appErr.StatusCode)
+		mockRepo.AssertNotCalled(t, "Update", mock.Anything)
+	})
+
+	t.Run("Not Found", func(t *testing.T) {
+		service, mockRepo := setupCancelTest()
+		mockRepo.On("GetById", "missing-id").Return(nil, nil).Once()
+
+		err := service.CancelAnalysis(context.Background(), "missing-id")
+
+		var appErr *apperror.AppError
+		assert.ErrorAs(t, err, &appErr)
+		assert.Equal(t, http.StatusNotFound, appErr.StatusCode)
+	})
+
+	t.Run("Running analysis keeps partial results", func(t *testing.T) {
+		slowReached := make(chan struct{})
+		var slowOnce sync.Once
+
+		var ts *httptest.Server
+		ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/":
+				fmt.Fprintf(w, `<html><head><title>Cancel</title></head><body><a href="%s/broken">broken</a><a href="%s/slow">slow</a></body></html>`, ts.URL, ts.URL)
+			case "/broken":
+				w.WriteHeader(http.StatusNotFound)
+			case "/slow":
+				slowOnce.Do(func() { close(slowReached) })
+				<-r.Context().Done()
+			}
+		}))
+		defer ts.Close()
+
+		repo := repositorymemory.NewWebAnalyzerRepo(log)
+		service := NewWebAnalyzerService(log, repo, repositorymemory.NewBatchRepo(log), repositorymemory.NewCrawlRepo(log), NewLinkChecker(log, newTestNetworkGuard(), newTestRobotsCache(log), LinkCheckConfig{}), NewJobQueue(log, 1, 10), newTestWebhookDispatcher(log), newTestNetworkGuard(), PageFetchConfig{}, CrawlConfig{})
+		pageURL, _ := url.Parse(ts.URL + "/")
+
+		id, err := service.AnalyzeWebsite(context.Background(), pageURL, "", contract.AnalysisOptions{})
+		assert.NoError(t, err)
+
+		<-slowReached
+		// Give the broken link check time to report before cancelling
+		time.Sleep(200 * time.Millisecond)
+
+		assert.NoError(t, service.CancelAnalysis(context.Background(), id))
+
+		var result *contract.WebAnalyzeResponse
+		assert.Eventually(t, func() bool {
+			result, err = service.GetAnalyzeData(context.Background(), id)
+			return err == nil && result.Status == StatusCancelled
+		}, 5*time.Second, 20*time.Millisecond)
+
+		assert.Equal(t, "Cancel", result.Title)
+		assert.Equal(t, 1, result.Links.Inaccessible)
+		assert.Equal(t, ts.URL+"/broken", result.Links.InaccessibleDetails[0].URL)
+		assert.Equal(t, LinkErrorHTTP4xx, result.Links.InaccessibleDetails[0].Category)
+		// The cancelled check of the slow link is not reported as a failure
+		assert.Equal(t, map[string]int{LinkErrorHTTP4xx: 1}, result.Links.ErrorCategories)
+		assert.NotEmpty(t, result.ErrorDescription)
+
+		err = service.CancelAnalysis(context.Background(), id)
+		var appErr *apperror.AppError
+		assert.ErrorAs(t, err, &appErr)
+		assert.Equal(t, http.StatusConflict, appErr.StatusCode)
+	})
+}
+
+func TestListAnalyses(t *testing.T) {
+	log := logger.Get("info")
+
+	t.Run("Applies defaults and maps items", func(t *testing.T) {
+		mockRepo := new(MockWebAnalyzerRepository)
+		queue := NewJobQueue(log, 1, 10)
+		service := &webAnalyzerService{log: log, repo: mockRepo, linkChecker: new(MockLinkChecker), jobQueue: queue, events: newEventBroker(), webhooks: newTestWebhookDispatcher(log)}
+
+		baseURL, _ := url.Parse("http://test.com")
+		queue.Submit("queued-id", baseURL)
+
+		createdAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		expectedFilter := repository.ListFilter{SortBy: repository.SortByCreatedAt, SortDesc: true, Limit: DefaultListLimit}
+		mockRepo.On("List", expectedFilter).Return(&repository.ListResult{
+			Items: []model.WebAnalyzer{
+				{ID: "queued-id", URL: "http://test.com", Status: StatusQueued, CreatedAt: createdAt},
+				{ID: "done-id", URL: "http://test.com", Status: StatusSuccess, CreatedAt: createdAt},
+			},
+			NextCursor: "next",
+		}, nil)
+
+		resp, err := service.ListAnalyses(context.Background(), contract.ListAnalysesRequest{})
+
+		assert.NoError(t, err)
+		assert.Len(t, resp.Items, 2)
+		assert.Equal(t, "queued-id", resp.Items[0].AnalyzeID)
+		assert.Equal(t, 1, resp.Items[0].QueuePosition)
+		assert.Equal(t, createdAt, *resp.Items[0].CreatedAt)
+		assert.Equal(t, 0, resp.Items[1].QueuePosition)
+		assert.Equal(t, "next", resp.NextCursor)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Caps limit", func(t *testing.T) {
+		mockRepo := new(MockWebAnalyzerRepository)
+		service := &webAnalyzerService{log: log, repo: mockRepo, linkChecker: new(MockLinkChecker), jobQueue: NewJobQueue(log, 1, 10), events: newEventBroker(), webhooks: newTestWebhookDispatcher(log)}
+
+		mockRepo.On("List", mock.MatchedBy(func(f repository.ListFilter) bool {
+			return f.Limit == MaxListLimit && f.SortBy == repository.SortByUpdatedAt && !f.SortDesc
+		})).Return(&repository.ListResult{}, nil)
+
+		resp, err := service.ListAnalyses(context.Background(), contract.ListAnalysesRequest{Sort: repository.SortByUpdatedAt, Order: SortOrderAsc, Limit: 1000})
+
+		assert.NoError(t, err)
+		assert.Empty(t, resp.Items)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Invalid cursor", func(t *testing.T) {
+		mockRepo := new(MockWebAnalyzerRepository)
+		service := &webAnalyzerService{log: log, repo: mockRepo, linkChecker: new(MockLinkChecker), jobQueue: NewJobQueue(log, 1, 10), events: newEventBroker(), webhooks: newTestWebhookDispatcher(log)}
+
+		mockRepo.On("List", mock.Anything).Return(nil, repository.ErrInvalidCursor)
+
+		resp, err := service.ListAnalyses(context.Background(), contract.ListAnalysesRequest{Cursor: "bad"})
+
+		assert.Nil(t, resp)
+		var appErr *apperror.AppError
+		assert.ErrorAs(t, err, &appErr)
+		assert.Equal(t, http.StatusBadRequest, appErr.StatusCode)
+	})
+
+	t.Run("Repository error", func(t *testing.T) {
+		mockRepo := new(MockWebAnalyzerRepository)
+		service := &webAnalyzerService{log: log, repo: mockRepo, linkChecker: new(MockLinkChecker), jobQueue: NewJobQueue(log, 1, 10), events: newEventBroker(), webhooks: newTestWebhookDispatcher(log)}
+
+		mockRepo.On("List", mock.Anything).Return(nil, errors.New("db error"))
+
+		_, err := service.ListAnalyses(context.Background(), contract.ListAnalysesRequest{})
+
+		var appErr *apperror.AppError
+		assert.ErrorAs(t, err, &appErr)
+		assert.Equal(t, http.StatusInternalServerError, appErr.StatusCode)
+	})
 }
@@ -0,0 +1,65 @@
+package webanalyzer
+
+import (
+	"context"
+	"web-analyzer-api/app/internal/contract"
+	"web-analyzer-api/app/internal/core/apperror"
+	"web-analyzer-api/app/internal/model"
+)
+
+// notifyWebhook sends the result of a successful or failed analysis to its callback URL, if it has one.
+func (s *webAnalyzerService) notifyWebhook(analysis model.WebAnalyzer) {
+	if analysis.CallbackURL == "" {
+		return
+	}
+
+	var event string
+	switch analysis.Status {
+	case StatusSuccess:
+		event = WebhookEventCompleted
+	case StatusFailed:
+		event = WebhookEventFailed
+	default:
+		return
+	}
+
+	s.webhooks.Dispatch(analysis.ID, analysis.CallbackURL, event, toWebAnalyzeResponse(analysis))
+}
+
+func (s *webAnalyzerService) GetWebhookDeliveries(ctx context.Context, analyzeId string) (*contract.WebhookDeliveriesResponse, error) {
+	analysis, err := s.repo.GetById(analyzeId)
+	if err != nil {
+		s.log.Error("Failed to get analysis data: " + err.Error())
+		return nil, apperror.InternalServerError("Failed to get analysis data")
+	}
+
+	if analysis == nil {
+		return nil, apperror.NotFound("Analysis result not found")
+	}
+
+	deliveries, err := s.webhooks.Deliveries(analyzeId)
+	if err != nil {
+		s.log.Error("Failed to get webhook deliveries: " + err.Error())
+		return nil, apperror.InternalServerError("Failed to get webhook deliveries")
+	}
+
+	response := contract.WebhookDeliveriesResponse{
+		AnalyzeID:   analyzeId,
+		CallbackURL: analysis.CallbackURL,
+		Deliveries:  make([]contract.WebhookDelivery, len(deliveries)),
+	}
+	for i, delivery := range deliveries {
+		response.Deliveries[i] = contract.WebhookDelivery{
+			ID:         delivery.ID,
+			Event:      delivery.Event,
+			Attempt:    delivery.Attempt,
+			StatusCode: delivery.StatusCode,
+			Success:    delivery.Success,
+			Error:      delivery.Error,
+			DurationMs: delivery.Duration.Milliseconds(),
+			CreatedAt:  delivery.CreatedAt,
+		}
+	}
+
+	return &response, nil
+}
@@ -7,32 +7,39 @@ import (
 	"strconv"
 	"strings"
 	"sync"
-	"time"
 	"web-analyzer-api/app/internal/core"
 	"web-analyzer-api/app/internal/model"
+	htmlhelper "web-analyzer-api/app/internal/util/html"
 	"web-analyzer-api/app/internal/util/logger"
 )
 
 type linkChecker struct {
-	log *logger.Logger
+	log     *logger.Logger
+	guard   *NetworkGuard
+	robots  *RobotsCache
+	pacer   *hostPacer
+	limiter *hostLimiter
+	cache   *linkResultCache
 }
 
-func NewLinkChecker(log *logger.Logger) core.LinkChecker {
+func NewLinkChecker(log *logger.Logger, guard *NetworkGuard, robots *RobotsCache, config LinkCheckConfig) core.LinkChecker {
+	config = config.withDefaults()
+
 	return &linkChecker{
-		log: log,
+		log:     log,
+		guard:   guard,
+		robots:  robots,
+		pacer:   newHostPacer(),
+		limiter: newHostLimiter(config),
+		cache:   newLinkResultCache(config.CacheSize, config.CacheSuccessTTL, config.CacheFailureTTL),
 	}
 }
 
-func (lc *linkChecker) RunWorker(ctx context.Context, linksChan <-chan string, resultsChan chan<- model.LinkCheckResult, baseURL *url.URL, wg *sync.WaitGroup) {
+func (lc *linkChecker) RunWorker(ctx context.Context, linksChan <-chan string, resultsChan chan<- model.LinkCheckResult, baseURL *url.URL, options model.AnalysisOptions, wg *sync.WaitGroup) {
 	defer wg.Done()
 	defer lc.log.Info("Link check worker stopped")
 
-	client := &http.Client{
-		Timeout: 10 * time.Second,
-		CheckRedirect: func(req *http.Request, via []*http.Request) error {
-			return http.ErrUseLastResponse
-		},
-	}
+	client := newLinkCheckClient(options, lc.guard.Transport())
 
 	for {
 		select {
@@ -43,7 +50,7 @@ func (lc *linkChecker) RunWorker(ctx context.Context, linksChan <-chan string, r
 				return
 			}
 
-			result := lc.CheckLink(ctx, client, link, baseURL)
+			result := lc.CheckLink(ctx, client, link, baseURL, options)
 			if result != nil {
 				select {
 				case resultsChan <- *result:
@@ -55,8 +62,8 @@ func (lc *linkChecker) RunWorker(ctx context.Context, linksChan <-chan string, r
 	}
 }
 
-func (lc *linkChecker) CheckLink(ctx context.Context, client *http.Client, link string, baseURL *url.URL) *model.LinkCheckResult {
-	if link == "" || strings.HasPrefix(link, "#") || strings.HasPrefix(link, "javascript:") || strings.HasPrefix(link, "mailto:") {
+func (lc *linkChecker) CheckLink(ctx context.Context, client *http.Client, link string, baseURL *url.URL, options model.AnalysisOptions) *model.LinkCheckResult {
+	if !isCheckableLink(link) {
 		lc.log.Warn("Invalid link: " + link)
 		return nil
 	}
@@ -71,51 +78,238 @@ func (lc *linkChecker) CheckLink(ctx context.Context, client *http.Client, link
 		absoluteURL = baseURL.ResolveReference(parsedLink).String()
 	}
 
-	req, err := http.NewRequestWithContext(ctx, "HEAD", absoluteURL, nil)
+	parsedURL, err := url.Parse(absoluteURL)
 	if err != nil {
 		lc.log.Warn("Invalid link: " + link)
 		return nil
 	}
 
-	resp, err := client.Do(req)
-	needsFallback := err != nil || (resp != nil && resp.StatusCode >= 400)
-
-	if needsFallback {
-		if resp != nil {
-			resp.Body.Close()
+	var rules *robotsRules
+	if options.RespectRobots {
+		rules, err = lc.robots.Rules(ctx, parsedURL, options.UserAgent)
+		if err != nil {
+			lc.log.Debug("Link check cancelled: " + absoluteURL)
+			return nil
 		}
+		if !rules.Allowed(parsedURL) {
+			lc.log.Debug("Link disallowed by robots.txt: " + absoluteURL)
+			return &model.LinkCheckResult{URL: absoluteURL, SkippedRobots: true}
+		}
+	}
+
+	if ctx.Err() != nil {
+		lc.log.Debug("Link check cancelled: " + absoluteURL)
+		return nil
+	}
+
+	normalizedURL, _ := htmlhelper.NormalizeLink(absoluteURL, baseURL)
+	cacheKey := linkCacheKey(normalizedURL, options)
+	if cached, ok := lc.cache.Get(cacheKey); ok {
+		lc.log.Debug("Cached link result: " + absoluteURL)
+		cached.URL = absoluteURL
+		return &cached
+	}
+
+	if err := lc.pacer.wait(ctx, parsedURL.Host, rules.CrawlDelay()); err != nil {
+		lc.log.Debug("Link check cancelled: " + absoluteURL)
+		return nil
+	}
+
+	result := lc.check(ctx, client, link, absoluteURL, options)
+	if result != nil {
+		lc.cache.Put(cacheKey, *result)
+	}
+	return result
+}
 
-		lc.log.Debug("HEAD failed or returned error, trying GET: " + absoluteURL)
-		req, err = http.NewRequestWithContext(ctx, "GET", absoluteURL, nil)
+// Acquire waits until target may be requested under the robots.txt rules and host limits that link checks
+// follow. It returns ErrDisallowedByRobots when robots.txt disallows target, and otherwise a function that must
+// be called once the request is done.
+func (lc *linkChecker) Acquire(ctx context.Context, target *url.URL, options model.AnalysisOptions) (func(), error) {
+	var rules *robotsRules
+	if options.RespectRobots {
+		var err error
+		rules, err = lc.robots.Rules(ctx, target, options.UserAgent)
 		if err != nil {
-			lc.log.Debug("Failed to create GET request: " + err.Error())
+			return nil, err
+		}
+		if !rules.Allowed(target) {
+			return nil, ErrDisallowedByRobots
+		}
+	}
+
+	if err := lc.pacer.wait(ctx, target.Host, rules.CrawlDelay()); err != nil {
+		return nil, err
+	}
+	return lc.limiter.acquire(ctx, target.Host)
+}
+
+// check requests absoluteURL and turns the response into a result. It returns nil when ctx is done first.
+func (lc *linkChecker) check(ctx context.Context, client *http.Client, link string, absoluteURL string, options model.AnalysisOptions) *model.LinkCheckResult {
+	resp, chain, retries, err := lc.fetchWithRetries(ctx, client, absoluteURL, options)
+
+	result := &model.LinkCheckResult{URL: absoluteURL}
+	if !options.SkipRedirects && isRedirectChain(chain) {
+		result.FinalURL = chain[len(chain)-1].URL
+		result.Redirects = chain
+	}
+
+	if err != nil {
+		if ctx.Err() != nil {
+			// A cancelled check says nothing about the link itself
+			lc.log.Debug("Link check cancelled: " + absoluteURL)
 			return nil
 		}
-		resp, err = client.Do(req)
-		if err != nil {
-			lc.log.Debug("Inaccessible link (GET failed): " + absoluteURL)
-			return &model.LinkCheckResult{
-				URL:          absoluteURL,
-				StatusCode:   0,
-				IsAccessible: false,
-			}
+
+		result.RedirectError = redirectErrorCode(err)
+		if result.RedirectError != "" {
+			result.StatusCode = chain[len(chain)-1].StatusCode
 		}
+		result.ErrorCategory = classifyLinkError(err)
+		result.ErrorMessage = err.Error()
+		lc.log.Debug("Inaccessible link (" + result.ErrorCategory + "): " + absoluteURL + ": " + result.ErrorMessage)
+		return result
 	}
 	defer resp.Body.Close()
 
+	result.StatusCode = resp.StatusCode
 	if resp.StatusCode >= 400 {
-		lc.log.Debug("Inaccessible link: " + link + " with status code: " + strconv.Itoa(resp.StatusCode))
-		return &model.LinkCheckResult{
-			URL:          absoluteURL,
-			StatusCode:   resp.StatusCode,
-			IsAccessible: false,
+		result.ErrorCategory = classifyStatus(resp.StatusCode)
+		result.ErrorMessage = statusMessage(resp.StatusCode)
+		if retries > 0 {
+			result.ErrorMessage += " after " + strconv.Itoa(retries) + " retries"
 		}
+		lc.log.Debug("Inaccessible link: " + link + " with status code: " + strconv.Itoa(resp.StatusCode))
+		return result
 	}
 
 	lc.log.Debug("Accessible link: " + link + " with status code: " + strconv.Itoa(resp.StatusCode))
-	return &model.LinkCheckResult{
-		URL:          absoluteURL,
-		StatusCode:   resp.StatusCode,
-		IsAccessible: true,
+	result.IsAccessible = true
+	return result
+}
+
+// fetchWithRetries follows the redirects of target and retries it while it answers 429 or 503, waiting
+// as long as the Retry-After header asks for. It returns the number of retries made.
+func (lc *linkChecker) fetchWithRetries(ctx context.Context, client *http.Client, target string, options model.AnalysisOptions) (*http.Response, []model.RedirectHop, int, error) {
+	for attempt := 0; ; attempt++ {
+		resp, chain, err := followRedirects(target, options.SkipRedirects, func(target string) (*http.Response, error) {
+			return lc.request(ctx, client, target)
+		})
+		if err != nil || attempt >= lc.limiter.config.MaxRetries || !isRetryableStatus(resp.StatusCode) {
+			return resp, chain, attempt, err
+		}
+
+		delay, ok := retryDelay(resp, attempt, lc.limiter.config.MaxRetryAfter)
+		if !ok {
+			return resp, chain, attempt, nil
+		}
+		resp.Body.Close()
+
+		lc.log.Debug("Link returned status code " + strconv.Itoa(resp.StatusCode) + ", retrying in " + delay.String() + ": " + target)
+		if err := sleepContext(ctx, delay); err != nil {
+			return nil, chain, attempt, err
+		}
+	}
+}
+
+// request sends a HEAD request for a single URL and falls back to GET when HEAD fails or is rejected. A
+// request to come back later is returned as is rather than repeated with GET.
+func (lc *linkChecker) request(ctx context.Context, client *http.Client, target string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, target, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := lc.do(client, req)
+	if err == nil && (resp.StatusCode < 400 || isRetryableStatus(resp.StatusCode)) {
+		return resp, nil
+	}
+	if resp != nil {
+		resp.Body.Close()
+	}
+
+	lc.log.Debug("HEAD failed or returned error, trying GET: " + target)
+	req, err = http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return nil, err
+	}
+	return lc.do(client, req)
+}
+
+// do sends req once the host limits allow it. Waiting for the limits does not count towards the client
+// timeout.
+func (lc *linkChecker) do(client *http.Client, req *http.Request) (*http.Response, error) {
+	release, err := lc.limiter.acquire(req.Context(), req.URL.Host)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	return client.Do(req)
+}
+
+// newLinkCheckClient builds the HTTP client used by a link check worker. The client never follows
+// redirects itself so that CheckLink can record every hop.
+func newLinkCheckClient(options model.AnalysisOptions, transport http.RoundTripper) *http.Client {
+	client := &http.Client{
+		Transport: transport,
+		Timeout:   linkTimeout(options),
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	if options.UserAgent != "" {
+		client.Transport = &userAgentTransport{base: transport, userAgent: options.UserAgent}
+	}
+
+	return client
+}
+
+// userAgentTransport sets the User-Agent header on every request.
+type userAgentTransport struct {
+	base      http.RoundTripper
+	userAgent string
+}
+
+func (t *userAgentTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Header.Set("User-Agent", t.userAgent)
+	return t.base.RoundTrip(req)
+}
+
+// isCheckableLink reports whether a link points to a resource that can be requested. Only relative links and
+// http(s) links are, so links such as tel: and sms: are never checked.
+func isCheckableLink(link string) bool {
+	if link == "" || strings.HasPrefix(link, "#") {
+		return false
+	}
+	parsedLink, err := url.Parse(link)
+	if err != nil {
+		return false
+	}
+	return parsedLink.Scheme == "" || parsedLink.Scheme == "http" || parsedLink.Scheme == "https"
+}
+
+// selectLinksToCheck returns the checkable links, leaving out external links when they are excluded and
+// stopping at the requested maximum.
+func selectLinksToCheck(links []string, baseURL *url.URL, options model.AnalysisOptions) []string {
+	if options.SkipLinkCheck {
+		return nil
+	}
+
+	selected := []string{}
+	for _, link := range links {
+		if options.MaxLinks > 0 && len(selected) >= options.MaxLinks {
+			break
+		}
+		if !isCheckableLink(link) {
+			continue
+		}
+		if options.SkipExternalLinks && !htmlhelper.IsInternalLink(link, baseURL) {
+			continue
+		}
+		selected = append(selected, link)
 	}
+	return selected
 }
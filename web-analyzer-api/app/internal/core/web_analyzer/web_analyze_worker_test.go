@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"strconv"
 	"sync"
 	"testing"
 	"time"
@@ -17,13 +18,13 @@ import (
 
 func TestNewLinkChecker(t *testing.T) {
 	log := logger.Get("debug")
-	lc := NewLinkChecker(log)
+	lc := NewLinkChecker(log, newTestNetworkGuard(), newTestRobotsCache(log), LinkCheckConfig{})
 	assert.NotNil(t, lc)
 }
 
 func setupBaseURL() (*url.URL, core.LinkChecker) {
 	log := logger.Get("debug")
-	lc := NewLinkChecker(log)
+	lc := NewLinkChecker(log, newTestNetworkGuard(), newTestRobotsCache(log), LinkCheckConfig{})
 	baseURL, _ := url.Parse("http://base.com")
 	return baseURL, lc
 }
@@ -33,10 +34,12 @@ func TestCheckLink(t *testing.T) {
 	client := http.DefaultClient
 
 	t.Run("Invalid link patterns", func(t *testing.T) {
-		assert.Nil(t, lc.CheckLink(context.Background(), client, "", baseURL))
-		assert.Nil(t, lc.CheckLink(context.Background(), client, "#", baseURL))
-		assert.Nil(t, lc.CheckLink(context.Background(), client, "mailto:test@test.com", baseURL))
-		assert.Nil(t, lc.CheckLink(context.Background(), client, "javascript:void(0)", baseURL))
+		assert.Nil(t, lc.CheckLink(context.Background(), client, "", baseURL, model.AnalysisOptions{}))
+		assert.Nil(t, lc.CheckLink(context.Background(), client, "#", baseURL, model.AnalysisOptions{}))
+		assert.Nil(t, lc.CheckLink(context.Background(), client, "mailto:test@test.com", baseURL, model.AnalysisOptions{}))
+		assert.Nil(t, lc.CheckLink(context.Background(), client, "javascript:void(0)", baseURL, model.AnalysisOptions{}))
+		assert.Nil(t, lc.CheckLink(context.Background(), client, "tel:+4930123456", baseURL, model.AnalysisOptions{}))
+		assert.Nil(t, lc.CheckLink(context.Background(), client, "sms:+4930123456?body=hi", baseURL, model.AnalysisOptions{}))
 	})
 
 	t.Run("Relative link resolution", func(t *testing.T) {
@@ -47,7 +50,7 @@ func TestCheckLink(t *testing.T) {
 		defer ts.Close()
 
 		tsURL, _ := url.Parse(ts.URL)
-		result := lc.CheckLink(context.Background(), client, "/about", tsURL)
+		result := lc.CheckLink(context.Background(), client, "/about", tsURL, model.AnalysisOptions{})
 		assert.NotNil(t, result)
 		assert.Equal(t, ts.URL+"/about", result.URL)
 		assert.True(t, result.IsAccessible)
@@ -60,7 +63,7 @@ func TestCheckLink(t *testing.T) {
 		}))
 		defer ts.Close()
 
-		result := lc.CheckLink(context.Background(), client, ts.URL, baseURL)
+		result := lc.CheckLink(context.Background(), client, ts.URL, baseURL, model.AnalysisOptions{})
 		assert.True(t, result.IsAccessible)
 		assert.Equal(t, http.StatusOK, result.StatusCode)
 	})
@@ -78,7 +81,7 @@ func TestCheckLink(t *testing.T) {
 		}))
 		defer ts.Close()
 
-		result := lc.CheckLink(context.Background(), client, ts.URL, baseURL)
+		result := lc.CheckLink(context.Background(), client, ts.URL, baseURL, model.AnalysisOptions{})
 		assert.True(t, result.IsAccessible)
 		assert.Equal(t, http.StatusOK, result.StatusCode)
 		assert.Equal(t, 2, callCount)
@@ -90,47 +93,95 @@ func TestCheckLink(t *testing.T) {
 		}))
 		defer ts.Close()
 
-		result := lc.CheckLink(context.Background(), client, ts.URL, baseURL)
+		result := lc.CheckLink(context.Background(), client, ts.URL, baseURL, model.AnalysisOptions{})
 		assert.False(t, result.IsAccessible)
 		assert.Equal(t, http.StatusNotFound, result.StatusCode)
 	})
 
 	t.Run("Invalid parsing", func(t *testing.T) {
-		result := lc.CheckLink(context.Background(), client, "http://[fe80::%31]/", baseURL)
+		result := lc.CheckLink(context.Background(), client, "http://[fe80::%31]/", baseURL, model.AnalysisOptions{})
 		assert.Nil(t, result)
 	})
 
 	t.Run("Both HEAD and GET fail with network error", func(t *testing.T) {
-		result := lc.CheckLink(context.Background(), client, "http://localhost:1", baseURL)
+		result := lc.CheckLink(context.Background(), client, "http://localhost:1", baseURL, model.AnalysisOptions{})
 		assert.False(t, result.IsAccessible)
 		assert.Equal(t, 0, result.StatusCode)
 	})
 
+	t.Run("Cancelled context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		result := lc.CheckLink(ctx, client, "http://localhost:1", baseURL, model.AnalysisOptions{})
+		assert.Nil(t, result)
+	})
+
 	t.Run("Redirect handling", func(t *testing.T) {
 		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			if r.URL.Path == "/redirect" {
+			switch r.URL.Path {
+			case "/redirect":
+				http.Redirect(w, r, "/moved", http.StatusMovedPermanently)
+			case "/moved":
 				http.Redirect(w, r, "/target", http.StatusFound)
-				return
+			case "/broken":
+				http.Redirect(w, r, "/missing", http.StatusMovedPermanently)
+			case "/missing":
+				w.WriteHeader(http.StatusNotFound)
+			case "/loop":
+				http.Redirect(w, r, "/loop-back", http.StatusFound)
+			case "/loop-back":
+				http.Redirect(w, r, "/loop", http.StatusFound)
+			case "/endless":
+				next, _ := strconv.Atoi(r.URL.Query().Get("n"))
+				http.Redirect(w, r, "/endless?n="+strconv.Itoa(next+1), http.StatusFound)
+			default:
+				w.WriteHeader(http.StatusOK)
 			}
-			w.WriteHeader(http.StatusOK)
 		}))
 		defer ts.Close()
 
 		tsURL, _ := url.Parse(ts.URL)
+		client := newLinkCheckClient(model.AnalysisOptions{}, newTestNetworkGuard().Transport())
 
-		client := &http.Client{
-			CheckRedirect: func(req *http.Request, via []*http.Request) error {
-				return http.ErrUseLastResponse
-			},
-		}
-
-		result := lc.CheckLink(context.Background(), client, "/redirect", tsURL)
+		result := lc.CheckLink(context.Background(), client, "/redirect", tsURL, model.AnalysisOptions{})
 		assert.True(t, result.IsAccessible)
+		assert.Equal(t, http.StatusOK, result.StatusCode)
+		assert.Equal(t, ts.URL+"/target", result.FinalURL)
+		assert.Equal(t, []model.RedirectHop{
+			{URL: ts.URL + "/redirect", StatusCode: http.StatusMovedPermanently},
+			{URL: ts.URL + "/moved", StatusCode: http.StatusFound},
+			{URL: ts.URL + "/target", StatusCode: http.StatusOK},
+		}, result.Redirects)
+		assert.Empty(t, result.RedirectError)
+
+		// Accessibility is judged by the final status
+		result = lc.CheckLink(context.Background(), client, "/broken", tsURL, model.AnalysisOptions{})
+		assert.False(t, result.IsAccessible)
+		assert.Equal(t, http.StatusNotFound, result.StatusCode)
+		assert.Len(t, result.Redirects, 2)
+
+		result = lc.CheckLink(context.Background(), client, "/loop", tsURL, model.AnalysisOptions{})
+		assert.False(t, result.IsAccessible)
+		assert.Equal(t, RedirectErrorLoop, result.RedirectError)
 		assert.Equal(t, http.StatusFound, result.StatusCode)
+		assert.Len(t, result.Redirects, 2)
+
+		result = lc.CheckLink(context.Background(), client, "/endless", tsURL, model.AnalysisOptions{})
+		assert.False(t, result.IsAccessible)
+		assert.Equal(t, RedirectErrorTooMany, result.RedirectError)
+		assert.Len(t, result.Redirects, MaxRedirects+1)
+
+		// Without following, a redirecting link is reported with its own status
+		result = lc.CheckLink(context.Background(), client, "/redirect", tsURL, model.AnalysisOptions{SkipRedirects: true})
+		assert.True(t, result.IsAccessible)
+		assert.Equal(t, http.StatusMovedPermanently, result.StatusCode)
+		assert.Empty(t, result.FinalURL)
+		assert.Nil(t, result.Redirects)
 	})
 
 	t.Run("HEAD Request creation error", func(t *testing.T) {
-		result := lc.CheckLink(context.Background(), client, "http://invalid.com:abc", baseURL)
+		result := lc.CheckLink(context.Background(), client, "http://invalid.com:abc", baseURL, model.AnalysisOptions{})
 		assert.Nil(t, result)
 	})
 }
@@ -154,7 +205,7 @@ func TestRunWorker(t *testing.T) {
 		ctx, cancel := context.WithCancel(context.Background())
 
 		wg.Add(1)
-		go lc.RunWorker(ctx, linksChan, resultsChan, baseURL, &wg)
+		go lc.RunWorker(ctx, linksChan, resultsChan, baseURL, model.AnalysisOptions{}, &wg)
 
 		<-resultsChan
 
@@ -177,7 +228,7 @@ func TestRunWorker(t *testing.T) {
 		linksChan <- ts.URL
 
 		wg.Add(1)
-		go lc.RunWorker(ctx, linksChan, resultsChan, baseURL, &wg)
+		go lc.RunWorker(ctx, linksChan, resultsChan, baseURL, model.AnalysisOptions{}, &wg)
 
 		time.Sleep(100 * time.Millisecond)
 		cancel()
@@ -185,3 +236,58 @@ func TestRunWorker(t *testing.T) {
 		wg.Wait()
 	})
 }
+
+func TestNewLinkCheckClient(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/redirect" {
+			http.Redirect(w, r, "/target", http.StatusFound)
+			return
+		}
+		w.Header().Set("X-User-Agent", r.UserAgent())
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	t.Run("Defaults", func(t *testing.T) {
+		client := newLinkCheckClient(model.AnalysisOptions{}, newTestNetworkGuard().Transport())
+		assert.Equal(t, DefaultLinkTimeout, client.Timeout)
+
+		resp, err := client.Get(ts.URL + "/redirect")
+		assert.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusFound, resp.StatusCode)
+	})
+
+	t.Run("User agent", func(t *testing.T) {
+		client := newLinkCheckClient(model.AnalysisOptions{UserAgent: "audit-bot/1.0", LinkTimeout: time.Second}, newTestNetworkGuard().Transport())
+		assert.Equal(t, time.Second, client.Timeout)
+
+		resp, err := client.Get(ts.URL + "/target")
+		assert.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "audit-bot/1.0", resp.Header.Get("X-User-Agent"))
+	})
+}
+
+func TestSelectLinksToCheck(t *testing.T) {
+	baseURL, _ := url.Parse("http://base.com")
+	links := []string{"/a", "#top", "http://external.com/", "mailto:a@b.c", "tel:+4930123456", "http://base.com/b", "sms:+4930123456", "/c"}
+
+	tests := []struct {
+		name     string
+		options  model.AnalysisOptions
+		expected []string
+	}{
+		{"Defaults", model.AnalysisOptions{}, []string{"/a", "http://external.com/", "http://base.com/b", "/c"}},
+		{"Link check disabled", model.AnalysisOptions{SkipLinkCheck: true}, nil},
+		{"Maximum", model.AnalysisOptions{MaxLinks: 2}, []string{"/a", "http://external.com/"}},
+		{"Internal only", model.AnalysisOptions{SkipExternalLinks: true, MaxLinks: 2}, []string{"/a", "http://base.com/b"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, selectLinksToCheck(links, baseURL, tt.options))
+		})
+	}
+}
@@ -0,0 +1,226 @@
+package webanalyzer
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+	"web-analyzer-api/app/internal/model"
+	"web-analyzer-api/app/internal/repository"
+	"web-analyzer-api/app/internal/util/logger"
+)
+
+const (
+	WebhookEventCompleted = "analysis.completed"
+	WebhookEventFailed    = "analysis.failed"
+
+	WebhookEventHeader     = "X-Webhook-Event"
+	WebhookAttemptHeader   = "X-Webhook-Attempt"
+	WebhookTimestampHeader = "X-Webhook-Timestamp"
+	WebhookSignatureHeader = "X-Webhook-Signature"
+)
+
+type WebhookConfig struct {
+	Secret         string
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	Timeout        time.Duration
+}
+
+// WebhookDispatcher delivers analysis results to callback URLs in the background. Every attempt is
+// recorded in the delivery log, and failed attempts are retried with exponential backoff.
+type WebhookDispatcher struct {
+	log    *logger.Logger
+	repo   repository.WebhookDeliveryRepository
+	config WebhookConfig
+	client *http.Client
+
+	ctx      context.Context
+	cancel   context.CancelFunc
+	mu       sync.Mutex
+	closed   bool
+	inFlight int
+	wg       sync.WaitGroup
+}
+
+// NewWebhookDispatcher returns a dispatcher whose deliveries connect through guard, so callbacks cannot reach
+// addresses that analyses are not allowed to.
+func NewWebhookDispatcher(log *logger.Logger, repo repository.WebhookDeliveryRepository, guard *NetworkGuard, config WebhookConfig) *WebhookDispatcher {
+	if config.MaxAttempts < 1 {
+		config.MaxAttempts = 1
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	return &WebhookDispatcher{
+		log:    log,
+		repo:   repo,
+		config: config,
+		client: &http.Client{Transport: guard.Transport(), Timeout: config.Timeout},
+		ctx:    ctx,
+		cancel: cancel,
+	}
+}
+
+// Enabled reports whether a signing secret is configured. Callbacks are only accepted when it is.
+func (d *WebhookDispatcher) Enabled() bool {
+	return d.config.Secret != ""
+}
+
+// Dispatch starts delivering payload to callbackURL and returns immediately.
+func (d *WebhookDispatcher) Dispatch(analysisId string, callbackURL string, event string, payload any) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		d.log.Error("Failed to encode webhook payload: " + err.Error())
+		return
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.closed {
+		d.log.Warn("Webhook not delivered, server is shutting down: analyzeId - " + analysisId)
+		return
+	}
+
+	d.inFlight++
+	d.wg.Add(1)
+	go func() {
+		defer d.wg.Done()
+		d.deliver(analysisId, callbackURL, event, body)
+
+		d.mu.Lock()
+		d.inFlight--
+		d.mu.Unlock()
+	}()
+}
+
+// Deliveries returns the delivery log of an analysis, oldest attempt first.
+func (d *WebhookDispatcher) Deliveries(analysisId string) ([]model.WebhookDelivery, error) {
+	return d.repo.ListDeliveries(analysisId)
+}
+
+// Shutdown stops accepting deliveries and waits for in-flight ones until ctx is done. Retries that
+// are still pending after that are abandoned.
+func (d *WebhookDispatcher) Shutdown(ctx context.Context) error {
+	d.mu.Lock()
+	d.closed = true
+	idle := d.inFlight == 0
+	d.mu.Unlock()
+
+	if idle {
+		d.cancel()
+		return nil
+	}
+
+	done := make(chan struct{})
+	go func() {
+		d.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		d.cancel()
+		return nil
+	case <-ctx.Done():
+		d.cancel()
+		<-done
+		return ctx.Err()
+	}
+}
+
+func (d *WebhookDispatcher) deliver(analysisId string, callbackURL string, event string, body []byte) {
+	for attempt := 1; attempt <= d.config.MaxAttempts; attempt++ {
+		delivery, retry := d.attempt(callbackURL, event, attempt, body)
+		delivery.AnalysisID = analysisId
+
+		if _, err := d.repo.SaveDelivery(delivery); err != nil {
+			d.log.Error("Failed to save webhook delivery: " + err.Error())
+		}
+
+		if delivery.Success {
+			d.log.Info("Webhook delivered: analyzeId - " + analysisId)
+			return
+		}
+		if !retry || attempt == d.config.MaxAttempts {
+			break
+		}
+
+		select {
+		case <-time.After(d.backoff(attempt)):
+		case <-d.ctx.Done():
+			d.log.Warn("Webhook retries abandoned on shutdown: analyzeId - " + analysisId)
+			return
+		}
+	}
+
+	d.log.Warn("Webhook delivery failed: analyzeId - " + analysisId)
+}
+
+// attempt sends a single request and reports whether a failed attempt is worth retrying.
+func (d *WebhookDispatcher) attempt(callbackURL string, event string, attempt int, body []byte) (model.WebhookDelivery, bool) {
+	delivery := model.WebhookDelivery{
+		URL:       callbackURL,
+		Event:     event,
+		Attempt:   attempt,
+		CreatedAt: time.Now().UTC(),
+	}
+
+	req, err := http.NewRequestWithContext(d.ctx, http.MethodPost, callbackURL, bytes.NewReader(body))
+	if err != nil {
+		delivery.Error = err.Error()
+		return delivery, false
+	}
+
+	timestamp := strconv.FormatInt(delivery.CreatedAt.Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookEventHeader, event)
+	req.Header.Set(WebhookAttemptHeader, strconv.Itoa(attempt))
+	req.Header.Set(WebhookTimestampHeader, timestamp)
+	req.Header.Set(WebhookSignatureHeader, SignWebhook(d.config.Secret, timestamp, body))
+
+	resp, err := d.client.Do(req)
+	delivery.Duration = time.Since(delivery.CreatedAt)
+	if err != nil {
+		delivery.Error = err.Error()
+		return delivery, !errors.Is(err, ErrBlockedAddress)
+	}
+	resp.Body.Close()
+
+	delivery.StatusCode = resp.StatusCode
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		delivery.Success = true
+		return delivery, false
+	}
+
+	delivery.Error = fmt.Sprintf("unexpected status code: %d", resp.StatusCode)
+	retry := resp.StatusCode == http.StatusRequestTimeout || resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+	return delivery, retry
+}
+
+func (d *WebhookDispatcher) backoff(attempt int) time.Duration {
+	backoff := d.config.InitialBackoff << (attempt - 1)
+	if d.config.MaxBackoff > 0 && (backoff > d.config.MaxBackoff || backoff <= 0) {
+		return d.config.MaxBackoff
+	}
+	return backoff
+}
+
+// SignWebhook returns the signature header value for a payload: the hex encoded HMAC-SHA256 of
+// "<timestamp>.<body>" keyed with the secret.
+func SignWebhook(secret string, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,270 @@
+package webanalyzer
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+	"web-analyzer-api/app/internal/contract"
+	"web-analyzer-api/app/internal/core/apperror"
+	"web-analyzer-api/app/internal/model"
+	"web-analyzer-api/app/internal/repository"
+	"web-analyzer-api/app/internal/repositorymemory"
+	"web-analyzer-api/app/internal/util/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignWebhook(t *testing.T) {
+	signature := SignWebhook("secret", "1700000000", []byte(`{"a":1}`))
+
+	assert.Regexp(t, "^sha256=[0-9a-f]{64}$", signature)
+	assert.Equal(t, signature, SignWebhook("secret", "1700000000", []byte(`{"a":1}`)))
+	assert.NotEqual(t, signature, SignWebhook("other", "1700000000", []byte(`{"a":1}`)))
+	assert.NotEqual(t, signature, SignWebhook("secret", "1700000001", []byte(`{"a":1}`)))
+}
+
+func TestWebhookDispatcher(t *testing.T) {
+	log := logger.Get("info")
+	config := WebhookConfig{Secret: "secret", MaxAttempts: 3, InitialBackoff: time.Millisecond, Timeout: time.Second}
+
+	setup := func(config WebhookConfig, handler http.HandlerFunc) (*WebhookDispatcher, repository.WebhookDeliveryRepository, string) {
+		ts := httptest.NewServer(handler)
+		t.Cleanup(ts.Close)
+		repo := repositorymemory.NewWebhookDeliveryRepo(log)
+		return NewWebhookDispatcher(log, repo, newTestNetworkGuard(), config), repo, ts.URL
+	}
+
+	waitForDeliveries := func(t *testing.T, d *WebhookDispatcher) {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		require.NoError(t, d.Shutdown(ctx))
+	}
+
+	t.Run("Signed delivery", func(t *testing.T) {
+		received := make(chan *http.Request, 1)
+		var body []byte
+		d, repo, callbackURL := setup(config, func(w http.ResponseWriter, r *http.Request) {
+			body, _ = io.ReadAll(r.Body)
+			received <- r
+		})
+
+		d.Dispatch("id-1", callbackURL, WebhookEventCompleted, map[string]string{"analyze_id": "id-1"})
+		waitForDeliveries(t, d)
+
+		req := <-received
+		assert.Equal(t, http.MethodPost, req.Method)
+		assert.Equal(t, "application/json", req.Header.Get("Content-Type"))
+		assert.Equal(t, WebhookEventCompleted, req.Header.Get(WebhookEventHeader))
+		assert.Equal(t, "1", req.Header.Get(WebhookAttemptHeader))
+		assert.JSONEq(t, `{"analyze_id":"id-1"}`, string(body))
+		assert.Equal(t, SignWebhook("secret", req.Header.Get(WebhookTimestampHeader), body), req.Header.Get(WebhookSignatureHeader))
+
+		deliveries, _ := repo.ListDeliveries("id-1")
+		require.Len(t, deliveries, 1)
+		assert.True(t, deliveries[0].Success)
+		assert.Equal(t, http.StatusOK, deliveries[0].StatusCode)
+		assert.Equal(t, callbackURL, deliveries[0].URL)
+		assert.Equal(t, WebhookEventCompleted, deliveries[0].Event)
+	})
+
+	t.Run("Retries until success", func(t *testing.T) {
+		var calls atomic.Int32
+		d, repo, callbackURL := setup(config, func(w http.ResponseWriter, r *http.Request) {
+			if calls.Add(1) < 3 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+			}
+		})
+
+		d.Dispatch("id-1", callbackURL, WebhookEventFailed, map[string]string{})
+		waitForDeliveries(t, d)
+
+		deliveries, _ := repo.ListDeliveries("id-1")
+		require.Len(t, deliveries, 3)
+		for i, delivery := range deliveries {
+			assert.Equal(t, i+1, delivery.Attempt)
+		}
+		assert.False(t, deliveries[0].Success)
+		assert.Equal(t, http.StatusServiceUnavailable, deliveries[0].StatusCode)
+		assert.Equal(t, "unexpected status code: 503", deliveries[0].Error)
+		assert.True(t, deliveries[2].Success)
+	})
+
+	t.Run("Gives up after max attempts", func(t *testing.T) {
+		d, repo, callbackURL := setup(config, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		})
+
+		d.Dispatch("id-1", callbackURL, WebhookEventCompleted, map[string]string{})
+		waitForDeliveries(t, d)
+
+		deliveries, _ := repo.ListDeliveries("id-1")
+		assert.Len(t, deliveries, 3)
+	})
+
+	t.Run("Client errors are not retried", func(t *testing.T) {
+		d, repo, callbackURL := setup(config, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadRequest)
+		})
+
+		d.Dispatch("id-1", callbackURL, WebhookEventCompleted, map[string]string{})
+		waitForDeliveries(t, d)
+
+		deliveries, _ := repo.ListDeliveries("id-1")
+		require.Len(t, deliveries, 1)
+		assert.Equal(t, http.StatusBadRequest, deliveries[0].StatusCode)
+	})
+
+	t.Run("Unreachable callback is retried", func(t *testing.T) {
+		d, repo, callbackURL := setup(config, func(w http.ResponseWriter, r *http.Request) {})
+		d.Dispatch("id-1", callbackURL+"/%zz", WebhookEventCompleted, map[string]string{})
+		d.Dispatch("id-2", "http://127.0.0.1:1/hook", WebhookEventCompleted, map[string]string{})
+		waitForDeliveries(t, d)
+
+		// An invalid URL cannot succeed on retry
+		deliveries, _ := repo.ListDeliveries("id-1")
+		assert.Len(t, deliveries, 1)
+
+		deliveries, _ = repo.ListDeliveries("id-2")
+		require.Len(t, deliveries, 3)
+		assert.NotEmpty(t, deliveries[0].Error)
+		assert.Equal(t, 0, deliveries[0].StatusCode)
+	})
+
+	t.Run("Shutdown abandons pending retries", func(t *testing.T) {
+		slowConfig := config
+		slowConfig.InitialBackoff = time.Hour
+		d, repo, callbackURL := setup(slowConfig, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		})
+
+		d.Dispatch("id-1", callbackURL, WebhookEventCompleted, map[string]string{})
+		require.Eventually(t, func() bool {
+			deliveries, _ := repo.ListDeliveries("id-1")
+			return len(deliveries) == 1
+		}, 5*time.Second, 10*time.Millisecond)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		assert.ErrorIs(t, d.Shutdown(ctx), context.DeadlineExceeded)
+
+		// Deliveries after shutdown are dropped
+		d.Dispatch("id-2", callbackURL, WebhookEventCompleted, map[string]string{})
+		deliveries, _ := repo.ListDeliveries("id-2")
+		assert.Empty(t, deliveries)
+	})
+
+	t.Run("Private callback addresses are blocked", func(t *testing.T) {
+		var called atomic.Bool
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			called.Store(true)
+		}))
+		defer ts.Close()
+
+		d := NewWebhookDispatcher(log, repositorymemory.NewWebhookDeliveryRepo(log), NewNetworkGuard(NetworkGuardConfig{}), config)
+		_, err := d.client.Post(ts.URL, "application/json", nil)
+		assert.ErrorIs(t, err, ErrBlockedAddress)
+
+		// Blocked deliveries are not retried
+		delivery, retry := d.attempt(ts.URL, WebhookEventCompleted, 1, []byte(`{}`))
+		assert.False(t, delivery.Success)
+		assert.Contains(t, delivery.Error, ErrBlockedAddress.Error())
+		assert.False(t, retry)
+		assert.False(t, called.Load())
+	})
+
+	t.Run("Backoff", func(t *testing.T) {
+		d := NewWebhookDispatcher(log, repositorymemory.NewWebhookDeliveryRepo(log), newTestNetworkGuard(), WebhookConfig{InitialBackoff: time.Second, MaxBackoff: 5 * time.Second})
+
+		assert.Equal(t, time.Second, d.backoff(1))
+		assert.Equal(t, 2*time.Second, d.backoff(2))
+		assert.Equal(t, 4*time.Second, d.backoff(3))
+		assert.Equal(t, 5*time.Second, d.backoff(4))
+		assert.Equal(t, 5*time.Second, d.backoff(100))
+	})
+}
+
+func TestWebhookCallbacks(t *testing.T) {
+	log := logger.Get("info")
+
+	t.Run("Result is posted on completion", func(t *testing.T) {
+		callbacks := make(chan *http.Request, 1)
+		callbackServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			callbacks <- r
+		}))
+		defer callbackServer.Close()
+
+		page := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`<html><head><title>Hooked</title></head><body></body></html>`))
+		}))
+		defer page.Close()
+
+		repo := repositorymemory.NewWebAnalyzerRepo(log)
+		webhooks := NewWebhookDispatcher(log, repositorymemory.NewWebhookDeliveryRepo(log), newTestNetworkGuard(), WebhookConfig{Secret: "secret", MaxAttempts: 1, Timeout: time.Second})
+		service := NewWebAnalyzerService(log, repo, repositorymemory.NewBatchRepo(log), repositorymemory.NewCrawlRepo(log), NewLinkChecker(log, newTestNetworkGuard(), newTestRobotsCache(log), LinkCheckConfig{}), NewJobQueue(log, 1, 10), webhooks, newTestNetworkGuard(), PageFetchConfig{}, CrawlConfig{})
+
+		pageURL, _ := url.Parse(page.URL)
+		id, err := service.AnalyzeWebsite(context.Background(), pageURL, callbackServer.URL, contract.AnalysisOptions{})
+		require.NoError(t, err)
+
+		select {
+		case req := <-callbacks:
+			assert.Equal(t, WebhookEventCompleted, req.Header.Get(WebhookEventHeader))
+		case <-time.After(5 * time.Second):
+			t.Fatal("webhook was not delivered")
+		}
+		require.NoError(t, service.Shutdown(context.Background()))
+
+		found, _ := repo.GetById(id)
+		assert.Equal(t, callbackServer.URL, found.CallbackURL)
+
+		resp, err := service.GetWebhookDeliveries(context.Background(), id)
+		require.NoError(t, err)
+		assert.Equal(t, callbackServer.URL, resp.CallbackURL)
+		require.Len(t, resp.Deliveries, 1)
+		assert.True(t, resp.Deliveries[0].Success)
+	})
+
+	t.Run("Only final success or failure is notified", func(t *testing.T) {
+		repo := repositorymemory.NewWebAnalyzerRepo(log)
+		deliveries := repositorymemory.NewWebhookDeliveryRepo(log)
+		webhooks := NewWebhookDispatcher(log, deliveries, newTestNetworkGuard(), WebhookConfig{Secret: "secret", MaxAttempts: 1})
+		service := &webAnalyzerService{log: log, repo: repo, linkChecker: NewLinkChecker(log, newTestNetworkGuard(), newTestRobotsCache(log), LinkCheckConfig{}), pages: newPageFetcher(newTestNetworkGuard(), PageFetchConfig{}), jobQueue: NewJobQueue(log, 1, 10), events: newEventBroker(), webhooks: webhooks}
+
+		id, _ := repo.Save(model.WebAnalyzer{URL: "http://test.com", Status: StatusQueued, CallbackURL: "http://127.0.0.1:1/hook"})
+		service.UpdateAnalysisStatus(id, StatusCancelled, "Analysis was cancelled.")
+		require.NoError(t, webhooks.Shutdown(context.Background()))
+
+		found, _ := deliveries.ListDeliveries(id)
+		assert.Empty(t, found)
+	})
+
+	t.Run("Callbacks disabled without secret", func(t *testing.T) {
+		service, mockRepo, _ := setupTest()
+		pageURL, _ := url.Parse("http://test.com")
+
+		_, err := service.AnalyzeWebsite(context.Background(), pageURL, "http://ci.test/hook", contract.AnalysisOptions{})
+
+		var appErr *apperror.AppError
+		require.ErrorAs(t, err, &appErr)
+		assert.Equal(t, http.StatusBadRequest, appErr.StatusCode)
+		mockRepo.AssertNotCalled(t, "Save", mock.Anything)
+	})
+
+	t.Run("Delivery log of unknown analysis", func(t *testing.T) {
+		service, mockRepo, _ := setupTest()
+		mockRepo.On("GetById", "missing").Return(nil, nil)
+
+		_, err := service.GetWebhookDeliveries(context.Background(), "missing")
+
+		var appErr *apperror.AppError
+		require.ErrorAs(t, err, &appErr)
+		assert.Equal(t, http.StatusNotFound, appErr.StatusCode)
+	})
+}
@@ -1,8 +1,16 @@
 package di
 
 import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
 	v1 "web-analyzer-api/app/internal/api/v1"
+	"web-analyzer-api/app/internal/config"
+	"web-analyzer-api/app/internal/core"
 	webanalyzer "web-analyzer-api/app/internal/core/web_analyzer"
+	"web-analyzer-api/app/internal/repository"
+	"web-analyzer-api/app/internal/repositorymemory"
 	"web-analyzer-api/app/internal/repositorysql"
 	"web-analyzer-api/app/internal/util/logger"
 )
@@ -12,19 +20,118 @@ type HTTPHandlers struct {
 }
 
 type Container struct {
-	HTTPHandlers HTTPHandlers
+	Config             config.Config
+	HTTPHandlers       HTTPHandlers
+	webAnalyzerService core.WebAnalyzerService
+	db                 *sql.DB
 }
 
-func NewContainer(logger *logger.Logger) *Container {
-	webAnalyzerRepo := repositorysql.NewWebAnalyzerRepo(logger)
-	linkChecker := webanalyzer.NewLinkChecker(logger)
-	webAnalyzerService := webanalyzer.NewWebAnalyzerService(logger, webAnalyzerRepo, linkChecker)
+func NewContainer(logger *logger.Logger, cfg config.Config) (*Container, error) {
+	container := &Container{Config: cfg}
+
+	webAnalyzerRepo, batchRepo, crawlRepo, webhookDeliveryRepo, err := container.newRepositories(logger, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	guard := webanalyzer.NewNetworkGuard(webanalyzer.NetworkGuardConfig{
+		AllowPrivateNetworks: cfg.AllowPrivateNetworks,
+		AllowedHosts:         cfg.FetchAllowedHosts,
+	})
+	if cfg.AllowPrivateNetworks {
+		logger.Warn("Private network protection disabled, analyses may reach internal addresses")
+	}
+	robots := webanalyzer.NewRobotsCache(logger, guard, webanalyzer.RobotsConfig{
+		UserAgent: cfg.RobotsUserAgent,
+		CacheTTL:  cfg.RobotsCacheTTL,
+	})
+	linkChecker := webanalyzer.NewLinkChecker(logger, guard, robots, webanalyzer.LinkCheckConfig{
+		HostConcurrency: cfg.LinkHostConcurrency,
+		HostRate:        cfg.LinkHostRate,
+		HostBurst:       cfg.LinkHostBurst,
+		MaxRetries:      cfg.LinkMaxRetries,
+		MaxRetryAfter:   cfg.LinkMaxRetryAfter,
+		CacheSize:       cfg.LinkCacheSize,
+		CacheSuccessTTL: cfg.LinkCacheSuccessTTL,
+		CacheFailureTTL: cfg.LinkCacheFailureTTL,
+	})
+	jobQueue := webanalyzer.NewJobQueue(logger, cfg.AnalysisWorkers, cfg.AnalysisQueueSize)
+	webhooks := webanalyzer.NewWebhookDispatcher(logger, webhookDeliveryRepo, guard, webanalyzer.WebhookConfig{
+		Secret:         cfg.WebhookSecret,
+		MaxAttempts:    cfg.WebhookMaxAttempts,
+		InitialBackoff: cfg.WebhookInitialBackoff,
+		MaxBackoff:     cfg.WebhookMaxBackoff,
+		Timeout:        cfg.WebhookTimeout,
+	})
+	if !webhooks.Enabled() {
+		logger.Info("Webhook callbacks disabled, set WEBHOOK_SECRET to enable them")
+	}
+	webAnalyzerService := webanalyzer.NewUnstartedWebAnalyzerService(logger, webAnalyzerRepo, batchRepo, crawlRepo, linkChecker, jobQueue, webhooks, guard, webanalyzer.PageFetchConfig{
+		ConnectTimeout: cfg.PageConnectTimeout,
+		HeaderTimeout:  cfg.PageHeaderTimeout,
+		Timeout:        cfg.PageFetchTimeout,
+		MaxBodyBytes:   cfg.PageMaxBodyBytes,
+	}, webanalyzer.CrawlConfig{
+		MaxDepth: cfg.CrawlMaxDepth,
+		MaxPages: cfg.CrawlMaxPages,
+	})
+	container.webAnalyzerService = webAnalyzerService
+	if err := webAnalyzerService.RecoverStaleAnalyses(cfg.StaleAnalysisPolicy); err != nil {
+		// Closes the analysis queue and the webhook dispatcher along with the database
+		container.Shutdown(context.Background())
+		return nil, err
+	}
+	webAnalyzerService.Start()
+
 	webAnalyzerHandler := v1.NewWebAnalyzerHandler(logger, webAnalyzerService)
 	logger.Info("Dependency injection container initialized successfully")
 
-	return &Container{
-		HTTPHandlers: HTTPHandlers{
-			WebAnalyzerHandler: *webAnalyzerHandler,
-		},
+	container.HTTPHandlers = HTTPHandlers{
+		WebAnalyzerHandler: *webAnalyzerHandler,
+	}
+
+	return container, nil
+}
+
+// Shutdown stops background analyses, waiting for running ones until ctx is done, and then releases resources.
+func (c *Container) Shutdown(ctx context.Context) error {
+	var errs []error
+
+	if c.webAnalyzerService != nil {
+		if err := c.webAnalyzerService.Shutdown(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if err := c.Close(); err != nil {
+		errs = append(errs, err)
+	}
+
+	return errors.Join(errs...)
+}
+
+// Close releases resources held by the container such as the database connection.
+func (c *Container) Close() error {
+	if c.db == nil {
+		return nil
+	}
+	return c.db.Close()
+}
+
+func (c *Container) newRepositories(logger *logger.Logger, cfg config.Config) (repository.WebAnalyzerRepository, repository.BatchRepository, repository.CrawlRepository, repository.WebhookDeliveryRepository, error) {
+	switch cfg.StorageDriver {
+	case config.StorageDriverMemory:
+		logger.Info("Using in-memory storage")
+		return repositorymemory.NewWebAnalyzerRepo(logger), repositorymemory.NewBatchRepo(logger), repositorymemory.NewCrawlRepo(logger), repositorymemory.NewWebhookDeliveryRepo(logger), nil
+	case config.StorageDriverSQLite:
+		db, err := repositorysql.Open(cfg.SQLitePath)
+		if err != nil {
+			return nil, nil, nil, nil, err
+		}
+		c.db = db
+		logger.Info("Using SQLite storage", "path", cfg.SQLitePath)
+		return repositorysql.NewWebAnalyzerRepo(logger, db), repositorysql.NewBatchRepo(logger, db), repositorysql.NewCrawlRepo(logger, db), repositorysql.NewWebhookDeliveryRepo(logger, db), nil
+	default:
+		return nil, nil, nil, nil, fmt.Errorf("unsupported storage driver: %s", cfg.StorageDriver)
 	}
 }
@@ -1,7 +1,10 @@
 package di
 
 import (
+	"context"
+	"path/filepath"
 	"testing"
+	"web-analyzer-api/app/internal/config"
 	"web-analyzer-api/app/internal/util/logger"
 
 	"github.com/stretchr/testify/assert"
@@ -11,9 +14,37 @@ func TestNewContainer(t *testing.T) {
 	log := logger.Get("debug")
 
 	t.Run("Initialize Container", func(t *testing.T) {
-		container := NewContainer(log)
+		container, err := NewContainer(log, config.Config{StorageDriver: config.StorageDriverMemory, StaleAnalysisPolicy: "resume"})
 
+		assert.NoError(t, err)
 		assert.NotNil(t, container)
 		assert.NotNil(t, container.HTTPHandlers.WebAnalyzerHandler)
+		assert.NoError(t, container.Close())
+	})
+
+	t.Run("Initialize Container with SQLite", func(t *testing.T) {
+		container, err := NewContainer(log, config.Config{
+			StorageDriver:       config.StorageDriverSQLite,
+			SQLitePath:          filepath.Join(t.TempDir(), "test.db"),
+			StaleAnalysisPolicy: "resume",
+		})
+
+		assert.NoError(t, err)
+		assert.NotNil(t, container)
+		assert.NoError(t, container.Shutdown(context.Background()))
+	})
+
+	t.Run("Invalid stale analysis policy", func(t *testing.T) {
+		container, err := NewContainer(log, config.Config{StorageDriver: config.StorageDriverMemory, StaleAnalysisPolicy: "ignore"})
+
+		assert.Error(t, err)
+		assert.Nil(t, container)
+	})
+
+	t.Run("Unsupported storage driver", func(t *testing.T) {
+		container, err := NewContainer(log, config.Config{StorageDriver: "mongo"})
+
+		assert.Error(t, err)
+		assert.Nil(t, container)
 	})
 }
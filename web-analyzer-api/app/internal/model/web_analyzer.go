@@ -10,26 +10,165 @@ type WebAnalyzer struct {
 	Headings         map[string]int
 	Links            LinkAnalysis
 	HasLoginForm     bool
+	SEO              *SEOAnalysis
 	Status           string
 	ErrorDescription *string
+	CallbackURL      string
+	Options          AnalysisOptions
+	FinalURL         string
+	Redirects        []RedirectHop
+	Encoding         string
 	CreatedAt        time.Time
 	UpdatedAt        *time.Time
 }
 
+// AnalysisOptions holds the options requested for an analysis. Zero values mean the server defaults.
+type AnalysisOptions struct {
+	SkipLinkCheck     bool
+	MaxLinks          int
+	LinkWorkers       int
+	LinkTimeout       time.Duration
+	SkipRedirects     bool
+	SkipExternalLinks bool
+	UserAgent         string
+	RespectRobots     bool
+}
+
+// LinkAnalysis counts unique anchor links in Internal and External, and every appearance of them in the
+// occurrence counts. Resources counts the resources of every kind, anchors included.
 type LinkAnalysis struct {
 	Internal            int
 	External            int
+	InternalOccurrences int
+	ExternalOccurrences int
 	Inaccessible        int
 	InaccessibleDetails []InaccessibleLink
+	Redirected          []LinkRedirect
+	ErrorCategories     map[string]int
+	SkippedRobots       []string
+	Resources           map[string]ResourceStats
+}
+
+// SEOAnalysis is the SEO metadata of a page and the problems found in it. Canonical and the Hreflang URLs are
+// resolved against the page. OpenGraph and TwitterCard are keyed by property name without prefix.
+type SEOAnalysis struct {
+	TitleCount  int
+	Description string
+	Robots      string
+	Canonical   string
+	Hreflang    []HreflangAlternate
+	OpenGraph   map[string]string
+	TwitterCard map[string]string
+	Viewport    string
+	Lang        string
+	Issues      []SEOIssue
+}
+
+// HreflangAlternate is a translation of a page.
+type HreflangAlternate struct {
+	Lang string
+	URL  string
+}
+
+// SEOIssue is a problem found by the SEO checks. Code identifies the check and Message explains the problem.
+type SEOIssue struct {
+	Code    string
+	Message string
 }
 
+// ResourceStats counts the unique resources of one kind and how many of them failed their check.
+type ResourceStats struct {
+	Internal int
+	External int
+	Broken   int
+}
+
+// RedirectHop is one response in a redirect chain.
+type RedirectHop struct {
+	URL        string
+	StatusCode int
+}
+
+// LinkRedirect is a checked link that redirected. Error is set when the chain was abandoned.
+type LinkRedirect struct {
+	URL        string
+	FinalURL   string
+	StatusCode int
+	Chain      []RedirectHop
+	Error      string
+}
+
+// InaccessibleLink is a checked link that failed. Category is the cause of the failure and Message its detail.
+// Kinds are the kinds of resource the page references it as.
 type InaccessibleLink struct {
 	URL        string
 	StatusCode int
+	Category   string
+	Message    string
+	Kinds      []string
 }
 
 type LinkCheckResult struct {
-	URL          string
-	StatusCode   int
-	IsAccessible bool
+	URL           string
+	StatusCode    int
+	IsAccessible  bool
+	FinalURL      string
+	Redirects     []RedirectHop
+	RedirectError string
+	ErrorCategory string
+	ErrorMessage  string
+	SkippedRobots bool
+}
+
+type WebhookDelivery struct {
+	ID         string
+	AnalysisID string
+	URL        string
+	Event      string
+	Attempt    int
+	StatusCode int
+	Success    bool
+	Error      string
+	Duration   time.Duration
+	CreatedAt  time.Time
+}
+
+type Batch struct {
+	ID          string
+	AnalysisIDs []string
+	CreatedAt   time.Time
+}
+
+// Crawl is a multi-page analysis that follows internal links from URL, or analyzes the pages the sitemaps of
+// the site list when Source is sitemap. Pages are in the order they were found.
+type Crawl struct {
+	ID        string
+	URL       string
+	Source    string
+	Status    string
+	MaxDepth  int
+	MaxPages  int
+	Pages     []CrawlPage
+	Sitemap   CrawlSitemap
+	CreatedAt time.Time
+	UpdatedAt *time.Time
+}
+
+// CrawlSitemap is what the sitemaps of a crawled site list. Sitemaps are the files that were read, Pages the
+// number of pages they list, NotAnalyzed the listed pages left out of the crawl, such as past its page limit,
+// and NotInSitemap the internal pages linked from analyzed pages they leave out.
+type CrawlSitemap struct {
+	Sitemaps     []string
+	Pages        int
+	NotAnalyzed  int
+	NotInSitemap []string
+}
+
+// CrawlPage is a page of a crawl with its analysis. Links are the internal pages it links to.
+type CrawlPage struct {
+	URL        string
+	Depth      int
+	AnalysisID string
+	Links      []string
+	InSitemap  bool
 }
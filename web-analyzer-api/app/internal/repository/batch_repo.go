@@ -0,0 +1,8 @@
+package repository
+
+import "web-analyzer-api/app/internal/model"
+
+type BatchRepository interface {
+	SaveBatch(batch model.Batch) (string, error)
+	GetBatch(id string) (*model.Batch, error)
+}
@@ -0,0 +1,10 @@
+package repository
+
+import "web-analyzer-api/app/internal/model"
+
+type CrawlRepository interface {
+	SaveCrawl(crawl model.Crawl) (string, error)
+	UpdateCrawl(crawl model.Crawl) error
+	GetCrawl(id string) (*model.Crawl, error)
+	FindCrawlsByStatus(statuses ...string) ([]model.Crawl, error)
+}
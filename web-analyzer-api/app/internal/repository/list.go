@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"time"
+	"web-analyzer-api/app/internal/model"
+)
+
+const (
+	SortByCreatedAt = "created_at"
+	SortByUpdatedAt = "updated_at"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// ListFilter selects a page of analyses. Results are ordered by SortBy and then by ID so that
+// the cursor of the last returned item identifies a stable position in the listing.
+type ListFilter struct {
+	Statuses     []string
+	URL          string
+	URLContains  string
+	HostContains string
+	CreatedFrom  *time.Time
+	CreatedTo    *time.Time
+	SortBy       string
+	SortDesc     bool
+	Cursor       string
+	Limit        int
+}
+
+type ListResult struct {
+	Items      []model.WebAnalyzer
+	NextCursor string
+}
+
+type cursor struct {
+	Key int64  `json:"k"`
+	ID  string `json:"i"`
+}
+
+// SortKey returns the value an analysis is ordered by. Unset timestamps sort as zero and a
+// missing update time falls back to the creation time.
+func SortKey(analysis model.WebAnalyzer, sortBy string) int64 {
+	t := analysis.CreatedAt
+	if sortBy == SortByUpdatedAt && analysis.UpdatedAt != nil {
+		t = *analysis.UpdatedAt
+	}
+
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixNano()
+}
+
+func EncodeCursor(key int64, id string) string {
+	data, _ := json.Marshal(cursor{Key: key, ID: id})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func DecodeCursor(value string) (int64, string, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return 0, "", ErrInvalidCursor
+	}
+
+	var c cursor
+	if err := json.Unmarshal(data, &c); err != nil || c.ID == "" {
+		return 0, "", ErrInvalidCursor
+	}
+
+	return c.Key, c.ID, nil
+}
@@ -0,0 +1,36 @@
+package repository
+
+import (
+	"testing"
+	"time"
+	"web-analyzer-api/app/internal/model"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	encoded := EncodeCursor(1700000000000000000, "abc-123")
+
+	key, id, err := DecodeCursor(encoded)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1700000000000000000), key)
+	assert.Equal(t, "abc-123", id)
+}
+
+func TestDecodeCursor_Invalid(t *testing.T) {
+	for _, value := range []string{"not base64!", "bm90IGpzb24", EncodeCursor(1, "")} {
+		_, _, err := DecodeCursor(value)
+		assert.ErrorIs(t, err, ErrInvalidCursor, value)
+	}
+}
+
+func TestSortKey(t *testing.T) {
+	created := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	updated := created.Add(time.Hour)
+
+	assert.Equal(t, int64(0), SortKey(model.WebAnalyzer{}, SortByCreatedAt))
+	assert.Equal(t, created.UnixNano(), SortKey(model.WebAnalyzer{CreatedAt: created}, SortByUpdatedAt))
+	assert.Equal(t, updated.UnixNano(), SortKey(model.WebAnalyzer{CreatedAt: created, UpdatedAt: &updated}, SortByUpdatedAt))
+	assert.Equal(t, created.UnixNano(), SortKey(model.WebAnalyzer{CreatedAt: created, UpdatedAt: &updated}, SortByCreatedAt))
+}
@@ -1,9 +1,16 @@
 package repository
 
-import "web-analyzer-api/app/internal/model"
+import (
+	"errors"
+	"web-analyzer-api/app/internal/model"
+)
+
+var ErrRecordNotFound = errors.New("record not found")
 
 type WebAnalyzerRepository interface {
 	Save(webAnalyzer model.WebAnalyzer) (string, error)
 	Update(webAnalyzer model.WebAnalyzer) (string, error)
 	GetById(id string) (*model.WebAnalyzer, error)
+	FindByStatus(statuses ...string) ([]model.WebAnalyzer, error)
+	List(filter ListFilter) (*ListResult, error)
 }
@@ -0,0 +1,8 @@
+package repository
+
+import "web-analyzer-api/app/internal/model"
+
+type WebhookDeliveryRepository interface {
+	SaveDelivery(delivery model.WebhookDelivery) (string, error)
+	ListDeliveries(analysisId string) ([]model.WebhookDelivery, error)
+}
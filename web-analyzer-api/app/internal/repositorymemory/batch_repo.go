@@ -0,0 +1,49 @@
+package repositorymemory
+
+import (
+	"sync"
+	"time"
+	"web-analyzer-api/app/internal/model"
+	"web-analyzer-api/app/internal/repository"
+	"web-analyzer-api/app/internal/util/logger"
+)
+
+type batchRepo struct {
+	log     *logger.Logger
+	mu      sync.RWMutex
+	storage map[string]model.Batch
+}
+
+func NewBatchRepo(logger *logger.Logger) repository.BatchRepository {
+	return &batchRepo{
+		log:     logger,
+		storage: make(map[string]model.Batch),
+	}
+}
+
+func (r *batchRepo) SaveBatch(batch model.Batch) (string, error) {
+	batch.ID = generateID()
+	if batch.CreatedAt.IsZero() {
+		batch.CreatedAt = time.Now().UTC()
+	}
+	batch.AnalysisIDs = append([]string(nil), batch.AnalysisIDs...)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.storage[batch.ID] = batch
+	return batch.ID, nil
+}
+
+func (r *batchRepo) GetBatch(id string) (*model.Batch, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	batch, exists := r.storage[id]
+	if !exists {
+		return nil, nil
+	}
+
+	batch.AnalysisIDs = append([]string(nil), batch.AnalysisIDs...)
+	return &batch, nil
+}
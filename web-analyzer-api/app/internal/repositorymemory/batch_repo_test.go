@@ -0,0 +1,34 @@
+package repositorymemory
+
+import (
+	"testing"
+	"web-analyzer-api/app/internal/model"
+	"web-analyzer-api/app/internal/util/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBatchRepo(t *testing.T) {
+	repo := NewBatchRepo(logger.Get("info"))
+
+	id, err := repo.SaveBatch(model.Batch{AnalysisIDs: []string{"a", "b", "c"}})
+	require.NoError(t, err)
+	assert.NotEmpty(t, id)
+
+	batch, err := repo.GetBatch(id)
+	require.NoError(t, err)
+	require.NotNil(t, batch)
+	assert.Equal(t, id, batch.ID)
+	assert.Equal(t, []string{"a", "b", "c"}, batch.AnalysisIDs)
+	assert.False(t, batch.CreatedAt.IsZero())
+
+	// The returned batch is a copy
+	batch.AnalysisIDs[0] = "changed"
+	batch, _ = repo.GetBatch(id)
+	assert.Equal(t, "a", batch.AnalysisIDs[0])
+
+	batch, err = repo.GetBatch("missing")
+	require.NoError(t, err)
+	assert.Nil(t, batch)
+}
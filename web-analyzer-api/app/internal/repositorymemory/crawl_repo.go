@@ -0,0 +1,115 @@
+package repositorymemory
+
+import (
+	"slices"
+	"sort"
+	"sync"
+	"time"
+	"web-analyzer-api/app/internal/model"
+	"web-analyzer-api/app/internal/repository"
+	"web-analyzer-api/app/internal/util/logger"
+)
+
+type crawlRepo struct {
+	log     *logger.Logger
+	mu      sync.RWMutex
+	storage map[string]model.Crawl
+}
+
+func NewCrawlRepo(logger *logger.Logger) repository.CrawlRepository {
+	return &crawlRepo{
+		log:     logger,
+		storage: make(map[string]model.Crawl),
+	}
+}
+
+func (r *crawlRepo) SaveCrawl(crawl model.Crawl) (string, error) {
+	crawl.ID = generateID()
+	if crawl.CreatedAt.IsZero() {
+		crawl.CreatedAt = time.Now().UTC()
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.storage[crawl.ID] = cloneCrawl(crawl)
+	return crawl.ID, nil
+}
+
+func (r *crawlRepo) UpdateCrawl(crawl model.Crawl) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, exists := r.storage[crawl.ID]
+	if !exists {
+		return repository.ErrRecordNotFound
+	}
+
+	crawl.CreatedAt = stored.CreatedAt
+	updatedAt := time.Now().UTC()
+	crawl.UpdatedAt = &updatedAt
+	r.storage[crawl.ID] = cloneCrawl(crawl)
+	return nil
+}
+
+func (r *crawlRepo) GetCrawl(id string) (*model.Crawl, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	crawl, exists := r.storage[id]
+	if !exists {
+		return nil, nil
+	}
+
+	crawl = cloneCrawl(crawl)
+	return &crawl, nil
+}
+
+func (r *crawlRepo) FindCrawlsByStatus(statuses ...string) ([]model.Crawl, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	result := []model.Crawl{}
+	for _, crawl := range r.storage {
+		if slices.Contains(statuses, crawl.Status) {
+			result = append(result, cloneCrawl(crawl))
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if !result[i].CreatedAt.Equal(result[j].CreatedAt) {
+			return result[i].CreatedAt.Before(result[j].CreatedAt)
+		}
+		return result[i].ID < result[j].ID
+	})
+
+	return result, nil
+}
+
+func cloneCrawl(src model.Crawl) model.Crawl {
+	dst := src
+
+	if src.Pages != nil {
+		dst.Pages = make([]model.CrawlPage, len(src.Pages))
+		for i, page := range src.Pages {
+			if page.Links != nil {
+				page.Links = append([]string(nil), page.Links...)
+			}
+			dst.Pages[i] = page
+		}
+	}
+
+	if src.Sitemap.Sitemaps != nil {
+		dst.Sitemap.Sitemaps = append([]string(nil), src.Sitemap.Sitemaps...)
+	}
+	if src.Sitemap.NotInSitemap != nil {
+		dst.Sitemap.NotInSitemap = append([]string(nil), src.Sitemap.NotInSitemap...)
+	}
+
+	if src.UpdatedAt != nil {
+		updatedAt := *src.UpdatedAt
+		dst.UpdatedAt = &updatedAt
+	}
+
+	return dst
+}
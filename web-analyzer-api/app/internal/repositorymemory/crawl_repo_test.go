@@ -0,0 +1,82 @@
+package repositorymemory
+
+import (
+	"testing"
+	"web-analyzer-api/app/internal/model"
+	"web-analyzer-api/app/internal/repository"
+	"web-analyzer-api/app/internal/util/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCrawlRepo(t *testing.T) {
+	repo := NewCrawlRepo(logger.Get("info"))
+
+	id, err := repo.SaveCrawl(model.Crawl{
+		URL:      "http://a.test/",
+		Status:   "running",
+		MaxDepth: 2,
+		MaxPages: 10,
+		Pages:    []model.CrawlPage{{URL: "http://a.test/", AnalysisID: "a"}},
+	})
+	require.NoError(t, err)
+	assert.NotEmpty(t, id)
+
+	crawl, err := repo.GetCrawl(id)
+	require.NoError(t, err)
+	require.NotNil(t, crawl)
+	assert.Equal(t, "http://a.test/", crawl.URL)
+	assert.Equal(t, []model.CrawlPage{{URL: "http://a.test/", AnalysisID: "a"}}, crawl.Pages)
+	assert.False(t, crawl.CreatedAt.IsZero())
+	assert.Nil(t, crawl.UpdatedAt)
+	createdAt := crawl.CreatedAt
+
+	err = repo.UpdateCrawl(model.Crawl{
+		ID:       id,
+		URL:      "http://a.test/",
+		Status:   "completed",
+		MaxDepth: 2,
+		MaxPages: 10,
+		Pages: []model.CrawlPage{
+			{URL: "http://a.test/", AnalysisID: "a", Links: []string{"http://a.test/about"}},
+			{URL: "http://a.test/about", Depth: 1, AnalysisID: "b"},
+		},
+		Sitemap: model.CrawlSitemap{Sitemaps: []string{"http://a.test/sitemap.xml"}, NotInSitemap: []string{"http://a.test/about"}},
+	})
+	require.NoError(t, err)
+
+	crawl, _ = repo.GetCrawl(id)
+	assert.Equal(t, "completed", crawl.Status)
+	assert.Len(t, crawl.Pages, 2)
+	assert.Equal(t, createdAt, crawl.CreatedAt)
+	assert.NotNil(t, crawl.UpdatedAt)
+
+	// The returned crawl is a copy
+	crawl.Pages[0].Links[0] = "changed"
+	crawl.Sitemap.Sitemaps[0] = "changed"
+	crawl.Sitemap.NotInSitemap[0] = "changed"
+	crawl, _ = repo.GetCrawl(id)
+	assert.Equal(t, "http://a.test/about", crawl.Pages[0].Links[0])
+	assert.Equal(t, "http://a.test/sitemap.xml", crawl.Sitemap.Sitemaps[0])
+	assert.Equal(t, "http://a.test/about", crawl.Sitemap.NotInSitemap[0])
+
+	running, err := repo.SaveCrawl(model.Crawl{URL: "http://b.test/", Status: "running"})
+	require.NoError(t, err)
+	found, err := repo.FindCrawlsByStatus("running")
+	require.NoError(t, err)
+	require.Len(t, found, 1)
+	assert.Equal(t, running, found[0].ID)
+	found, err = repo.FindCrawlsByStatus("running", "completed")
+	require.NoError(t, err)
+	assert.Len(t, found, 2)
+	found, err = repo.FindCrawlsByStatus()
+	require.NoError(t, err)
+	assert.Empty(t, found)
+
+	assert.ErrorIs(t, repo.UpdateCrawl(model.Crawl{ID: "missing"}), repository.ErrRecordNotFound)
+
+	crawl, err = repo.GetCrawl("missing")
+	require.NoError(t, err)
+	assert.Nil(t, crawl)
+}
@@ -0,0 +1,264 @@
+package repositorymemory
+
+import (
+	"maps"
+	"net/url"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"web-analyzer-api/app/internal/model"
+	"web-analyzer-api/app/internal/repository"
+	"web-analyzer-api/app/internal/util/logger"
+
+	"github.com/google/uuid"
+)
+
+// webAnalyzerRepo is safe for concurrent use. Records are deep-copied on the way in and out so
+// callers never share slices, maps or pointers with the stored state.
+type webAnalyzerRepo struct {
+	log     *logger.Logger
+	mu      sync.RWMutex
+	storage map[string]model.WebAnalyzer
+}
+
+func NewWebAnalyzerRepo(logger *logger.Logger) repository.WebAnalyzerRepository {
+	return &webAnalyzerRepo{
+		log:     logger,
+		storage: make(map[string]model.WebAnalyzer),
+	}
+}
+
+func (r *webAnalyzerRepo) Save(webAnalyzer model.WebAnalyzer) (string, error) {
+	id := generateID()
+	webAnalyzer.ID = id
+	if webAnalyzer.CreatedAt.IsZero() {
+		webAnalyzer.CreatedAt = time.Now().UTC()
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.storage[id] = cloneWebAnalyzer(webAnalyzer)
+	return id, nil
+}
+
+func (r *webAnalyzerRepo) GetById(id string) (*model.WebAnalyzer, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if val, ok := r.storage[id]; ok {
+		val = cloneWebAnalyzer(val)
+		return &val, nil
+	}
+	return nil, nil
+}
+
+func (r *webAnalyzerRepo) Update(webAnalyzer model.WebAnalyzer) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.storage[webAnalyzer.ID]; !ok {
+		return "", repository.ErrRecordNotFound
+	}
+
+	updatedAt := time.Now().UTC()
+	webAnalyzer.UpdatedAt = &updatedAt
+	r.storage[webAnalyzer.ID] = cloneWebAnalyzer(webAnalyzer)
+	return webAnalyzer.ID, nil
+}
+
+func (r *webAnalyzerRepo) FindByStatus(statuses ...string) ([]model.WebAnalyzer, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	result := []model.WebAnalyzer{}
+	for _, val := range r.storage {
+		if slices.Contains(statuses, val.Status) {
+			result = append(result, cloneWebAnalyzer(val))
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if !result[i].CreatedAt.Equal(result[j].CreatedAt) {
+			return result[i].CreatedAt.Before(result[j].CreatedAt)
+		}
+		return result[i].ID < result[j].ID
+	})
+
+	return result, nil
+}
+
+func (r *webAnalyzerRepo) List(filter repository.ListFilter) (*repository.ListResult, error) {
+	var (
+		cursorKey int64
+		cursorID  string
+	)
+	if filter.Cursor != "" {
+		var err error
+		if cursorKey, cursorID, err = repository.DecodeCursor(filter.Cursor); err != nil {
+			return nil, err
+		}
+	}
+
+	r.mu.RLock()
+	matches := []model.WebAnalyzer{}
+	for _, val := range r.storage {
+		if matchesFilter(val, filter) {
+			matches = append(matches, val)
+		}
+	}
+	r.mu.RUnlock()
+
+	less := func(keyA int64, idA string, keyB int64, idB string) bool {
+		if keyA != keyB {
+			return keyA < keyB
+		}
+		return idA < idB
+	}
+	if filter.SortDesc {
+		ascending := less
+		less = func(keyA int64, idA string, keyB int64, idB string) bool {
+			return ascending(keyB, idB, keyA, idA)
+		}
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		return less(repository.SortKey(matches[i], filter.SortBy), matches[i].ID, repository.SortKey(matches[j], filter.SortBy), matches[j].ID)
+	})
+
+	result := &repository.ListResult{Items: []model.WebAnalyzer{}}
+	for _, val := range matches {
+		key := repository.SortKey(val, filter.SortBy)
+		if cursorID != "" && !less(cursorKey, cursorID, key, val.ID) {
+			continue
+		}
+
+		if filter.Limit > 0 && len(result.Items) == filter.Limit {
+			last := result.Items[len(result.Items)-1]
+			result.NextCursor = repository.EncodeCursor(repository.SortKey(last, filter.SortBy), last.ID)
+			break
+		}
+		result.Items = append(result.Items, cloneWebAnalyzer(val))
+	}
+
+	return result, nil
+}
+
+func matchesFilter(val model.WebAnalyzer, filter repository.ListFilter) bool {
+	if len(filter.Statuses) > 0 && !slices.Contains(filter.Statuses, val.Status) {
+		return false
+	}
+
+	if filter.URL != "" && val.URL != filter.URL {
+		return false
+	}
+
+	if filter.URLContains != "" && !strings.Contains(strings.ToLower(val.URL), strings.ToLower(filter.URLContains)) {
+		return false
+	}
+
+	if filter.HostContains != "" {
+		parsedURL, err := url.Parse(val.URL)
+		if err != nil || !strings.Contains(strings.ToLower(parsedURL.Host), strings.ToLower(filter.HostContains)) {
+			return false
+		}
+	}
+
+	if filter.CreatedFrom != nil && val.CreatedAt.Before(*filter.CreatedFrom) {
+		return false
+	}
+
+	if filter.CreatedTo != nil && !val.CreatedAt.Before(*filter.CreatedTo) {
+		return false
+	}
+
+	return true
+}
+
+func cloneWebAnalyzer(src model.WebAnalyzer) model.WebAnalyzer {
+	dst := src
+
+	if src.Headings != nil {
+		dst.Headings = make(map[string]int, len(src.Headings))
+		for level, count := range src.Headings {
+			dst.Headings[level] = count
+		}
+	}
+
+	if src.Links.InaccessibleDetails != nil {
+		dst.Links.InaccessibleDetails = make([]model.InaccessibleLink, len(src.Links.InaccessibleDetails))
+		for i, link := range src.Links.InaccessibleDetails {
+			if link.Kinds != nil {
+				link.Kinds = append([]string(nil), link.Kinds...)
+			}
+			dst.Links.InaccessibleDetails[i] = link
+		}
+	}
+
+	if src.Links.Redirected != nil {
+		dst.Links.Redirected = make([]model.LinkRedirect, len(src.Links.Redirected))
+		for i, redirect := range src.Links.Redirected {
+			redirect.Chain = cloneRedirectChain(redirect.Chain)
+			dst.Links.Redirected[i] = redirect
+		}
+	}
+
+	if src.Links.ErrorCategories != nil {
+		dst.Links.ErrorCategories = make(map[string]int, len(src.Links.ErrorCategories))
+		for category, count := range src.Links.ErrorCategories {
+			dst.Links.ErrorCategories[category] = count
+		}
+	}
+
+	if src.Links.Resources != nil {
+		dst.Links.Resources = make(map[string]model.ResourceStats, len(src.Links.Resources))
+		for kind, stats := range src.Links.Resources {
+			dst.Links.Resources[kind] = stats
+		}
+	}
+
+	if src.Links.SkippedRobots != nil {
+		dst.Links.SkippedRobots = make([]string, len(src.Links.SkippedRobots))
+		copy(dst.Links.SkippedRobots, src.Links.SkippedRobots)
+	}
+
+	dst.Redirects = cloneRedirectChain(src.Redirects)
+
+	if src.SEO != nil {
+		seo := *src.SEO
+		seo.Hreflang = slices.Clone(src.SEO.Hreflang)
+		seo.OpenGraph = maps.Clone(src.SEO.OpenGraph)
+		seo.TwitterCard = maps.Clone(src.SEO.TwitterCard)
+		seo.Issues = slices.Clone(src.SEO.Issues)
+		dst.SEO = &seo
+	}
+
+	if src.ErrorDescription != nil {
+		errorDescription := *src.ErrorDescription
+		dst.ErrorDescription = &errorDescription
+	}
+
+	if src.UpdatedAt != nil {
+		updatedAt := *src.UpdatedAt
+		dst.UpdatedAt = &updatedAt
+	}
+
+	return dst
+}
+
+func generateID() string {
+	id := uuid.New().String()
+	return id
+}
+
+func cloneRedirectChain(src []model.RedirectHop) []model.RedirectHop {
+	if src == nil {
+		return nil
+	}
+
+	dst := make([]model.RedirectHop, len(src))
+	copy(dst, src)
+	return dst
+}
@@ -0,0 +1,327 @@
+package repositorymemory
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+	"web-analyzer-api/app/internal/model"
+	"web-analyzer-api/app/internal/repository"
+	"web-analyzer-api/app/internal/util/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebAnalyzerRepo_FindByStatus(t *testing.T) {
+	log := logger.Get("info")
+	repo := NewWebAnalyzerRepo(log)
+
+	now := time.Now().UTC()
+	pendingID, _ := repo.Save(model.WebAnalyzer{URL: "http://pending.test", Status: "pending", CreatedAt: now.Add(time.Second)})
+	queuedID, _ := repo.Save(model.WebAnalyzer{URL: "http://queued.test", Status: "queued", CreatedAt: now})
+	_, _ = repo.Save(model.WebAnalyzer{URL: "http://success.test", Status: "success", CreatedAt: now})
+
+	found, err := repo.FindByStatus("queued", "pending")
+	assert.NoError(t, err)
+	if assert.Len(t, found, 2) {
+		assert.Equal(t, queuedID, found[0].ID)
+		assert.Equal(t, pendingID, found[1].ID)
+	}
+
+	found, err = repo.FindByStatus("failed")
+	assert.NoError(t, err)
+	assert.Empty(t, found)
+
+	found, err = repo.FindByStatus()
+	assert.NoError(t, err)
+	assert.Empty(t, found)
+}
+
+func TestWebAnalyzerRepo(t *testing.T) {
+	log := logger.Get("info")
+	repo := NewWebAnalyzerRepo(log)
+
+	t.Run("Save and GetById", func(t *testing.T) {
+		analysis := model.WebAnalyzer{
+			URL: "http://test.test",
+		}
+
+		id, err := repo.Save(analysis)
+		assert.NoError(t, err)
+		assert.NotEmpty(t, id)
+
+		// Get by valid ID
+		found, err := repo.GetById(id)
+		assert.NoError(t, err)
+		assert.NotNil(t, found)
+		assert.Equal(t, id, found.ID)
+		assert.Equal(t, "http://test.test", found.URL)
+		assert.False(t, found.CreatedAt.IsZero())
+
+		// Get by invalid ID
+		notFound, err := repo.GetById("123")
+		assert.NoError(t, err)
+		assert.Nil(t, notFound)
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		analysis := model.WebAnalyzer{
+			URL: "http://test.test",
+		}
+
+		id, _ := repo.Save(analysis)
+
+		updatedAnalysis := model.WebAnalyzer{
+			ID:     id,
+			URL:    "http://updated.test",
+			Status: "success",
+		}
+
+		updatedID, err := repo.Update(updatedAnalysis)
+		assert.NoError(t, err)
+		assert.Equal(t, id, updatedID)
+
+		// Verify update
+		found, _ := repo.GetById(id)
+		assert.Equal(t, "http://updated.test", found.URL)
+		assert.Equal(t, "success", found.Status)
+
+		// Update unavailable record
+		invalidUpdate := model.WebAnalyzer{ID: "123"}
+		_, err = repo.Update(invalidUpdate)
+		assert.Error(t, err)
+		assert.Equal(t, "record not found", err.Error())
+	})
+}
+
+func TestWebAnalyzerRepo_CopySemantics(t *testing.T) {
+	log := logger.Get("info")
+	repo := NewWebAnalyzerRepo(log)
+
+	errorDescription := "original"
+	analysis := model.WebAnalyzer{
+		URL:      "http://test.test",
+		Headings: map[string]int{"h1": 1},
+		Links: model.LinkAnalysis{
+			InaccessibleDetails: []model.InaccessibleLink{{URL: "http://test.test/a", StatusCode: 404}},
+		},
+		SEO: &model.SEOAnalysis{
+			OpenGraph: map[string]string{"title": "Test"},
+			Issues:    []model.SEOIssue{{Code: "noindex"}},
+		},
+		ErrorDescription: &errorDescription,
+	}
+
+	id, err := repo.Save(analysis)
+	require.NoError(t, err)
+
+	t.Run("Mutating the saved value does not change storage", func(t *testing.T) {
+		analysis.Headings["h1"] = 100
+		analysis.Links.InaccessibleDetails[0].StatusCode = 500
+		analysis.SEO.OpenGraph["title"] = "Changed"
+		errorDescription = "changed"
+
+		found, _ := repo.GetById(id)
+		assert.Equal(t, 1, found.Headings["h1"])
+		assert.Equal(t, 404, found.Links.InaccessibleDetails[0].StatusCode)
+		assert.Equal(t, "Test", found.SEO.OpenGraph["title"])
+		assert.Equal(t, "original", *found.ErrorDescription)
+	})
+
+	t.Run("Mutating a read value does not change storage", func(t *testing.T) {
+		found, _ := repo.GetById(id)
+		found.Headings["h2"] = 5
+		found.Links.InaccessibleDetails[0].URL = "http://mutated.test"
+		found.SEO.Issues[0].Code = "mutated"
+		*found.ErrorDescription = "mutated"
+
+		again, _ := repo.GetById(id)
+		assert.NotContains(t, again.Headings, "h2")
+		assert.Equal(t, "http://test.test/a", again.Links.InaccessibleDetails[0].URL)
+		assert.Equal(t, "noindex", again.SEO.Issues[0].Code)
+		assert.Equal(t, "original", *again.ErrorDescription)
+	})
+
+	t.Run("Mutating an updated value does not change storage", func(t *testing.T) {
+		found, _ := repo.GetById(id)
+		found.Headings = map[string]int{"h1": 2}
+		_, err := repo.Update(*found)
+		require.NoError(t, err)
+
+		found.Headings["h1"] = 3
+
+		again, _ := repo.GetById(id)
+		assert.Equal(t, 2, again.Headings["h1"])
+	})
+}
+
+// Run with -race to detect unsynchronized access to the underlying map.
+func TestWebAnalyzerRepo_ConcurrentAccess(t *testing.T) {
+	log := logger.Get("info")
+	repo := NewWebAnalyzerRepo(log)
+
+	const (
+		goroutines = 50
+		iterations = 100
+	)
+
+	ids := make([]string, goroutines)
+	for i := range ids {
+		id, err := repo.Save(model.WebAnalyzer{URL: fmt.Sprintf("http://test-%d.test", i), Headings: map[string]int{"h1": 0}})
+		require.NoError(t, err)
+		ids[i] = id
+	}
+
+	var wg sync.WaitGroup
+	for g := 0; g < goroutines; g++ {
+		wg.Add(3)
+
+		// Writer updating a record owned by this goroutine
+		go func(id string) {
+			defer wg.Done()
+			for i := 0; i < iterations; i++ {
+				found, err := repo.GetById(id)
+				if !assert.NoError(t, err) || !assert.NotNil(t, found) {
+					return
+				}
+				found.Headings["h1"]++
+				found.Links.InaccessibleDetails = append(found.Links.InaccessibleDetails, model.InaccessibleLink{URL: id, StatusCode: i})
+				_, err = repo.Update(*found)
+				assert.NoError(t, err)
+			}
+		}(ids[g])
+
+		// Reader mutating its copies of every record
+		go func() {
+			defer wg.Done()
+			for i := 0; i < iterations; i++ {
+				found, err := repo.GetById(ids[i%goroutines])
+				if assert.NoError(t, err) && assert.NotNil(t, found) {
+					found.Headings["h1"] = -1
+					for j := range found.Links.InaccessibleDetails {
+						found.Links.InaccessibleDetails[j].StatusCode = -1
+					}
+				}
+			}
+		}()
+
+		// Inserter adding new records
+		go func() {
+			defer wg.Done()
+			for i := 0; i < iterations; i++ {
+				_, err := repo.Save(model.WebAnalyzer{URL: "http://new.test"})
+				assert.NoError(t, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	for _, id := range ids {
+		found, err := repo.GetById(id)
+		require.NoError(t, err)
+		assert.Equal(t, iterations, found.Headings["h1"])
+		assert.Len(t, found.Links.InaccessibleDetails, iterations)
+		for i, detail := range found.Links.InaccessibleDetails {
+			assert.Equal(t, i, detail.StatusCode)
+		}
+	}
+}
+
+func TestWebAnalyzerRepo_List(t *testing.T) {
+	log := logger.Get("info")
+	repo := NewWebAnalyzerRepo(log)
+
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	seed := []model.WebAnalyzer{
+		{URL: "https://www.example.com/", Status: "success", CreatedAt: base},
+		{URL: "https://blog.example.com/post", Status: "failed", CreatedAt: base.Add(time.Hour)},
+		{URL: "https://other.test/example", Status: "success", CreatedAt: base.Add(2 * time.Hour)},
+		{URL: "https://www.example.com/sale?discount=100%25_off", Status: "pending", CreatedAt: base.Add(3 * time.Hour)},
+		{URL: "https://shop.test/", Status: "success", CreatedAt: base.Add(4 * time.Hour)},
+	}
+	ids := make([]string, len(seed))
+	for i, analysis := range seed {
+		ids[i], _ = repo.Save(analysis)
+	}
+
+	listIDs := func(items []model.WebAnalyzer) []string {
+		result := []string{}
+		for _, item := range items {
+			result = append(result, item.ID)
+		}
+		return result
+	}
+
+	t.Run("Cursor pagination newest first", func(t *testing.T) {
+		filter := repository.ListFilter{SortBy: repository.SortByCreatedAt, SortDesc: true, Limit: 2}
+
+		page, err := repo.List(filter)
+		require.NoError(t, err)
+		assert.Equal(t, []string{ids[4], ids[3]}, listIDs(page.Items))
+		require.NotEmpty(t, page.NextCursor)
+
+		filter.Cursor = page.NextCursor
+		page, err = repo.List(filter)
+		require.NoError(t, err)
+		assert.Equal(t, []string{ids[2], ids[1]}, listIDs(page.Items))
+
+		filter.Cursor = page.NextCursor
+		page, err = repo.List(filter)
+		require.NoError(t, err)
+		assert.Equal(t, []string{ids[0]}, listIDs(page.Items))
+		assert.Empty(t, page.NextCursor)
+	})
+
+	t.Run("Oldest first", func(t *testing.T) {
+		page, err := repo.List(repository.ListFilter{SortBy: repository.SortByCreatedAt, Limit: 10})
+		require.NoError(t, err)
+		assert.Equal(t, ids, listIDs(page.Items))
+		assert.Empty(t, page.NextCursor)
+	})
+
+	t.Run("Sort by update time", func(t *testing.T) {
+		found, _ := repo.GetById(ids[0])
+		_, err := repo.Update(*found)
+		require.NoError(t, err)
+
+		page, err := repo.List(repository.ListFilter{SortBy: repository.SortByUpdatedAt, SortDesc: true, Limit: 1})
+		require.NoError(t, err)
+		assert.Equal(t, []string{ids[0]}, listIDs(page.Items))
+	})
+
+	t.Run("Filters", func(t *testing.T) {
+		from := base.Add(time.Hour)
+		to := base.Add(4 * time.Hour)
+
+		tests := []struct {
+			name     string
+			filter   repository.ListFilter
+			expected []string
+		}{
+			{"Status", repository.ListFilter{Statuses: []string{"success"}}, []string{ids[0], ids[2], ids[4]}},
+			{"Multiple statuses", repository.ListFilter{Statuses: []string{"failed", "pending"}}, []string{ids[1], ids[3]}},
+			{"Exact URL", repository.ListFilter{URL: "https://www.example.com/"}, []string{ids[0]}},
+			{"URL substring", repository.ListFilter{URLContains: "EXAMPLE"}, []string{ids[0], ids[1], ids[2], ids[3]}},
+			{"URL substring with wildcards", repository.ListFilter{URLContains: "100%25_off"}, []string{ids[3]}},
+			{"Wildcards match literally", repository.ListFilter{URLContains: "e_a"}, []string{}},
+			{"Host substring", repository.ListFilter{HostContains: "example"}, []string{ids[0], ids[1], ids[3]}},
+			{"Created range", repository.ListFilter{CreatedFrom: &from, CreatedTo: &to}, []string{ids[1], ids[2], ids[3]}},
+			{"Combined", repository.ListFilter{Statuses: []string{"success"}, HostContains: "example"}, []string{ids[0]}},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				tt.filter.SortBy = repository.SortByCreatedAt
+				page, err := repo.List(tt.filter)
+				require.NoError(t, err)
+				assert.Equal(t, tt.expected, listIDs(page.Items))
+			})
+		}
+	})
+
+	t.Run("Invalid cursor", func(t *testing.T) {
+		_, err := repo.List(repository.ListFilter{Cursor: "not-a-cursor"})
+		assert.ErrorIs(t, err, repository.ErrInvalidCursor)
+	})
+}
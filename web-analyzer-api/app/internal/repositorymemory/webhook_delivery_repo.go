@@ -0,0 +1,50 @@
+package repositorymemory
+
+import (
+	"sort"
+	"sync"
+	"time"
+	"web-analyzer-api/app/internal/model"
+	"web-analyzer-api/app/internal/repository"
+	"web-analyzer-api/app/internal/util/logger"
+)
+
+type webhookDeliveryRepo struct {
+	log     *logger.Logger
+	mu      sync.RWMutex
+	storage map[string][]model.WebhookDelivery
+}
+
+func NewWebhookDeliveryRepo(logger *logger.Logger) repository.WebhookDeliveryRepository {
+	return &webhookDeliveryRepo{
+		log:     logger,
+		storage: make(map[string][]model.WebhookDelivery),
+	}
+}
+
+func (r *webhookDeliveryRepo) SaveDelivery(delivery model.WebhookDelivery) (string, error) {
+	delivery.ID = generateID()
+	if delivery.CreatedAt.IsZero() {
+		delivery.CreatedAt = time.Now().UTC()
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.storage[delivery.AnalysisID] = append(r.storage[delivery.AnalysisID], delivery)
+	return delivery.ID, nil
+}
+
+func (r *webhookDeliveryRepo) ListDeliveries(analysisId string) ([]model.WebhookDelivery, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	result := make([]model.WebhookDelivery, len(r.storage[analysisId]))
+	copy(result, r.storage[analysisId])
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].CreatedAt.Before(result[j].CreatedAt)
+	})
+
+	return result, nil
+}
@@ -0,0 +1,41 @@
+package repositorymemory
+
+import (
+	"testing"
+	"time"
+	"web-analyzer-api/app/internal/model"
+	"web-analyzer-api/app/internal/util/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhookDeliveryRepo(t *testing.T) {
+	log := logger.Get("info")
+	repo := NewWebhookDeliveryRepo(log)
+
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	_, err := repo.SaveDelivery(model.WebhookDelivery{AnalysisID: "a", Attempt: 2, StatusCode: 200, Success: true, CreatedAt: base.Add(time.Second)})
+	require.NoError(t, err)
+	id, err := repo.SaveDelivery(model.WebhookDelivery{AnalysisID: "a", Attempt: 1, StatusCode: 500, Error: "server error", CreatedAt: base})
+	require.NoError(t, err)
+	assert.NotEmpty(t, id)
+	_, err = repo.SaveDelivery(model.WebhookDelivery{AnalysisID: "b", Attempt: 1})
+	require.NoError(t, err)
+
+	deliveries, err := repo.ListDeliveries("a")
+	require.NoError(t, err)
+	require.Len(t, deliveries, 2)
+	assert.Equal(t, id, deliveries[0].ID)
+	assert.Equal(t, 1, deliveries[0].Attempt)
+	assert.Equal(t, 2, deliveries[1].Attempt)
+
+	// The returned slice is a copy
+	deliveries[0].Attempt = 10
+	deliveries, _ = repo.ListDeliveries("a")
+	assert.Equal(t, 1, deliveries[0].Attempt)
+
+	deliveries, err = repo.ListDeliveries("missing")
+	require.NoError(t, err)
+	assert.Empty(t, deliveries)
+}
@@ -0,0 +1,82 @@
+package repositorysql
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+	"web-analyzer-api/app/internal/model"
+	"web-analyzer-api/app/internal/repository"
+	"web-analyzer-api/app/internal/util/logger"
+)
+
+type batchRepo struct {
+	log *logger.Logger
+	db  *sql.DB
+}
+
+func NewBatchRepo(logger *logger.Logger, db *sql.DB) repository.BatchRepository {
+	return &batchRepo{
+		log: logger,
+		db:  db,
+	}
+}
+
+func (r *batchRepo) SaveBatch(batch model.Batch) (string, error) {
+	batch.ID = generateID()
+	if batch.CreatedAt.IsZero() {
+		batch.CreatedAt = time.Now().UTC()
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`INSERT INTO batches (id, created_at) VALUES (?, ?)`, batch.ID, toUnixNano(batch.CreatedAt)); err != nil {
+		return "", err
+	}
+
+	for i, analysisId := range batch.AnalysisIDs {
+		_, err := tx.Exec(`INSERT INTO batch_analyses (batch_id, position, analysis_id) VALUES (?, ?, ?)`, batch.ID, i, analysisId)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return "", err
+	}
+
+	return batch.ID, nil
+}
+
+func (r *batchRepo) GetBatch(id string) (*model.Batch, error) {
+	batch := model.Batch{ID: id, AnalysisIDs: []string{}}
+
+	var createdAt int64
+	err := r.db.QueryRow(`SELECT created_at FROM batches WHERE id = ?`, id).Scan(&createdAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	batch.CreatedAt = time.Unix(0, createdAt).UTC()
+
+	rows, err := r.db.Query(`SELECT analysis_id FROM batch_analyses WHERE batch_id = ? ORDER BY position`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var analysisId string
+		if err := rows.Scan(&analysisId); err != nil {
+			return nil, err
+		}
+		batch.AnalysisIDs = append(batch.AnalysisIDs, analysisId)
+	}
+
+	return &batch, rows.Err()
+}
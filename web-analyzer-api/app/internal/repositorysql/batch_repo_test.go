@@ -0,0 +1,42 @@
+package repositorysql
+
+import (
+	"testing"
+	"time"
+	"web-analyzer-api/app/internal/model"
+	"web-analyzer-api/app/internal/util/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBatchRepo(t *testing.T) {
+	log := logger.Get("info")
+	db := setupTestDB(t)
+	analyses := NewWebAnalyzerRepo(log, db)
+	repo := NewBatchRepo(log, db)
+
+	var analysisIDs []string
+	for _, u := range []string{"http://b.test", "http://a.test", "http://c.test"} {
+		id, err := analyses.Save(model.WebAnalyzer{URL: u, Status: "queued"})
+		require.NoError(t, err)
+		analysisIDs = append(analysisIDs, id)
+	}
+
+	createdAt := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	id, err := repo.SaveBatch(model.Batch{AnalysisIDs: analysisIDs, CreatedAt: createdAt})
+	require.NoError(t, err)
+
+	batch, err := repo.GetBatch(id)
+	require.NoError(t, err)
+	assert.Equal(t, &model.Batch{ID: id, AnalysisIDs: analysisIDs, CreatedAt: createdAt}, batch)
+
+	batch, err = repo.GetBatch("missing")
+	require.NoError(t, err)
+	assert.Nil(t, batch)
+
+	t.Run("Unknown analysis", func(t *testing.T) {
+		_, err := repo.SaveBatch(model.Batch{AnalysisIDs: []string{analysisIDs[0], "missing"}})
+		assert.Error(t, err)
+	})
+}
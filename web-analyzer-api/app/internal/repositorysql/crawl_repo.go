@@ -0,0 +1,266 @@
+package repositorysql
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+	"web-analyzer-api/app/internal/model"
+	"web-analyzer-api/app/internal/repository"
+	"web-analyzer-api/app/internal/util/logger"
+)
+
+type crawlRepo struct {
+	log *logger.Logger
+	db  *sql.DB
+}
+
+func NewCrawlRepo(logger *logger.Logger, db *sql.DB) repository.CrawlRepository {
+	return &crawlRepo{
+		log: logger,
+		db:  db,
+	}
+}
+
+func (r *crawlRepo) SaveCrawl(crawl model.Crawl) (string, error) {
+	crawl.ID = generateID()
+	if crawl.CreatedAt.IsZero() {
+		crawl.CreatedAt = time.Now().UTC()
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`INSERT INTO crawls (id, url, source, status, max_depth, max_pages, sitemap_pages, sitemap_not_analyzed, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		crawl.ID, crawl.URL, crawl.Source, crawl.Status, crawl.MaxDepth, crawl.MaxPages, crawl.Sitemap.Pages, crawl.Sitemap.NotAnalyzed, toUnixNano(crawl.CreatedAt), toNullUnixNano(crawl.UpdatedAt))
+	if err != nil {
+		return "", err
+	}
+
+	if err := insertCrawlPages(tx, crawl); err != nil {
+		return "", err
+	}
+	if err := insertCrawlSitemap(tx, crawl); err != nil {
+		return "", err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return "", err
+	}
+
+	return crawl.ID, nil
+}
+
+func (r *crawlRepo) UpdateCrawl(crawl model.Crawl) error {
+	updatedAt := time.Now().UTC()
+	crawl.UpdatedAt = &updatedAt
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`UPDATE crawls SET url = ?, source = ?, status = ?, max_depth = ?, max_pages = ?, sitemap_pages = ?, sitemap_not_analyzed = ?, updated_at = ? WHERE id = ?`,
+		crawl.URL, crawl.Source, crawl.Status, crawl.MaxDepth, crawl.MaxPages, crawl.Sitemap.Pages, crawl.Sitemap.NotAnalyzed, toNullUnixNano(crawl.UpdatedAt), crawl.ID)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return repository.ErrRecordNotFound
+	}
+
+	if _, err := tx.Exec(`DELETE FROM crawl_pages WHERE crawl_id = ?`, crawl.ID); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM crawl_page_links WHERE crawl_id = ?`, crawl.ID); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM crawl_sitemaps WHERE crawl_id = ?`, crawl.ID); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM crawl_pages_not_in_sitemap WHERE crawl_id = ?`, crawl.ID); err != nil {
+		return err
+	}
+
+	if err := insertCrawlPages(tx, crawl); err != nil {
+		return err
+	}
+	if err := insertCrawlSitemap(tx, crawl); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *crawlRepo) GetCrawl(id string) (*model.Crawl, error) {
+	crawl := model.Crawl{ID: id}
+
+	var (
+		createdAt int64
+		updatedAt sql.NullInt64
+	)
+	err := r.db.QueryRow(`SELECT url, source, status, max_depth, max_pages, sitemap_pages, sitemap_not_analyzed, created_at, updated_at FROM crawls WHERE id = ?`, id).
+		Scan(&crawl.URL, &crawl.Source, &crawl.Status, &crawl.MaxDepth, &crawl.MaxPages, &crawl.Sitemap.Pages, &crawl.Sitemap.NotAnalyzed, &createdAt, &updatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	crawl.CreatedAt = time.Unix(0, createdAt).UTC()
+	if updatedAt.Valid {
+		t := time.Unix(0, updatedAt.Int64).UTC()
+		crawl.UpdatedAt = &t
+	}
+
+	if crawl.Pages, err = r.getCrawlPages(id); err != nil {
+		return nil, err
+	}
+	if crawl.Sitemap.Sitemaps, err = r.queryStrings(`SELECT url FROM crawl_sitemaps WHERE crawl_id = ? ORDER BY position`, id); err != nil {
+		return nil, err
+	}
+	if crawl.Sitemap.NotInSitemap, err = r.queryStrings(`SELECT url FROM crawl_pages_not_in_sitemap WHERE crawl_id = ? ORDER BY position`, id); err != nil {
+		return nil, err
+	}
+
+	return &crawl, nil
+}
+
+func (r *crawlRepo) FindCrawlsByStatus(statuses ...string) ([]model.Crawl, error) {
+	if len(statuses) == 0 {
+		return []model.Crawl{}, nil
+	}
+
+	args := make([]any, len(statuses))
+	for i, status := range statuses {
+		args[i] = status
+	}
+
+	ids, err := r.queryStrings(`SELECT id FROM crawls WHERE status IN (?`+strings.Repeat(", ?", len(statuses)-1)+`) ORDER BY created_at, id`, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	crawls := make([]model.Crawl, 0, len(ids))
+	for _, id := range ids {
+		crawl, err := r.GetCrawl(id)
+		if err != nil {
+			return nil, err
+		}
+		if crawl != nil {
+			crawls = append(crawls, *crawl)
+		}
+	}
+	return crawls, nil
+}
+
+func (r *crawlRepo) getCrawlPages(id string) ([]model.CrawlPage, error) {
+	rows, err := r.db.Query(`SELECT url, depth, analysis_id, in_sitemap FROM crawl_pages WHERE crawl_id = ? ORDER BY position`, id)
+	if err != nil {
+		return nil, err
+	}
+
+	var pages []model.CrawlPage
+	for rows.Next() {
+		var page model.CrawlPage
+		if err := rows.Scan(&page.URL, &page.Depth, &page.AnalysisID, &page.InSitemap); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		pages = append(pages, page)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(pages) == 0 {
+		return pages, nil
+	}
+
+	links, err := r.db.Query(`SELECT page_position, url FROM crawl_page_links
+		WHERE crawl_id = ? ORDER BY page_position, position`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer links.Close()
+
+	for links.Next() {
+		var (
+			pagePosition int
+			link         string
+		)
+		if err := links.Scan(&pagePosition, &link); err != nil {
+			return nil, err
+		}
+		if pagePosition >= 0 && pagePosition < len(pages) {
+			pages[pagePosition].Links = append(pages[pagePosition].Links, link)
+		}
+	}
+
+	return pages, links.Err()
+}
+
+// queryStrings returns the single column of text selected by query.
+func (r *crawlRepo) queryStrings(query string, args ...any) ([]string, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var urls []string
+	for rows.Next() {
+		var u string
+		if err := rows.Scan(&u); err != nil {
+			return nil, err
+		}
+		urls = append(urls, u)
+	}
+	return urls, rows.Err()
+}
+
+func insertCrawlPages(tx *sql.Tx, crawl model.Crawl) error {
+	for i, page := range crawl.Pages {
+		_, err := tx.Exec(`INSERT INTO crawl_pages (crawl_id, position, url, depth, analysis_id, in_sitemap) VALUES (?, ?, ?, ?, ?, ?)`,
+			crawl.ID, i, page.URL, page.Depth, page.AnalysisID, page.InSitemap)
+		if err != nil {
+			return err
+		}
+
+		for j, link := range page.Links {
+			_, err := tx.Exec(`INSERT INTO crawl_page_links (crawl_id, page_position, position, url) VALUES (?, ?, ?, ?)`,
+				crawl.ID, i, j, link)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func insertCrawlSitemap(tx *sql.Tx, crawl model.Crawl) error {
+	for i, sitemap := range crawl.Sitemap.Sitemaps {
+		if _, err := tx.Exec(`INSERT INTO crawl_sitemaps (crawl_id, position, url) VALUES (?, ?, ?)`, crawl.ID, i, sitemap); err != nil {
+			return err
+		}
+	}
+
+	for i, page := range crawl.Sitemap.NotInSitemap {
+		if _, err := tx.Exec(`INSERT INTO crawl_pages_not_in_sitemap (crawl_id, position, url) VALUES (?, ?, ?)`, crawl.ID, i, page); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,91 @@
+package repositorysql
+
+import (
+	"testing"
+	"time"
+	"web-analyzer-api/app/internal/model"
+	"web-analyzer-api/app/internal/repository"
+	"web-analyzer-api/app/internal/util/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCrawlRepo(t *testing.T) {
+	log := logger.Get("info")
+	db := setupTestDB(t)
+	analyses := NewWebAnalyzerRepo(log, db)
+	repo := NewCrawlRepo(log, db)
+
+	var analysisIDs []string
+	for _, u := range []string{"http://a.test/", "http://a.test/about", "http://a.test/team"} {
+		id, err := analyses.Save(model.WebAnalyzer{URL: u, Status: "queued"})
+		require.NoError(t, err)
+		analysisIDs = append(analysisIDs, id)
+	}
+
+	createdAt := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	id, err := repo.SaveCrawl(model.Crawl{
+		URL:       "http://a.test/",
+		Status:    "running",
+		MaxDepth:  2,
+		MaxPages:  10,
+		Pages:     []model.CrawlPage{{URL: "http://a.test/", AnalysisID: analysisIDs[0]}},
+		CreatedAt: createdAt,
+	})
+	require.NoError(t, err)
+
+	crawl, err := repo.GetCrawl(id)
+	require.NoError(t, err)
+	assert.Equal(t, &model.Crawl{
+		ID:        id,
+		URL:       "http://a.test/",
+		Status:    "running",
+		MaxDepth:  2,
+		MaxPages:  10,
+		Pages:     []model.CrawlPage{{URL: "http://a.test/", AnalysisID: analysisIDs[0]}},
+		CreatedAt: createdAt,
+	}, crawl)
+
+	pages := []model.CrawlPage{
+		{URL: "http://a.test/", AnalysisID: analysisIDs[0], Links: []string{"http://a.test/about", "http://a.test/team"}, InSitemap: true},
+		{URL: "http://a.test/about", Depth: 1, AnalysisID: analysisIDs[1], Links: []string{"http://a.test/"}},
+		{URL: "http://a.test/team", Depth: 1, AnalysisID: analysisIDs[2], InSitemap: true},
+	}
+	sitemap := model.CrawlSitemap{
+		Sitemaps:     []string{"http://a.test/sitemap_index.xml", "http://a.test/sitemap.xml.gz"},
+		Pages:        2,
+		NotAnalyzed:  1,
+		NotInSitemap: []string{"http://a.test/about"},
+	}
+	err = repo.UpdateCrawl(model.Crawl{ID: id, URL: "http://a.test/", Source: "sitemap", Status: "completed", MaxDepth: 2, MaxPages: 10, Pages: pages, Sitemap: sitemap})
+	require.NoError(t, err)
+
+	crawl, err = repo.GetCrawl(id)
+	require.NoError(t, err)
+	assert.Equal(t, "completed", crawl.Status)
+	assert.Equal(t, "sitemap", crawl.Source)
+	assert.Equal(t, pages, crawl.Pages)
+	assert.Equal(t, sitemap, crawl.Sitemap)
+	assert.Equal(t, createdAt, crawl.CreatedAt)
+	assert.NotNil(t, crawl.UpdatedAt)
+
+	running, err := repo.SaveCrawl(model.Crawl{URL: "http://b.test/", Status: "running"})
+	require.NoError(t, err)
+	found, err := repo.FindCrawlsByStatus("running")
+	require.NoError(t, err)
+	require.Len(t, found, 1)
+	assert.Equal(t, running, found[0].ID)
+	found, err = repo.FindCrawlsByStatus("running", "completed")
+	require.NoError(t, err)
+	assert.Len(t, found, 2)
+	found, err = repo.FindCrawlsByStatus()
+	require.NoError(t, err)
+	assert.Empty(t, found)
+
+	assert.ErrorIs(t, repo.UpdateCrawl(model.Crawl{ID: "missing"}), repository.ErrRecordNotFound)
+
+	crawl, err = repo.GetCrawl("missing")
+	require.NoError(t, err)
+	assert.Nil(t, crawl)
+}
@@ -0,0 +1,295 @@
+package repositorysql
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// migrations are applied in order and must never be edited once released; add a new entry instead.
+var migrations = []string{
+	// 1: analyses with normalized headings and inaccessible links
+	`CREATE TABLE web_analyses (
+		id                 TEXT PRIMARY KEY,
+		url                TEXT NOT NULL,
+		html_version       TEXT NOT NULL DEFAULT '',
+		title              TEXT NOT NULL DEFAULT '',
+		has_login_form     INTEGER NOT NULL DEFAULT 0,
+		status             TEXT NOT NULL DEFAULT '',
+		error_description  TEXT,
+		internal_links     INTEGER NOT NULL DEFAULT 0,
+		external_links     INTEGER NOT NULL DEFAULT 0,
+		inaccessible_links INTEGER NOT NULL DEFAULT 0,
+		created_at         INTEGER,
+		updated_at         INTEGER
+	);
+
+	CREATE TABLE web_analysis_headings (
+		analysis_id TEXT NOT NULL REFERENCES web_analyses(id) ON DELETE CASCADE,
+		level       TEXT NOT NULL,
+		count       INTEGER NOT NULL,
+		PRIMARY KEY (analysis_id, level)
+	);
+
+	CREATE TABLE web_analysis_inaccessible_links (
+		analysis_id TEXT NOT NULL REFERENCES web_analyses(id) ON DELETE CASCADE,
+		position    INTEGER NOT NULL,
+		url         TEXT NOT NULL,
+		status_code INTEGER NOT NULL,
+		PRIMARY KEY (analysis_id, position)
+	);`,
+
+	// 2: host column and indexes for listing analyses
+	`ALTER TABLE web_analyses ADD COLUMN host TEXT NOT NULL DEFAULT '';
+
+	UPDATE web_analyses SET host = lower(
+		CASE WHEN instr(substr(url, instr(url, '://') + 3), '/') > 0
+			THEN substr(substr(url, instr(url, '://') + 3), 1, instr(substr(url, instr(url, '://') + 3), '/') - 1)
+			ELSE substr(url, instr(url, '://') + 3)
+		END);
+
+	CREATE INDEX idx_web_analyses_created_at ON web_analyses (COALESCE(created_at, 0), id);
+	CREATE INDEX idx_web_analyses_updated_at ON web_analyses (COALESCE(updated_at, created_at, 0), id);
+	CREATE INDEX idx_web_analyses_status ON web_analyses (status);`,
+
+	// 3: lookup of the run history of a single URL
+	`CREATE INDEX idx_web_analyses_url ON web_analyses (url, COALESCE(created_at, 0), id);`,
+
+	// 4: webhook callbacks and their delivery log
+	`ALTER TABLE web_analyses ADD COLUMN callback_url TEXT NOT NULL DEFAULT '';
+
+	CREATE TABLE webhook_deliveries (
+		id          TEXT PRIMARY KEY,
+		analysis_id TEXT NOT NULL REFERENCES web_analyses(id) ON DELETE CASCADE,
+		url         TEXT NOT NULL,
+		event       TEXT NOT NULL,
+		attempt     INTEGER NOT NULL,
+		status_code INTEGER NOT NULL DEFAULT 0,
+		success     INTEGER NOT NULL DEFAULT 0,
+		error       TEXT NOT NULL DEFAULT '',
+		duration_ms INTEGER NOT NULL DEFAULT 0,
+		created_at  INTEGER NOT NULL
+	);
+
+	CREATE INDEX idx_webhook_deliveries_analysis ON webhook_deliveries (analysis_id, created_at);`,
+
+	// 5: batches of analyses submitted together
+	`CREATE TABLE batches (
+		id         TEXT PRIMARY KEY,
+		created_at INTEGER NOT NULL
+	);
+
+	CREATE TABLE batch_analyses (
+		batch_id    TEXT NOT NULL REFERENCES batches(id) ON DELETE CASCADE,
+		position    INTEGER NOT NULL,
+		analysis_id TEXT NOT NULL REFERENCES web_analyses(id) ON DELETE CASCADE,
+		PRIMARY KEY (batch_id, position)
+	);`,
+
+	// 6: per-request analysis options, zero values mean the server defaults
+	`ALTER TABLE web_analyses ADD COLUMN skip_link_check INTEGER NOT NULL DEFAULT 0;
+	ALTER TABLE web_analyses ADD COLUMN max_links INTEGER NOT NULL DEFAULT 0;
+	ALTER TABLE web_analyses ADD COLUMN link_workers INTEGER NOT NULL DEFAULT 0;
+	ALTER TABLE web_analyses ADD COLUMN link_timeout_ms INTEGER NOT NULL DEFAULT 0;
+	ALTER TABLE web_analyses ADD COLUMN follow_redirects INTEGER NOT NULL DEFAULT 0;
+	ALTER TABLE web_analyses ADD COLUMN skip_external_links INTEGER NOT NULL DEFAULT 0;
+	ALTER TABLE web_analyses ADD COLUMN user_agent TEXT NOT NULL DEFAULT '';`,
+
+	// 7: redirect chains; redirects are now followed unless skipped, so earlier analyses keep their behaviour
+	`ALTER TABLE web_analyses RENAME COLUMN follow_redirects TO skip_redirects;
+	UPDATE web_analyses SET skip_redirects = 1 - skip_redirects;
+	ALTER TABLE web_analyses ADD COLUMN final_url TEXT NOT NULL DEFAULT '';
+
+	CREATE TABLE web_analysis_page_redirects (
+		analysis_id TEXT NOT NULL REFERENCES web_analyses(id) ON DELETE CASCADE,
+		position    INTEGER NOT NULL,
+		url         TEXT NOT NULL,
+		status_code INTEGER NOT NULL,
+		PRIMARY KEY (analysis_id, position)
+	);
+
+	CREATE TABLE web_analysis_link_redirects (
+		analysis_id TEXT NOT NULL REFERENCES web_analyses(id) ON DELETE CASCADE,
+		position    INTEGER NOT NULL,
+		url         TEXT NOT NULL,
+		final_url   TEXT NOT NULL,
+		status_code INTEGER NOT NULL,
+		error       TEXT NOT NULL DEFAULT '',
+		PRIMARY KEY (analysis_id, position)
+	);
+
+	CREATE TABLE web_analysis_link_redirect_hops (
+		analysis_id   TEXT NOT NULL REFERENCES web_analyses(id) ON DELETE CASCADE,
+		link_position INTEGER NOT NULL,
+		position      INTEGER NOT NULL,
+		url           TEXT NOT NULL,
+		status_code   INTEGER NOT NULL,
+		PRIMARY KEY (analysis_id, link_position, position)
+	);`,
+
+	// 8: error categories of inaccessible links, derived from the status code for earlier analyses
+	`ALTER TABLE web_analysis_inaccessible_links ADD COLUMN category TEXT NOT NULL DEFAULT '';
+	ALTER TABLE web_analysis_inaccessible_links ADD COLUMN message TEXT NOT NULL DEFAULT '';
+	UPDATE web_analysis_inaccessible_links SET category = CASE
+		WHEN status_code >= 500 THEN 'http_5xx'
+		WHEN status_code >= 400 THEN 'http_4xx'
+		ELSE 'network'
+	END;`,
+
+	// 9: detected character encoding of the page
+	`ALTER TABLE web_analyses ADD COLUMN encoding TEXT NOT NULL DEFAULT '';`,
+
+	// 10: robots.txt option and the links it skipped
+	`ALTER TABLE web_analyses ADD COLUMN respect_robots INTEGER NOT NULL DEFAULT 0;
+
+	CREATE TABLE web_analysis_robots_skipped_links (
+		analysis_id TEXT NOT NULL REFERENCES web_analyses(id) ON DELETE CASCADE,
+		position    INTEGER NOT NULL,
+		url         TEXT NOT NULL,
+		PRIMARY KEY (analysis_id, position)
+	);`,
+
+	// 11: links are counted once per unique URL; earlier counts were occurrences
+	`ALTER TABLE web_analyses ADD COLUMN internal_occurrences INTEGER NOT NULL DEFAULT 0;
+	ALTER TABLE web_analyses ADD COLUMN external_occurrences INTEGER NOT NULL DEFAULT 0;
+	UPDATE web_analyses SET internal_occurrences = internal_links, external_occurrences = external_links;`,
+
+	// 12: resources of every kind are checked and counted per kind
+	`ALTER TABLE web_analysis_inaccessible_links ADD COLUMN kinds TEXT NOT NULL DEFAULT '';
+
+	CREATE TABLE web_analysis_resource_counts (
+		analysis_id TEXT NOT NULL REFERENCES web_analyses(id) ON DELETE CASCADE,
+		kind        TEXT NOT NULL,
+		internal    INTEGER NOT NULL,
+		external    INTEGER NOT NULL,
+		broken      INTEGER NOT NULL,
+		PRIMARY KEY (analysis_id, kind)
+	);`,
+
+	// 13: site crawls, their pages and the links between them
+	`CREATE TABLE crawls (
+		id         TEXT PRIMARY KEY,
+		url        TEXT NOT NULL,
+		status     TEXT NOT NULL,
+		max_depth  INTEGER NOT NULL,
+		max_pages  INTEGER NOT NULL,
+		created_at INTEGER NOT NULL,
+		updated_at INTEGER
+	);
+
+	CREATE TABLE crawl_pages (
+		crawl_id    TEXT NOT NULL REFERENCES crawls(id) ON DELETE CASCADE,
+		position    INTEGER NOT NULL,
+		url         TEXT NOT NULL,
+		depth       INTEGER NOT NULL,
+		analysis_id TEXT NOT NULL REFERENCES web_analyses(id) ON DELETE CASCADE,
+		PRIMARY KEY (crawl_id, position)
+	);
+
+	CREATE TABLE crawl_page_links (
+		crawl_id      TEXT NOT NULL REFERENCES crawls(id) ON DELETE CASCADE,
+		page_position INTEGER NOT NULL,
+		position      INTEGER NOT NULL,
+		url           TEXT NOT NULL,
+		PRIMARY KEY (crawl_id, page_position, position)
+	);`,
+
+	// 14: crawls seeded from the sitemaps of a site
+	`ALTER TABLE crawls ADD COLUMN source TEXT NOT NULL DEFAULT 'links';
+	ALTER TABLE crawls ADD COLUMN sitemap_pages INTEGER NOT NULL DEFAULT 0;
+	ALTER TABLE crawl_pages ADD COLUMN in_sitemap INTEGER NOT NULL DEFAULT 0;
+
+	CREATE TABLE crawl_sitemaps (
+		crawl_id TEXT NOT NULL REFERENCES crawls(id) ON DELETE CASCADE,
+		position INTEGER NOT NULL,
+		url      TEXT NOT NULL,
+		PRIMARY KEY (crawl_id, position)
+	);
+
+	CREATE TABLE crawl_pages_not_in_sitemap (
+		crawl_id TEXT NOT NULL REFERENCES crawls(id) ON DELETE CASCADE,
+		position INTEGER NOT NULL,
+		url      TEXT NOT NULL,
+		PRIMARY KEY (crawl_id, position)
+	);`,
+
+	// 15: SEO metadata and checks
+	`CREATE TABLE web_analysis_seo (
+		analysis_id TEXT PRIMARY KEY REFERENCES web_analyses(id) ON DELETE CASCADE,
+		title_count INTEGER NOT NULL,
+		description TEXT NOT NULL,
+		robots      TEXT NOT NULL,
+		canonical   TEXT NOT NULL,
+		viewport    TEXT NOT NULL,
+		lang        TEXT NOT NULL
+	);
+
+	CREATE TABLE web_analysis_seo_hreflang (
+		analysis_id TEXT NOT NULL REFERENCES web_analyses(id) ON DELETE CASCADE,
+		position    INTEGER NOT NULL,
+		lang        TEXT NOT NULL,
+		url         TEXT NOT NULL,
+		PRIMARY KEY (analysis_id, position)
+	);
+
+	CREATE TABLE web_analysis_seo_properties (
+		analysis_id TEXT NOT NULL REFERENCES web_analyses(id) ON DELETE CASCADE,
+		kind        TEXT NOT NULL,
+		name        TEXT NOT NULL,
+		value       TEXT NOT NULL,
+		PRIMARY KEY (analysis_id, kind, name)
+	);
+
+	CREATE TABLE web_analysis_seo_issues (
+		analysis_id TEXT NOT NULL REFERENCES web_analyses(id) ON DELETE CASCADE,
+		position    INTEGER NOT NULL,
+		code        TEXT NOT NULL,
+		message     TEXT NOT NULL,
+		PRIMARY KEY (analysis_id, position)
+	);`,
+
+	// 16: listed pages left out of sitemap crawls
+	`ALTER TABLE crawls ADD COLUMN sitemap_not_analyzed INTEGER NOT NULL DEFAULT 0;`,
+}
+
+func migrate(db *sql.DB) error {
+	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+		version    INTEGER PRIMARY KEY,
+		applied_at INTEGER NOT NULL
+	)`)
+	if err != nil {
+		return fmt.Errorf("create schema_migrations table: %w", err)
+	}
+
+	var current int
+	if err := db.QueryRow(`SELECT COALESCE(MAX(version), 0) FROM schema_migrations`).Scan(&current); err != nil {
+		return fmt.Errorf("read schema version: %w", err)
+	}
+
+	for i := current; i < len(migrations); i++ {
+		version := i + 1
+		if err := applyMigration(db, version, migrations[i]); err != nil {
+			return fmt.Errorf("apply migration %d: %w", version, err)
+		}
+	}
+
+	return nil
+}
+
+func applyMigration(db *sql.DB, version int, statement string) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(statement); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(`INSERT INTO schema_migrations (version, applied_at) VALUES (?, ?)`, version, time.Now().UnixNano()); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
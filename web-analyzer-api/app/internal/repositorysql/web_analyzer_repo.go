@@ -1,7 +1,12 @@
 package repositorysql
 
 import (
+	"database/sql"
 	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
 	"web-analyzer-api/app/internal/model"
 	"web-analyzer-api/app/internal/repository"
 	"web-analyzer-api/app/internal/util/logger"
@@ -9,41 +14,759 @@ import (
 	"github.com/google/uuid"
 )
 
+// Kinds of the SEO properties stored in web_analysis_seo_properties.
+const (
+	seoPropertyOpenGraph   = "og"
+	seoPropertyTwitterCard = "twitter"
+)
+
 type webAnalyzerRepo struct {
-	log     *logger.Logger
-	storage map[string]model.WebAnalyzer
+	log *logger.Logger
+	db  *sql.DB
 }
 
-func NewWebAnalyzerRepo(logger *logger.Logger) repository.WebAnalyzerRepository {
+func NewWebAnalyzerRepo(logger *logger.Logger, db *sql.DB) repository.WebAnalyzerRepository {
 	return &webAnalyzerRepo{
-		log:     logger,
-		storage: make(map[string]model.WebAnalyzer),
+		log: logger,
+		db:  db,
 	}
 }
 
 func (r *webAnalyzerRepo) Save(webAnalyzer model.WebAnalyzer) (string, error) {
-	id := generateID()
-	webAnalyzer.ID = id
-	r.storage[id] = webAnalyzer
-	return id, nil
+	webAnalyzer.ID = generateID()
+	if webAnalyzer.CreatedAt.IsZero() {
+		webAnalyzer.CreatedAt = time.Now().UTC()
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`INSERT INTO web_analyses (
+			id, url, html_version, title, has_login_form, status, error_description,
+			internal_links, external_links, inaccessible_links, created_at, updated_at, host, callback_url,
+			skip_link_check, max_links, link_workers, link_timeout_ms, skip_redirects, skip_external_links, user_agent, final_url,
+			encoding, respect_robots, internal_occurrences, external_occurrences
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		webAnalyzer.ID, webAnalyzer.URL, webAnalyzer.HTMLVersion, webAnalyzer.Title, webAnalyzer.HasLoginForm,
+		webAnalyzer.Status, webAnalyzer.ErrorDescription, webAnalyzer.Links.Internal, webAnalyzer.Links.External,
+		webAnalyzer.Links.Inaccessible, toUnixNano(webAnalyzer.CreatedAt), toNullUnixNano(webAnalyzer.UpdatedAt), hostOf(webAnalyzer.URL),
+		webAnalyzer.CallbackURL, webAnalyzer.Options.SkipLinkCheck, webAnalyzer.Options.MaxLinks, webAnalyzer.Options.LinkWorkers,
+		webAnalyzer.Options.LinkTimeout.Milliseconds(), webAnalyzer.Options.SkipRedirects, webAnalyzer.Options.SkipExternalLinks,
+		webAnalyzer.Options.UserAgent, webAnalyzer.FinalURL, webAnalyzer.Encoding, webAnalyzer.Options.RespectRobots,
+		webAnalyzer.Links.InternalOccurrences, webAnalyzer.Links.ExternalOccurrences)
+	if err != nil {
+		return "", err
+	}
+
+	if err := insertChildren(tx, webAnalyzer); err != nil {
+		return "", err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return "", err
+	}
+
+	return webAnalyzer.ID, nil
 }
 
+const analysisColumns = `id, url, html_version, title, has_login_form, status, error_description,
+	internal_links, external_links, inaccessible_links, created_at, updated_at, callback_url,
+	skip_link_check, max_links, link_workers, link_timeout_ms, skip_redirects, skip_external_links, user_agent, final_url,
+	encoding, respect_robots, internal_occurrences, external_occurrences`
+
 func (r *webAnalyzerRepo) GetById(id string) (*model.WebAnalyzer, error) {
-	if val, ok := r.storage[id]; ok {
-		return &val, nil
+	analysis, err := scanAnalysis(r.db.QueryRow(`SELECT `+analysisColumns+` FROM web_analyses WHERE id = ?`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
 	}
-	return nil, nil
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.loadChildren(analysis); err != nil {
+		return nil, err
+	}
+
+	return analysis, nil
+}
+
+func (r *webAnalyzerRepo) FindByStatus(statuses ...string) ([]model.WebAnalyzer, error) {
+	if len(statuses) == 0 {
+		return []model.WebAnalyzer{}, nil
+	}
+
+	args := make([]any, len(statuses))
+	for i, status := range statuses {
+		args[i] = status
+	}
+
+	return r.queryAnalyses(`SELECT `+analysisColumns+` FROM web_analyses
+		WHERE status IN (?`+strings.Repeat(", ?", len(statuses)-1)+`)
+		ORDER BY created_at, id`, args...)
 }
 
 func (r *webAnalyzerRepo) Update(webAnalyzer model.WebAnalyzer) (string, error) {
-	if _, ok := r.storage[webAnalyzer.ID]; !ok {
-		return "", errors.New("record not found")
+	updatedAt := time.Now().UTC()
+	webAnalyzer.UpdatedAt = &updatedAt
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`UPDATE web_analyses SET
+			url = ?, html_version = ?, title = ?, has_login_form = ?, status = ?, error_description = ?,
+			internal_links = ?, external_links = ?, inaccessible_links = ?, created_at = ?, updated_at = ?, host = ?,
+			callback_url = ?, skip_link_check = ?, max_links = ?, link_workers = ?, link_timeout_ms = ?, skip_redirects = ?,
+			skip_external_links = ?, user_agent = ?, final_url = ?, encoding = ?, respect_robots = ?,
+			internal_occurrences = ?, external_occurrences = ?
+		WHERE id = ?`,
+		webAnalyzer.URL, webAnalyzer.HTMLVersion, webAnalyzer.Title, webAnalyzer.HasLoginForm, webAnalyzer.Status,
+		webAnalyzer.ErrorDescription, webAnalyzer.Links.Internal, webAnalyzer.Links.External, webAnalyzer.Links.Inaccessible,
+		toUnixNano(webAnalyzer.CreatedAt), toNullUnixNano(webAnalyzer.UpdatedAt), hostOf(webAnalyzer.URL),
+		webAnalyzer.CallbackURL, webAnalyzer.Options.SkipLinkCheck, webAnalyzer.Options.MaxLinks, webAnalyzer.Options.LinkWorkers,
+		webAnalyzer.Options.LinkTimeout.Milliseconds(), webAnalyzer.Options.SkipRedirects, webAnalyzer.Options.SkipExternalLinks,
+		webAnalyzer.Options.UserAgent, webAnalyzer.FinalURL, webAnalyzer.Encoding, webAnalyzer.Options.RespectRobots,
+		webAnalyzer.Links.InternalOccurrences, webAnalyzer.Links.ExternalOccurrences, webAnalyzer.ID)
+	if err != nil {
+		return "", err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return "", err
+	}
+	if affected == 0 {
+		return "", repository.ErrRecordNotFound
+	}
+
+	if _, err := tx.Exec(`DELETE FROM web_analysis_headings WHERE analysis_id = ?`, webAnalyzer.ID); err != nil {
+		return "", err
+	}
+	if _, err := tx.Exec(`DELETE FROM web_analysis_inaccessible_links WHERE analysis_id = ?`, webAnalyzer.ID); err != nil {
+		return "", err
+	}
+	if _, err := tx.Exec(`DELETE FROM web_analysis_page_redirects WHERE analysis_id = ?`, webAnalyzer.ID); err != nil {
+		return "", err
+	}
+	if _, err := tx.Exec(`DELETE FROM web_analysis_link_redirects WHERE analysis_id = ?`, webAnalyzer.ID); err != nil {
+		return "", err
+	}
+	if _, err := tx.Exec(`DELETE FROM web_analysis_link_redirect_hops WHERE analysis_id = ?`, webAnalyzer.ID); err != nil {
+		return "", err
+	}
+	if _, err := tx.Exec(`DELETE FROM web_analysis_robots_skipped_links WHERE analysis_id = ?`, webAnalyzer.ID); err != nil {
+		return "", err
+	}
+	if _, err := tx.Exec(`DELETE FROM web_analysis_resource_counts WHERE analysis_id = ?`, webAnalyzer.ID); err != nil {
+		return "", err
+	}
+	if _, err := tx.Exec(`DELETE FROM web_analysis_seo WHERE analysis_id = ?`, webAnalyzer.ID); err != nil {
+		return "", err
+	}
+	if _, err := tx.Exec(`DELETE FROM web_analysis_seo_hreflang WHERE analysis_id = ?`, webAnalyzer.ID); err != nil {
+		return "", err
+	}
+	if _, err := tx.Exec(`DELETE FROM web_analysis_seo_properties WHERE analysis_id = ?`, webAnalyzer.ID); err != nil {
+		return "", err
+	}
+	if _, err := tx.Exec(`DELETE FROM web_analysis_seo_issues WHERE analysis_id = ?`, webAnalyzer.ID); err != nil {
+		return "", err
+	}
+
+	if err := insertChildren(tx, webAnalyzer); err != nil {
+		return "", err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return "", err
 	}
 
-	r.storage[webAnalyzer.ID] = webAnalyzer
 	return webAnalyzer.ID, nil
 }
 
+func (r *webAnalyzerRepo) List(filter repository.ListFilter) (*repository.ListResult, error) {
+	sortColumn := "COALESCE(created_at, 0)"
+	if filter.SortBy == repository.SortByUpdatedAt {
+		sortColumn = "COALESCE(updated_at, created_at, 0)"
+	}
+
+	direction, comparison := "ASC", ">"
+	if filter.SortDesc {
+		direction, comparison = "DESC", "<"
+	}
+
+	var (
+		conditions []string
+		args       []any
+	)
+
+	if len(filter.Statuses) > 0 {
+		conditions = append(conditions, "status IN (?"+strings.Repeat(", ?", len(filter.Statuses)-1)+")")
+		for _, status := range filter.Statuses {
+			args = append(args, status)
+		}
+	}
+
+	if filter.URL != "" {
+		conditions = append(conditions, "url = ?")
+		args = append(args, filter.URL)
+	}
+
+	if filter.URLContains != "" {
+		conditions = append(conditions, `url LIKE ? ESCAPE '\'`)
+		args = append(args, "%"+escapeLike(filter.URLContains)+"%")
+	}
+
+	if filter.HostContains != "" {
+		conditions = append(conditions, `host LIKE ? ESCAPE '\'`)
+		args = append(args, "%"+escapeLike(strings.ToLower(filter.HostContains))+"%")
+	}
+
+	if filter.CreatedFrom != nil {
+		conditions = append(conditions, "created_at >= ?")
+		args = append(args, filter.CreatedFrom.UnixNano())
+	}
+
+	if filter.CreatedTo != nil {
+		conditions = append(conditions, "created_at < ?")
+		args = append(args, filter.CreatedTo.UnixNano())
+	}
+
+	if filter.Cursor != "" {
+		key, id, err := repository.DecodeCursor(filter.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		conditions = append(conditions, fmt.Sprintf("(%[1]s %[2]s ? OR (%[1]s = ? AND id %[2]s ?))", sortColumn, comparison))
+		args = append(args, key, key, id)
+	}
+
+	query := `SELECT ` + analysisColumns + ` FROM web_analyses`
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += fmt.Sprintf(" ORDER BY %s %s, id %s", sortColumn, direction, direction)
+
+	// One extra row tells whether another page follows
+	if filter.Limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, filter.Limit+1)
+	}
+
+	items, err := r.queryAnalyses(query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &repository.ListResult{Items: items}
+	if filter.Limit > 0 && len(items) > filter.Limit {
+		result.Items = items[:filter.Limit]
+		last := result.Items[len(result.Items)-1]
+		result.NextCursor = repository.EncodeCursor(repository.SortKey(last, filter.SortBy), last.ID)
+	}
+
+	return result, nil
+}
+
+// queryAnalyses reads all matching rows before loading child rows so that only one statement is open at a time.
+func (r *webAnalyzerRepo) queryAnalyses(query string, args ...any) ([]model.WebAnalyzer, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	result := []model.WebAnalyzer{}
+	for rows.Next() {
+		analysis, err := scanAnalysis(rows)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+		result = append(result, *analysis)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range result {
+		if err := r.loadChildren(&result[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanAnalysis(row rowScanner) (*model.WebAnalyzer, error) {
+	var (
+		analysis         model.WebAnalyzer
+		errorDescription sql.NullString
+		createdAt        sql.NullInt64
+		updatedAt        sql.NullInt64
+		linkTimeoutMs    int64
+	)
+
+	err := row.Scan(
+		&analysis.ID, &analysis.URL, &analysis.HTMLVersion, &analysis.Title, &analysis.HasLoginForm,
+		&analysis.Status, &errorDescription, &analysis.Links.Internal, &analysis.Links.External,
+		&analysis.Links.Inaccessible, &createdAt, &updatedAt, &analysis.CallbackURL,
+		&analysis.Options.SkipLinkCheck, &analysis.Options.MaxLinks, &analysis.Options.LinkWorkers, &linkTimeoutMs,
+		&analysis.Options.SkipRedirects, &analysis.Options.SkipExternalLinks, &analysis.Options.UserAgent, &analysis.FinalURL,
+		&analysis.Encoding, &analysis.Options.RespectRobots, &analysis.Links.InternalOccurrences, &analysis.Links.ExternalOccurrences)
+	if err != nil {
+		return nil, err
+	}
+
+	analysis.Options.LinkTimeout = time.Duration(linkTimeoutMs) * time.Millisecond
+	if errorDescription.Valid {
+		analysis.ErrorDescription = &errorDescription.String
+	}
+	if createdAt.Valid {
+		analysis.CreatedAt = time.Unix(0, createdAt.Int64).UTC()
+	}
+	if updatedAt.Valid {
+		t := time.Unix(0, updatedAt.Int64).UTC()
+		analysis.UpdatedAt = &t
+	}
+
+	return &analysis, nil
+}
+
+func (r *webAnalyzerRepo) loadChildren(analysis *model.WebAnalyzer) error {
+	var err error
+
+	if analysis.Headings, err = r.getHeadings(analysis.ID); err != nil {
+		return err
+	}
+
+	if analysis.Links.InaccessibleDetails, err = r.getInaccessibleLinks(analysis.ID); err != nil {
+		return err
+	}
+
+	if analysis.Links.ErrorCategories, err = r.getLinkErrorCategories(analysis.ID); err != nil {
+		return err
+	}
+
+	if analysis.Redirects, err = r.getPageRedirects(analysis.ID); err != nil {
+		return err
+	}
+
+	if analysis.Links.Redirected, err = r.getLinkRedirects(analysis.ID); err != nil {
+		return err
+	}
+
+	if analysis.Links.SkippedRobots, err = r.getRobotsSkippedLinks(analysis.ID); err != nil {
+		return err
+	}
+
+	if analysis.Links.Resources, err = r.getResourceCounts(analysis.ID); err != nil {
+		return err
+	}
+
+	if analysis.SEO, err = r.getSEO(analysis.ID); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *webAnalyzerRepo) getHeadings(id string) (map[string]int, error) {
+	rows, err := r.db.Query(`SELECT level, count FROM web_analysis_headings WHERE analysis_id = ?`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var headings map[string]int
+	for rows.Next() {
+		var (
+			level string
+			count int
+		)
+		if err := rows.Scan(&level, &count); err != nil {
+			return nil, err
+		}
+		if headings == nil {
+			headings = make(map[string]int)
+		}
+		headings[level] = count
+	}
+
+	return headings, rows.Err()
+}
+
+func (r *webAnalyzerRepo) getInaccessibleLinks(id string) ([]model.InaccessibleLink, error) {
+	rows, err := r.db.Query(`SELECT url, status_code, category, message, kinds FROM web_analysis_inaccessible_links
+		WHERE analysis_id = ? ORDER BY position`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	links := []model.InaccessibleLink{}
+	for rows.Next() {
+		var (
+			link  model.InaccessibleLink
+			kinds string
+		)
+		if err := rows.Scan(&link.URL, &link.StatusCode, &link.Category, &link.Message, &kinds); err != nil {
+			return nil, err
+		}
+		if kinds != "" {
+			link.Kinds = strings.Split(kinds, ",")
+		}
+		links = append(links, link)
+	}
+
+	return links, rows.Err()
+}
+
+func (r *webAnalyzerRepo) getLinkErrorCategories(id string) (map[string]int, error) {
+	rows, err := r.db.Query(`SELECT category, COUNT(*) FROM web_analysis_inaccessible_links
+		WHERE analysis_id = ? GROUP BY category`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var categories map[string]int
+	for rows.Next() {
+		var (
+			category string
+			count    int
+		)
+		if err := rows.Scan(&category, &count); err != nil {
+			return nil, err
+		}
+		if categories == nil {
+			categories = make(map[string]int)
+		}
+		categories[category] = count
+	}
+
+	return categories, rows.Err()
+}
+
+func (r *webAnalyzerRepo) getPageRedirects(id string) ([]model.RedirectHop, error) {
+	rows, err := r.db.Query(`SELECT url, status_code FROM web_analysis_page_redirects
+		WHERE analysis_id = ? ORDER BY position`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var hops []model.RedirectHop
+	for rows.Next() {
+		var hop model.RedirectHop
+		if err := rows.Scan(&hop.URL, &hop.StatusCode); err != nil {
+			return nil, err
+		}
+		hops = append(hops, hop)
+	}
+
+	return hops, rows.Err()
+}
+
+func (r *webAnalyzerRepo) getLinkRedirects(id string) ([]model.LinkRedirect, error) {
+	rows, err := r.db.Query(`SELECT url, final_url, status_code, error FROM web_analysis_link_redirects
+		WHERE analysis_id = ? ORDER BY position`, id)
+	if err != nil {
+		return nil, err
+	}
+
+	var redirects []model.LinkRedirect
+	for rows.Next() {
+		var redirect model.LinkRedirect
+		if err := rows.Scan(&redirect.URL, &redirect.FinalURL, &redirect.StatusCode, &redirect.Error); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		redirects = append(redirects, redirect)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(redirects) == 0 {
+		return redirects, nil
+	}
+
+	hops, err := r.db.Query(`SELECT link_position, url, status_code FROM web_analysis_link_redirect_hops
+		WHERE analysis_id = ? ORDER BY link_position, position`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer hops.Close()
+
+	for hops.Next() {
+		var (
+			linkPosition int
+			hop          model.RedirectHop
+		)
+		if err := hops.Scan(&linkPosition, &hop.URL, &hop.StatusCode); err != nil {
+			return nil, err
+		}
+		if linkPosition >= 0 && linkPosition < len(redirects) {
+			redirects[linkPosition].Chain = append(redirects[linkPosition].Chain, hop)
+		}
+	}
+
+	return redirects, hops.Err()
+}
+
+func (r *webAnalyzerRepo) getRobotsSkippedLinks(id string) ([]string, error) {
+	rows, err := r.db.Query(`SELECT url FROM web_analysis_robots_skipped_links WHERE analysis_id = ? ORDER BY position`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var links []string
+	for rows.Next() {
+		var link string
+		if err := rows.Scan(&link); err != nil {
+			return nil, err
+		}
+		links = append(links, link)
+	}
+
+	return links, rows.Err()
+}
+
+func (r *webAnalyzerRepo) getResourceCounts(id string) (map[string]model.ResourceStats, error) {
+	rows, err := r.db.Query(`SELECT kind, internal, external, broken FROM web_analysis_resource_counts WHERE analysis_id = ?`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var resources map[string]model.ResourceStats
+	for rows.Next() {
+		var (
+			kind  string
+			stats model.ResourceStats
+		)
+		if err := rows.Scan(&kind, &stats.Internal, &stats.External, &stats.Broken); err != nil {
+			return nil, err
+		}
+		if resources == nil {
+			resources = make(map[string]model.ResourceStats)
+		}
+		resources[kind] = stats
+	}
+
+	return resources, rows.Err()
+}
+
+// getSEO returns the SEO analysis of an analysis, or nil when the page was never analyzed.
+func (r *webAnalyzerRepo) getSEO(id string) (*model.SEOAnalysis, error) {
+	var seo model.SEOAnalysis
+	err := r.db.QueryRow(`SELECT title_count, description, robots, canonical, viewport, lang FROM web_analysis_seo WHERE analysis_id = ?`, id).
+		Scan(&seo.TitleCount, &seo.Description, &seo.Robots, &seo.Canonical, &seo.Viewport, &seo.Lang)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	hreflang, err := r.db.Query(`SELECT lang, url FROM web_analysis_seo_hreflang WHERE analysis_id = ? ORDER BY position`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer hreflang.Close()
+
+	seo.Hreflang = []model.HreflangAlternate{}
+	for hreflang.Next() {
+		var alternate model.HreflangAlternate
+		if err := hreflang.Scan(&alternate.Lang, &alternate.URL); err != nil {
+			return nil, err
+		}
+		seo.Hreflang = append(seo.Hreflang, alternate)
+	}
+	if err := hreflang.Err(); err != nil {
+		return nil, err
+	}
+
+	properties, err := r.db.Query(`SELECT kind, name, value FROM web_analysis_seo_properties WHERE analysis_id = ?`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer properties.Close()
+
+	seo.OpenGraph = map[string]string{}
+	seo.TwitterCard = map[string]string{}
+	for properties.Next() {
+		var kind, name, value string
+		if err := properties.Scan(&kind, &name, &value); err != nil {
+			return nil, err
+		}
+		switch kind {
+		case seoPropertyOpenGraph:
+			seo.OpenGraph[name] = value
+		case seoPropertyTwitterCard:
+			seo.TwitterCard[name] = value
+		}
+	}
+	if err := properties.Err(); err != nil {
+		return nil, err
+	}
+
+	issues, err := r.db.Query(`SELECT code, message FROM web_analysis_seo_issues WHERE analysis_id = ? ORDER BY position`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer issues.Close()
+
+	seo.Issues = []model.SEOIssue{}
+	for issues.Next() {
+		var issue model.SEOIssue
+		if err := issues.Scan(&issue.Code, &issue.Message); err != nil {
+			return nil, err
+		}
+		seo.Issues = append(seo.Issues, issue)
+	}
+
+	return &seo, issues.Err()
+}
+
+func insertSEO(tx *sql.Tx, id string, seo *model.SEOAnalysis) error {
+	if seo == nil {
+		return nil
+	}
+
+	_, err := tx.Exec(`INSERT INTO web_analysis_seo (analysis_id, title_count, description, robots, canonical, viewport, lang) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		id, seo.TitleCount, seo.Description, seo.Robots, seo.Canonical, seo.Viewport, seo.Lang)
+	if err != nil {
+		return err
+	}
+
+	for i, alternate := range seo.Hreflang {
+		_, err := tx.Exec(`INSERT INTO web_analysis_seo_hreflang (analysis_id, position, lang, url) VALUES (?, ?, ?, ?)`,
+			id, i, alternate.Lang, alternate.URL)
+		if err != nil {
+			return err
+		}
+	}
+
+	for kind, properties := range map[string]map[string]string{seoPropertyOpenGraph: seo.OpenGraph, seoPropertyTwitterCard: seo.TwitterCard} {
+		for name, value := range properties {
+			_, err := tx.Exec(`INSERT INTO web_analysis_seo_properties (analysis_id, kind, name, value) VALUES (?, ?, ?, ?)`,
+				id, kind, name, value)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	for i, issue := range seo.Issues {
+		_, err := tx.Exec(`INSERT INTO web_analysis_seo_issues (analysis_id, position, code, message) VALUES (?, ?, ?, ?)`,
+			id, i, issue.Code, issue.Message)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func insertChildren(tx *sql.Tx, webAnalyzer model.WebAnalyzer) error {
+	for level, count := range webAnalyzer.Headings {
+		_, err := tx.Exec(`INSERT INTO web_analysis_headings (analysis_id, level, count) VALUES (?, ?, ?)`,
+			webAnalyzer.ID, level, count)
+		if err != nil {
+			return err
+		}
+	}
+
+	for i, link := range webAnalyzer.Links.InaccessibleDetails {
+		_, err := tx.Exec(`INSERT INTO web_analysis_inaccessible_links (analysis_id, position, url, status_code, category, message, kinds)
+			VALUES (?, ?, ?, ?, ?, ?, ?)`, webAnalyzer.ID, i, link.URL, link.StatusCode, link.Category, link.Message, strings.Join(link.Kinds, ","))
+		if err != nil {
+			return err
+		}
+	}
+
+	for i, hop := range webAnalyzer.Redirects {
+		_, err := tx.Exec(`INSERT INTO web_analysis_page_redirects (analysis_id, position, url, status_code) VALUES (?, ?, ?, ?)`,
+			webAnalyzer.ID, i, hop.URL, hop.StatusCode)
+		if err != nil {
+			return err
+		}
+	}
+
+	for i, redirect := range webAnalyzer.Links.Redirected {
+		_, err := tx.Exec(`INSERT INTO web_analysis_link_redirects (analysis_id, position, url, final_url, status_code, error) VALUES (?, ?, ?, ?, ?, ?)`,
+			webAnalyzer.ID, i, redirect.URL, redirect.FinalURL, redirect.StatusCode, redirect.Error)
+		if err != nil {
+			return err
+		}
+
+		for j, hop := range redirect.Chain {
+			_, err := tx.Exec(`INSERT INTO web_analysis_link_redirect_hops (analysis_id, link_position, position, url, status_code) VALUES (?, ?, ?, ?, ?)`,
+				webAnalyzer.ID, i, j, hop.URL, hop.StatusCode)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	for i, link := range webAnalyzer.Links.SkippedRobots {
+		_, err := tx.Exec(`INSERT INTO web_analysis_robots_skipped_links (analysis_id, position, url) VALUES (?, ?, ?)`,
+			webAnalyzer.ID, i, link)
+		if err != nil {
+			return err
+		}
+	}
+
+	for kind, stats := range webAnalyzer.Links.Resources {
+		_, err := tx.Exec(`INSERT INTO web_analysis_resource_counts (analysis_id, kind, internal, external, broken) VALUES (?, ?, ?, ?, ?)`,
+			webAnalyzer.ID, kind, stats.Internal, stats.External, stats.Broken)
+		if err != nil {
+			return err
+		}
+	}
+
+	return insertSEO(tx, webAnalyzer.ID, webAnalyzer.SEO)
+}
+
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(value)
+}
+
+func hostOf(rawURL string) string {
+	parsedURL, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(parsedURL.Host)
+}
+
+func toUnixNano(t time.Time) sql.NullInt64 {
+	if t.IsZero() {
+		return sql.NullInt64{}
+	}
+	return sql.NullInt64{Int64: t.UnixNano(), Valid: true}
+}
+
+func toNullUnixNano(t *time.Time) sql.NullInt64 {
+	if t == nil {
+		return sql.NullInt64{}
+	}
+	return toUnixNano(*t)
+}
+
 func generateID() string {
 	id := uuid.New().String()
 	return id
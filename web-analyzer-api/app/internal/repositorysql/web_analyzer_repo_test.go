@@ -1,20 +1,94 @@
 package repositorysql
 
 import (
+	"database/sql"
+	"path/filepath"
 	"testing"
+	"time"
 	"web-analyzer-api/app/internal/model"
+	"web-analyzer-api/app/internal/repository"
 	"web-analyzer-api/app/internal/util/logger"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
+func setupTestDB(t *testing.T) *sql.DB {
+	db, err := Open(filepath.Join(t.TempDir(), "test.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestOpen(t *testing.T) {
+	t.Run("Migrations are applied once", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "test.db")
+
+		db, err := Open(path)
+		require.NoError(t, err)
+		db.Close()
+
+		db, err = Open(path)
+		require.NoError(t, err)
+		defer db.Close()
+
+		var version int
+		err = db.QueryRow(`SELECT MAX(version) FROM schema_migrations`).Scan(&version)
+		assert.NoError(t, err)
+		assert.Equal(t, len(migrations), version)
+	})
+
+	t.Run("Invalid path", func(t *testing.T) {
+		db, err := Open(filepath.Join(t.TempDir(), "missing", "test.db"))
+		assert.Error(t, err)
+		assert.Nil(t, db)
+	})
+}
+
+func TestWebAnalyzerRepo_FindByStatus(t *testing.T) {
+	log := logger.Get("info")
+	repo := NewWebAnalyzerRepo(log, setupTestDB(t))
+
+	now := time.Now().UTC()
+	pendingID, _ := repo.Save(model.WebAnalyzer{URL: "http://pending.test", Status: "pending", CreatedAt: now.Add(time.Second)})
+	queuedID, _ := repo.Save(model.WebAnalyzer{URL: "http://queued.test", Status: "queued", CreatedAt: now})
+	_, _ = repo.Save(model.WebAnalyzer{URL: "http://success.test", Status: "success", CreatedAt: now})
+
+	found, err := repo.FindByStatus("queued", "pending")
+	assert.NoError(t, err)
+	if assert.Len(t, found, 2) {
+		assert.Equal(t, queuedID, found[0].ID)
+		assert.Equal(t, pendingID, found[1].ID)
+	}
+
+	found, err = repo.FindByStatus("failed")
+	assert.NoError(t, err)
+	assert.Empty(t, found)
+
+	found, err = repo.FindByStatus()
+	assert.NoError(t, err)
+	assert.Empty(t, found)
+}
+
 func TestWebAnalyzerRepo(t *testing.T) {
 	log := logger.Get("info")
-	repo := NewWebAnalyzerRepo(log)
+	repo := NewWebAnalyzerRepo(log, setupTestDB(t))
 
 	t.Run("Save and GetById", func(t *testing.T) {
 		analysis := model.WebAnalyzer{
-			URL: "http://test.test",
+			URL:         "http://test.test",
+			Status:      "pending",
+			CallbackURL: "https://ci.test/hook",
+			Options: model.AnalysisOptions{
+				SkipLinkCheck:     true,
+				MaxLinks:          50,
+				LinkWorkers:       4,
+				LinkTimeout:       2500 * time.Millisecond,
+				SkipRedirects:     true,
+				SkipExternalLinks: true,
+				UserAgent:         "audit-bot/1.0",
+				RespectRobots:     true,
+			},
 		}
 
 		id, err := repo.Save(analysis)
@@ -27,6 +101,11 @@ func TestWebAnalyzerRepo(t *testing.T) {
 		assert.NotNil(t, found)
 		assert.Equal(t, id, found.ID)
 		assert.Equal(t, "http://test.test", found.URL)
+		assert.False(t, found.CreatedAt.IsZero())
+		assert.Equal(t, "pending", found.Status)
+		assert.Equal(t, "https://ci.test/hook", found.CallbackURL)
+		assert.Equal(t, analysis.Options, found.Options)
+		assert.Nil(t, found.ErrorDescription)
 
 		// Get by invalid ID
 		notFound, err := repo.GetById("123")
@@ -41,10 +120,70 @@ func TestWebAnalyzerRepo(t *testing.T) {
 
 		id, _ := repo.Save(analysis)
 
+		errorDescription := "error description"
+		beforeUpdate := time.Now().UTC()
 		updatedAnalysis := model.WebAnalyzer{
-			ID:     id,
-			URL:    "http://updated.test",
-			Status: "success",
+			ID:          id,
+			URL:         "http://updated.test",
+			HTMLVersion: "HTML5",
+			Title:       "Updated",
+			Headings:    map[string]int{"h1": 1, "h2": 3},
+			Links: model.LinkAnalysis{
+				Internal:            4,
+				External:            2,
+				InternalOccurrences: 9,
+				ExternalOccurrences: 3,
+				Inaccessible:        2,
+				InaccessibleDetails: []model.InaccessibleLink{
+					{URL: "http://updated.test/a", StatusCode: 404, Category: "http_4xx", Message: "404 Not Found", Kinds: []string{"anchor", "image"}},
+					{URL: "http://updated.test/b", StatusCode: 0, Category: "dns", Message: "no such host"},
+				},
+				ErrorCategories: map[string]int{"http_4xx": 1, "dns": 1},
+				Redirected: []model.LinkRedirect{
+					{
+						URL:        "http://updated.test/old",
+						FinalURL:   "http://updated.test/new",
+						StatusCode: 200,
+						Chain: []model.RedirectHop{
+							{URL: "http://updated.test/old", StatusCode: 301},
+							{URL: "http://updated.test/new", StatusCode: 200},
+						},
+					},
+					{
+						URL:        "http://updated.test/loop",
+						FinalURL:   "http://updated.test/loop",
+						StatusCode: 302,
+						Chain:      []model.RedirectHop{{URL: "http://updated.test/loop", StatusCode: 302}},
+						Error:      "redirect_loop",
+					},
+				},
+				SkippedRobots: []string{"http://updated.test/private", "http://updated.test/admin"},
+				Resources: map[string]model.ResourceStats{
+					"anchor": {Internal: 4, External: 2, Broken: 2},
+					"image":  {Internal: 1, Broken: 1},
+				},
+			},
+			HasLoginForm: true,
+			SEO: &model.SEOAnalysis{
+				TitleCount:  1,
+				Description: "Updated page",
+				Robots:      "noindex",
+				Canonical:   "https://updated.test/",
+				Hreflang:    []model.HreflangAlternate{{Lang: "de", URL: "https://updated.test/de/"}, {Lang: "x-default", URL: "https://updated.test/"}},
+				OpenGraph:   map[string]string{"title": "Updated", "type": "website"},
+				TwitterCard: map[string]string{"card": "summary"},
+				Viewport:    "width=device-width",
+				Lang:        "en",
+				Issues:      []model.SEOIssue{{Code: "description_length", Message: "Too short"}, {Code: "noindex", Message: "Not indexed"}},
+			},
+			Status:           "success",
+			ErrorDescription: &errorDescription,
+			FinalURL:         "https://updated.test/",
+			Encoding:         "windows-1252",
+			Redirects: []model.RedirectHop{
+				{URL: "http://updated.test", StatusCode: 301},
+				{URL: "https://updated.test/", StatusCode: 200},
+			},
 		}
 
 		updatedID, err := repo.Update(updatedAnalysis)
@@ -55,11 +194,152 @@ func TestWebAnalyzerRepo(t *testing.T) {
 		found, _ := repo.GetById(id)
 		assert.Equal(t, "http://updated.test", found.URL)
 		assert.Equal(t, "success", found.Status)
+		assert.Equal(t, "HTML5", found.HTMLVersion)
+		assert.Equal(t, "Updated", found.Title)
+		assert.True(t, found.HasLoginForm)
+		assert.Equal(t, map[string]int{"h1": 1, "h2": 3}, found.Headings)
+		assert.Equal(t, updatedAnalysis.Links, found.Links)
+		assert.Equal(t, "https://updated.test/", found.FinalURL)
+		assert.Equal(t, "windows-1252", found.Encoding)
+		assert.Equal(t, updatedAnalysis.Redirects, found.Redirects)
+		assert.Equal(t, updatedAnalysis.SEO, found.SEO)
+		assert.Equal(t, errorDescription, *found.ErrorDescription)
+		assert.False(t, found.UpdatedAt.Before(beforeUpdate))
+
+		// Child rows are replaced rather than appended
+		updatedAnalysis.Headings = map[string]int{"h1": 2}
+		updatedAnalysis.Links.InaccessibleDetails = updatedAnalysis.Links.InaccessibleDetails[:1]
+		updatedAnalysis.Links.Redirected = updatedAnalysis.Links.Redirected[1:]
+		updatedAnalysis.Links.SkippedRobots = updatedAnalysis.Links.SkippedRobots[:1]
+		updatedAnalysis.Links.Resources = map[string]model.ResourceStats{"script": {External: 1}}
+		updatedAnalysis.SEO = &model.SEOAnalysis{
+			Hreflang:    []model.HreflangAlternate{},
+			OpenGraph:   map[string]string{"title": "Replaced"},
+			TwitterCard: map[string]string{},
+			Issues:      []model.SEOIssue{{Code: "title_missing", Message: "No title"}},
+		}
+		_, err = repo.Update(updatedAnalysis)
+		assert.NoError(t, err)
+
+		found, _ = repo.GetById(id)
+		assert.Equal(t, map[string]int{"h1": 2}, found.Headings)
+		assert.Len(t, found.Links.InaccessibleDetails, 1)
+		assert.Equal(t, map[string]int{"http_4xx": 1}, found.Links.ErrorCategories)
+		assert.Equal(t, updatedAnalysis.Links.Redirected, found.Links.Redirected)
+		assert.Equal(t, []string{"http://updated.test/private"}, found.Links.SkippedRobots)
+		assert.Equal(t, map[string]model.ResourceStats{"script": {External: 1}}, found.Links.Resources)
+		assert.Equal(t, updatedAnalysis.SEO, found.SEO)
+
+		// Analyses without SEO results have none
+		updatedAnalysis.SEO = nil
+		_, err = repo.Update(updatedAnalysis)
+		assert.NoError(t, err)
+
+		found, _ = repo.GetById(id)
+		assert.Nil(t, found.SEO)
 
 		// Update unavailable record
 		invalidUpdate := model.WebAnalyzer{ID: "123"}
 		_, err = repo.Update(invalidUpdate)
-		assert.Error(t, err)
+		assert.ErrorIs(t, err, repository.ErrRecordNotFound)
 		assert.Equal(t, "record not found", err.Error())
 	})
 }
+
+func TestWebAnalyzerRepo_List(t *testing.T) {
+	log := logger.Get("info")
+	repo := NewWebAnalyzerRepo(log, setupTestDB(t))
+
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	seed := []model.WebAnalyzer{
+		{URL: "https://www.example.com/", Status: "success", CreatedAt: base},
+		{URL: "https://blog.example.com/post", Status: "failed", CreatedAt: base.Add(time.Hour)},
+		{URL: "https://other.test/example", Status: "success", CreatedAt: base.Add(2 * time.Hour)},
+		{URL: "https://www.example.com/sale?discount=100%25_off", Status: "pending", CreatedAt: base.Add(3 * time.Hour)},
+		{URL: "https://shop.test/", Status: "success", CreatedAt: base.Add(4 * time.Hour)},
+	}
+	ids := make([]string, len(seed))
+	for i, analysis := range seed {
+		ids[i], _ = repo.Save(analysis)
+	}
+
+	listIDs := func(items []model.WebAnalyzer) []string {
+		result := []string{}
+		for _, item := range items {
+			result = append(result, item.ID)
+		}
+		return result
+	}
+
+	t.Run("Cursor pagination newest first", func(t *testing.T) {
+		filter := repository.ListFilter{SortBy: repository.SortByCreatedAt, SortDesc: true, Limit: 2}
+
+		page, err := repo.List(filter)
+		require.NoError(t, err)
+		assert.Equal(t, []string{ids[4], ids[3]}, listIDs(page.Items))
+		require.NotEmpty(t, page.NextCursor)
+
+		filter.Cursor = page.NextCursor
+		page, err = repo.List(filter)
+		require.NoError(t, err)
+		assert.Equal(t, []string{ids[2], ids[1]}, listIDs(page.Items))
+
+		filter.Cursor = page.NextCursor
+		page, err = repo.List(filter)
+		require.NoError(t, err)
+		assert.Equal(t, []string{ids[0]}, listIDs(page.Items))
+		assert.Empty(t, page.NextCursor)
+	})
+
+	t.Run("Oldest first", func(t *testing.T) {
+		page, err := repo.List(repository.ListFilter{SortBy: repository.SortByCreatedAt, Limit: 10})
+		require.NoError(t, err)
+		assert.Equal(t, ids, listIDs(page.Items))
+		assert.Empty(t, page.NextCursor)
+	})
+
+	t.Run("Sort by update time", func(t *testing.T) {
+		found, _ := repo.GetById(ids[0])
+		_, err := repo.Update(*found)
+		require.NoError(t, err)
+
+		page, err := repo.List(repository.ListFilter{SortBy: repository.SortByUpdatedAt, SortDesc: true, Limit: 1})
+		require.NoError(t, err)
+		assert.Equal(t, []string{ids[0]}, listIDs(page.Items))
+	})
+
+	t.Run("Filters", func(t *testing.T) {
+		from := base.Add(time.Hour)
+		to := base.Add(4 * time.Hour)
+
+		tests := []struct {
+			name     string
+			filter   repository.ListFilter
+			expected []string
+		}{
+			{"Status", repository.ListFilter{Statuses: []string{"success"}}, []string{ids[0], ids[2], ids[4]}},
+			{"Multiple statuses", repository.ListFilter{Statuses: []string{"failed", "pending"}}, []string{ids[1], ids[3]}},
+			{"Exact URL", repository.ListFilter{URL: "https://www.example.com/"}, []string{ids[0]}},
+			{"URL substring", repository.ListFilter{URLContains: "EXAMPLE"}, []string{ids[0], ids[1], ids[2], ids[3]}},
+			{"URL substring with wildcards", repository.ListFilter{URLContains: "100%25_off"}, []string{ids[3]}},
+			{"Wildcards match literally", repository.ListFilter{URLContains: "e_a"}, []string{}},
+			{"Host substring", repository.ListFilter{HostContains: "example"}, []string{ids[0], ids[1], ids[3]}},
+			{"Created range", repository.ListFilter{CreatedFrom: &from, CreatedTo: &to}, []string{ids[1], ids[2], ids[3]}},
+			{"Combined", repository.ListFilter{Statuses: []string{"success"}, HostContains: "example"}, []string{ids[0]}},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				tt.filter.SortBy = repository.SortByCreatedAt
+				page, err := repo.List(tt.filter)
+				require.NoError(t, err)
+				assert.Equal(t, tt.expected, listIDs(page.Items))
+			})
+		}
+	})
+
+	t.Run("Invalid cursor", func(t *testing.T) {
+		_, err := repo.List(repository.ListFilter{Cursor: "not-a-cursor"})
+		assert.ErrorIs(t, err, repository.ErrInvalidCursor)
+	})
+}
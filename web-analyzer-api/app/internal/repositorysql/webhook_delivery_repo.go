@@ -0,0 +1,67 @@
+package repositorysql
+
+import (
+	"database/sql"
+	"time"
+	"web-analyzer-api/app/internal/model"
+	"web-analyzer-api/app/internal/repository"
+	"web-analyzer-api/app/internal/util/logger"
+)
+
+type webhookDeliveryRepo struct {
+	log *logger.Logger
+	db  *sql.DB
+}
+
+func NewWebhookDeliveryRepo(logger *logger.Logger, db *sql.DB) repository.WebhookDeliveryRepository {
+	return &webhookDeliveryRepo{
+		log: logger,
+		db:  db,
+	}
+}
+
+func (r *webhookDeliveryRepo) SaveDelivery(delivery model.WebhookDelivery) (string, error) {
+	delivery.ID = generateID()
+	if delivery.CreatedAt.IsZero() {
+		delivery.CreatedAt = time.Now().UTC()
+	}
+
+	_, err := r.db.Exec(`INSERT INTO webhook_deliveries (
+			id, analysis_id, url, event, attempt, status_code, success, error, duration_ms, created_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		delivery.ID, delivery.AnalysisID, delivery.URL, delivery.Event, delivery.Attempt, delivery.StatusCode,
+		delivery.Success, delivery.Error, delivery.Duration.Milliseconds(), toUnixNano(delivery.CreatedAt))
+	if err != nil {
+		return "", err
+	}
+
+	return delivery.ID, nil
+}
+
+func (r *webhookDeliveryRepo) ListDeliveries(analysisId string) ([]model.WebhookDelivery, error) {
+	rows, err := r.db.Query(`SELECT id, analysis_id, url, event, attempt, status_code, success, error, duration_ms, created_at
+		FROM webhook_deliveries WHERE analysis_id = ? ORDER BY created_at, rowid`, analysisId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []model.WebhookDelivery{}
+	for rows.Next() {
+		var (
+			delivery   model.WebhookDelivery
+			durationMs int64
+			createdAt  int64
+		)
+		err := rows.Scan(&delivery.ID, &delivery.AnalysisID, &delivery.URL, &delivery.Event, &delivery.Attempt,
+			&delivery.StatusCode, &delivery.Success, &delivery.Error, &durationMs, &createdAt)
+		if err != nil {
+			return nil, err
+		}
+		delivery.Duration = time.Duration(durationMs) * time.Millisecond
+		delivery.CreatedAt = time.Unix(0, createdAt).UTC()
+		result = append(result, delivery)
+	}
+
+	return result, rows.Err()
+}
@@ -0,0 +1,53 @@
+package repositorysql
+
+import (
+	"testing"
+	"time"
+	"web-analyzer-api/app/internal/model"
+	"web-analyzer-api/app/internal/util/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhookDeliveryRepo(t *testing.T) {
+	log := logger.Get("info")
+	db := setupTestDB(t)
+	analyses := NewWebAnalyzerRepo(log, db)
+	repo := NewWebhookDeliveryRepo(log, db)
+
+	analysisID, err := analyses.Save(model.WebAnalyzer{URL: "http://test.test", CallbackURL: "https://ci.test/hook"})
+	require.NoError(t, err)
+
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	_, err = repo.SaveDelivery(model.WebhookDelivery{
+		AnalysisID: analysisID, URL: "https://ci.test/hook", Event: "analysis.completed", Attempt: 2,
+		StatusCode: 200, Success: true, Duration: 150 * time.Millisecond, CreatedAt: base.Add(time.Second),
+	})
+	require.NoError(t, err)
+	id, err := repo.SaveDelivery(model.WebhookDelivery{
+		AnalysisID: analysisID, URL: "https://ci.test/hook", Event: "analysis.completed", Attempt: 1,
+		Error: "connection refused", CreatedAt: base,
+	})
+	require.NoError(t, err)
+
+	deliveries, err := repo.ListDeliveries(analysisID)
+	require.NoError(t, err)
+	require.Len(t, deliveries, 2)
+	assert.Equal(t, model.WebhookDelivery{
+		ID: id, AnalysisID: analysisID, URL: "https://ci.test/hook", Event: "analysis.completed", Attempt: 1,
+		Error: "connection refused", CreatedAt: base,
+	}, deliveries[0])
+	assert.True(t, deliveries[1].Success)
+	assert.Equal(t, 200, deliveries[1].StatusCode)
+	assert.Equal(t, 150*time.Millisecond, deliveries[1].Duration)
+
+	deliveries, err = repo.ListDeliveries("missing")
+	require.NoError(t, err)
+	assert.Empty(t, deliveries)
+
+	t.Run("Unknown analysis", func(t *testing.T) {
+		_, err := repo.SaveDelivery(model.WebhookDelivery{AnalysisID: "missing", URL: "https://ci.test/hook", Event: "analysis.completed", Attempt: 1})
+		assert.Error(t, err)
+	})
+}
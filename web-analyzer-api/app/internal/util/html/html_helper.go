@@ -77,7 +77,7 @@ func IsInternalLink(link string, baseURL *url.URL) bool {
 	if err != nil {
 		return false
 	}
-	return parsedLink.Host == baseURL.Host
+	return canonicalHost(parsedLink) == canonicalHost(baseURL)
 }
 
 func isTitleElement(n *html.Node) bool {
@@ -110,7 +110,7 @@ func GetLinks(doc *html.Node) []string {
 }
 
 func GetTitle(doc *html.Node) string {
-	if isTitleElement(doc) {
+	if isTitleElement(doc) && doc.FirstChild != nil {
 		return doc.FirstChild.Data
 	}
 
@@ -35,6 +35,11 @@ func TestGetTitle(t *testing.T) {
 			html:     `<html><head></head><body><h1>Body</h1></body></html>`,
 			expected: "",
 		},
+		{
+			name:     "Empty Title",
+			html:     `<html><head><title></title></head><body></body></html>`,
+			expected: "",
+		},
 		{
 			name:     "Title with Attributes",
 			html:     `<html><head><title id="main-title">Attr Title</title></head><body></body></html>`,
@@ -208,6 +213,11 @@ func TestIsInternalLink(t *testing.T) {
 			link:     "",
 			expected: true,
 		},
+		{
+			name:     "Same host with different case and default port",
+			link:     "http://MyApp.test:80/contact",
+			expected: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -0,0 +1,180 @@
+package htmlhelper
+
+import (
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// LinkCount is a unique link and the number of times it appears on a page.
+type LinkCount struct {
+	URL         string
+	Occurrences int
+}
+
+// GetBaseURL returns the URL relative links on a page resolve against: the first <base href> resolved
+// against the page URL, or the page URL itself.
+func GetBaseURL(doc *html.Node, pageURL *url.URL) *url.URL {
+	stack := []*html.Node{doc}
+	for len(stack) > 0 {
+		n := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		if n.Type == html.ElementNode && n.Data == "base" {
+			for _, attr := range n.Attr {
+				if attr.Key != "href" {
+					continue
+				}
+				href, err := url.Parse(strings.TrimSpace(attr.Val))
+				if err != nil {
+					return pageURL
+				}
+				return pageURL.ResolveReference(href)
+			}
+		}
+
+		// Push children in reverse so that the first <base> in document order is found first
+		for c := n.LastChild; c != nil; c = c.PrevSibling {
+			stack = append(stack, c)
+		}
+	}
+
+	return pageURL
+}
+
+// GetAnchors returns the fragment targets of a page: the id of every element and the name of every <a>.
+func GetAnchors(doc *html.Node) map[string]bool {
+	anchors := map[string]bool{}
+	stack := []*html.Node{doc}
+
+	for len(stack) > 0 {
+		n := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		if n.Type == html.ElementNode {
+			for _, attr := range n.Attr {
+				if attr.Val != "" && (attr.Key == "id" || (attr.Key == "name" && n.Data == "a")) {
+					anchors[attr.Val] = true
+				}
+			}
+		}
+
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			stack = append(stack, c)
+		}
+	}
+
+	return anchors
+}
+
+// NormalizeLink resolves link against baseURL and returns it in a canonical form: without fragment, with a
+// lower-case scheme and host, without the default port and with normalized percent-encoding. Links that
+// are not http or https URLs, and empty or fragment-only links to the page itself, are returned unchanged
+// with false.
+func NormalizeLink(link string, baseURL *url.URL) (string, bool) {
+	trimmed := strings.TrimSpace(link)
+	if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+		return link, false
+	}
+
+	ref, err := url.Parse(trimmed)
+	if err != nil {
+		return link, false
+	}
+
+	u := baseURL.ResolveReference(ref)
+	u.Scheme = strings.ToLower(u.Scheme)
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return link, false
+	}
+
+	u.Fragment = ""
+	u.RawFragment = ""
+	u.Host = canonicalHost(u)
+
+	path := normalizeEscapes(u.EscapedPath())
+	if path == "" {
+		path = "/"
+	}
+	if unescaped, err := url.PathUnescape(path); err == nil {
+		u.Path, u.RawPath = unescaped, path
+	}
+	u.RawQuery = normalizeEscapes(u.RawQuery)
+	u.ForceQuery = false
+
+	return u.String(), true
+}
+
+// canonicalHost returns the lower-case host of u without the default port of its scheme.
+func canonicalHost(u *url.URL) string {
+	host := strings.ToLower(u.Host)
+	port := u.Port()
+	if (strings.EqualFold(u.Scheme, "http") && port == "80") || (strings.EqualFold(u.Scheme, "https") && port == "443") {
+		host = strings.TrimSuffix(host, ":"+port)
+	}
+	return host
+}
+
+// CountLinks normalizes links against baseURL and returns each unique link once, in order of first
+// appearance, with its number of occurrences.
+func CountLinks(links []string, baseURL *url.URL) []LinkCount {
+	counts := []LinkCount{}
+	index := map[string]int{}
+
+	for _, link := range links {
+		key, _ := NormalizeLink(link, baseURL)
+		if i, ok := index[key]; ok {
+			counts[i].Occurrences++
+			continue
+		}
+		index[key] = len(counts)
+		counts = append(counts, LinkCount{URL: key, Occurrences: 1})
+	}
+
+	return counts
+}
+
+// normalizeEscapes decodes percent-encoded unreserved characters and upper-cases the hex digits of the
+// remaining escapes, as described in RFC 3986 section 6.2.2.
+func normalizeEscapes(s string) string {
+	if !strings.Contains(s, "%") {
+		return s
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '%' || i+2 >= len(s) || !isHex(s[i+1]) || !isHex(s[i+2]) {
+			b.WriteByte(s[i])
+			continue
+		}
+
+		c := unhex(s[i+1])<<4 | unhex(s[i+2])
+		if isUnreserved(c) {
+			b.WriteByte(c)
+		} else {
+			b.WriteString(strings.ToUpper(s[i : i+3]))
+		}
+		i += 2
+	}
+	return b.String()
+}
+
+func isUnreserved(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' || c == '-' || c == '.' || c == '_' || c == '~'
+}
+
+func isHex(c byte) bool {
+	return '0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F'
+}
+
+func unhex(c byte) byte {
+	switch {
+	case '0' <= c && c <= '9':
+		return c - '0'
+	case 'a' <= c && c <= 'f':
+		return c - 'a' + 10
+	default:
+		return c - 'A' + 10
+	}
+}
@@ -0,0 +1,102 @@
+package htmlhelper
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/html"
+)
+
+func TestGetBaseURL(t *testing.T) {
+	pageURL, _ := url.Parse("http://myapp.test/docs/page.html")
+
+	tests := []struct {
+		name     string
+		html     string
+		expected string
+	}{
+		{
+			name:     "No base element",
+			html:     `<html><head></head><body></body></html>`,
+			expected: "http://myapp.test/docs/page.html",
+		},
+		{
+			name:     "Absolute base",
+			html:     `<html><head><base href="https://cdn.test/assets/"></head></html>`,
+			expected: "https://cdn.test/assets/",
+		},
+		{
+			name:     "Relative base",
+			html:     `<html><head><base href="/v2/"><base href="/ignored/"></head></html>`,
+			expected: "http://myapp.test/v2/",
+		},
+		{
+			name:     "Base without href",
+			html:     `<html><head><base target="_blank"></head></html>`,
+			expected: "http://myapp.test/docs/page.html",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := html.Parse(strings.NewReader(tt.html))
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, GetBaseURL(doc, pageURL).String())
+		})
+	}
+}
+
+func TestNormalizeLink(t *testing.T) {
+	baseURL, _ := url.Parse("http://myapp.test/docs/")
+
+	tests := []struct {
+		name       string
+		link       string
+		expected   string
+		normalized bool
+	}{
+		{name: "Relative path", link: "guide", expected: "http://myapp.test/docs/guide", normalized: true},
+		{name: "Dot segments", link: "../about/./team", expected: "http://myapp.test/about/team", normalized: true},
+		{name: "Fragment", link: "/pricing#plans", expected: "http://myapp.test/pricing", normalized: true},
+		{name: "Host and scheme case", link: "HTTPS://MyApp.Test/Path", expected: "https://myapp.test/Path", normalized: true},
+		{name: "Default port", link: "http://myapp.test:80/a", expected: "http://myapp.test/a", normalized: true},
+		{name: "Other port", link: "https://myapp.test:8443/a", expected: "https://myapp.test:8443/a", normalized: true},
+		{name: "Empty path", link: "http://other.test", expected: "http://other.test/", normalized: true},
+		{name: "Unreserved escapes", link: "/%7Euser/%41bc", expected: "http://myapp.test/~user/Abc", normalized: true},
+		{name: "Reserved escapes", link: "/a%2fb?q=%3d%3D", expected: "http://myapp.test/a%2Fb?q=%3D%3D", normalized: true},
+		{name: "Empty query", link: "/search?", expected: "http://myapp.test/search", normalized: true},
+		{name: "Fragment only", link: "#top", expected: "#top", normalized: false},
+		{name: "Mail", link: "mailto:team@myapp.test", expected: "mailto:team@myapp.test", normalized: false},
+		{name: "Invalid", link: "http://[::1", expected: "http://[::1", normalized: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			normalized, ok := NormalizeLink(tt.link, baseURL)
+			assert.Equal(t, tt.expected, normalized)
+			assert.Equal(t, tt.normalized, ok)
+		})
+	}
+}
+
+func TestCountLinks(t *testing.T) {
+	baseURL, _ := url.Parse("http://myapp.test/")
+
+	links := []string{"/about", "http://MYAPP.test/about#team", "/about", "http://other.test", "#top", "/contact", "#top"}
+	assert.Equal(t, []LinkCount{
+		{URL: "http://myapp.test/about", Occurrences: 3},
+		{URL: "http://other.test/", Occurrences: 1},
+		{URL: "#top", Occurrences: 2},
+		{URL: "http://myapp.test/contact", Occurrences: 1},
+	}, CountLinks(links, baseURL))
+}
+
+func TestGetAnchors(t *testing.T) {
+	doc, _ := html.Parse(strings.NewReader(`<html><body>
+		<h1 id="title">Title</h1><a name="legacy"></a><input name="email"><div id="">Empty</div>
+	</body></html>`))
+
+	assert.Equal(t, map[string]bool{"title": true, "legacy": true}, GetAnchors(doc))
+}
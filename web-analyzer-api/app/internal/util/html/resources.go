@@ -0,0 +1,163 @@
+package htmlhelper
+
+import (
+	"net/url"
+	"slices"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// Kinds of resource a page references.
+const (
+	ResourceAnchor     = "anchor"
+	ResourceImage      = "image"
+	ResourceScript     = "script"
+	ResourceStylesheet = "stylesheet"
+	ResourceIframe     = "iframe"
+	ResourcePreload    = "preload"
+	ResourceForm       = "form"
+)
+
+// Resource is a URL referenced by a page, with the kind of resource and the attribute it was found in.
+type Resource struct {
+	Kind      string
+	Attribute string
+	URL       string
+}
+
+// ResourceCount is a unique resource URL with the kinds it is referenced as, in order of first appearance.
+type ResourceCount struct {
+	URL   string
+	Kinds []string
+}
+
+// GetResources returns the resources referenced by a page in document order: anchors, images and their
+// srcset candidates, scripts, stylesheets, iframes, preloads and form actions.
+func GetResources(doc *html.Node) []Resource {
+	var resources []Resource
+	stack := []*html.Node{doc}
+
+	for len(stack) > 0 {
+		n := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		if n.Type == html.ElementNode {
+			resources = append(resources, elementResources(n)...)
+		}
+
+		for c := n.LastChild; c != nil; c = c.PrevSibling {
+			stack = append(stack, c)
+		}
+	}
+
+	return resources
+}
+
+func elementResources(n *html.Node) []Resource {
+	var resources []Resource
+	add := func(kind, attribute string) {
+		if value, ok := attrValue(n, attribute); ok {
+			resources = append(resources, Resource{Kind: kind, Attribute: attribute, URL: value})
+		}
+	}
+	addSrcset := func(kind string) {
+		if value, ok := attrValue(n, "srcset"); ok {
+			for _, candidate := range ParseSrcset(value) {
+				resources = append(resources, Resource{Kind: kind, Attribute: "srcset", URL: candidate})
+			}
+		}
+	}
+
+	switch n.Data {
+	case "a":
+		add(ResourceAnchor, "href")
+	case "img":
+		add(ResourceImage, "src")
+		addSrcset(ResourceImage)
+	case "source":
+		addSrcset(ResourceImage)
+	case "script":
+		add(ResourceScript, "src")
+	case "iframe":
+		add(ResourceIframe, "src")
+	case "form":
+		add(ResourceForm, "action")
+	case "link":
+		rel := strings.Fields(strings.ToLower(getAttr(n, "rel")))
+		switch {
+		case slices.Contains(rel, "stylesheet"):
+			add(ResourceStylesheet, "href")
+		case slices.Contains(rel, "preload") || slices.Contains(rel, "modulepreload"):
+			add(ResourcePreload, "href")
+		}
+	}
+
+	return resources
+}
+
+// attrValue returns the trimmed value of an attribute, and false when it is missing or empty.
+func attrValue(n *html.Node, key string) (string, bool) {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			value := strings.TrimSpace(a.Val)
+			return value, value != ""
+		}
+	}
+	return "", false
+}
+
+// ParseSrcset returns the URLs of the candidates of a srcset attribute. As in the HTML spec, a URL runs up to
+// the next whitespace, so URLs with commas such as data URLs are kept whole.
+func ParseSrcset(srcset string) []string {
+	var urls []string
+	s := srcset
+	for {
+		s = strings.TrimLeft(s, " \t\n\r\f,")
+		if s == "" {
+			return urls
+		}
+
+		end := strings.IndexAny(s, " \t\n\r\f")
+		if end < 0 {
+			end = len(s)
+		}
+		candidate := s[:end]
+		s = s[end:]
+
+		// A URL ending with a comma has no descriptors
+		if trimmed := strings.TrimRight(candidate, ","); trimmed != candidate {
+			urls = append(urls, trimmed)
+			continue
+		}
+		urls = append(urls, candidate)
+
+		next := strings.IndexByte(s, ',')
+		if next < 0 {
+			return urls
+		}
+		s = s[next+1:]
+	}
+}
+
+// CountResources normalizes resources against baseURL and returns each unique URL once, in order of first
+// appearance, with the kinds it is referenced as.
+func CountResources(resources []Resource, baseURL *url.URL) []ResourceCount {
+	counts := []ResourceCount{}
+	index := map[string]int{}
+
+	for _, resource := range resources {
+		key, _ := NormalizeLink(resource.URL, baseURL)
+		i, ok := index[key]
+		if !ok {
+			i = len(counts)
+			index[key] = i
+			counts = append(counts, ResourceCount{URL: key})
+		}
+		if !slices.Contains(counts[i].Kinds, resource.Kind) {
+			counts[i].Kinds = append(counts[i].Kinds, resource.Kind)
+		}
+	}
+
+	return counts
+}
@@ -0,0 +1,61 @@
+package htmlhelper
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/html"
+)
+
+func TestGetResources(t *testing.T) {
+	doc, err := html.Parse(strings.NewReader(`<html><head>
+		<link rel="stylesheet" href="/main.css"><link rel="preload" href="/font.woff2" as="font">
+		<link rel="icon" href="/favicon.ico"><script src="/app.js"></script><script>inline()</script>
+	</head><body>
+		<a href="/about">About</a><a>No href</a>
+		<img src="/logo.png" srcset="/logo-1x.png 1x, /logo-2x.png 2x">
+		<picture><source srcset="/hero.webp"><img src=" /hero.jpg "></picture>
+		<iframe src="https://video.test/embed"></iframe>
+		<form action="/login" method="post"></form><form></form>
+	</body></html>`))
+	assert.NoError(t, err)
+
+	assert.Equal(t, []Resource{
+		{Kind: ResourceStylesheet, Attribute: "href", URL: "/main.css"},
+		{Kind: ResourcePreload, Attribute: "href", URL: "/font.woff2"},
+		{Kind: ResourceScript, Attribute: "src", URL: "/app.js"},
+		{Kind: ResourceAnchor, Attribute: "href", URL: "/about"},
+		{Kind: ResourceImage, Attribute: "src", URL: "/logo.png"},
+		{Kind: ResourceImage, Attribute: "srcset", URL: "/logo-1x.png"},
+		{Kind: ResourceImage, Attribute: "srcset", URL: "/logo-2x.png"},
+		{Kind: ResourceImage, Attribute: "srcset", URL: "/hero.webp"},
+		{Kind: ResourceImage, Attribute: "src", URL: "/hero.jpg"},
+		{Kind: ResourceIframe, Attribute: "src", URL: "https://video.test/embed"},
+		{Kind: ResourceForm, Attribute: "action", URL: "/login"},
+	}, GetResources(doc))
+}
+
+func TestParseSrcset(t *testing.T) {
+	assert.Equal(t, []string{"/a.png", "/b.png", "/c.png"}, ParseSrcset(" /a.png 480w,\n/b.png 2x , /c.png"))
+	assert.Equal(t, []string{"data:image/png;base64,AAAA", "/b.png"}, ParseSrcset("data:image/png;base64,AAAA 1x, /b.png 2x"))
+	assert.Equal(t, []string{"/a.png", "/b.png"}, ParseSrcset("/a.png, /b.png"))
+	assert.Nil(t, ParseSrcset(" , "))
+}
+
+func TestCountResources(t *testing.T) {
+	baseURL, _ := url.Parse("http://myapp.test/docs/")
+
+	counts := CountResources([]Resource{
+		{Kind: ResourceAnchor, URL: "logo.png"},
+		{Kind: ResourceImage, URL: "/docs/logo.png#x"},
+		{Kind: ResourceImage, URL: "http://MYAPP.test/docs/logo.png"},
+		{Kind: ResourceScript, URL: "https://cdn.test/app.js"},
+	}, baseURL)
+
+	assert.Equal(t, []ResourceCount{
+		{URL: "http://myapp.test/docs/logo.png", Kinds: []string{ResourceAnchor, ResourceImage}},
+		{URL: "https://cdn.test/app.js", Kinds: []string{ResourceScript}},
+	}, counts)
+}
@@ -0,0 +1,151 @@
+package htmlhelper
+
+import (
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// SEOMetadata is the metadata a page gives search engines and social networks. Titles holds every page
+// title in document order. Canonical and the alternate URLs are kept as written in the page.
+type SEOMetadata struct {
+	Titles      []string
+	Description string
+	Robots      string
+	Canonical   string
+	Alternates  []Alternate
+	OpenGraph   map[string]string
+	TwitterCard map[string]string
+	Viewport    string
+	Lang        string
+}
+
+// Alternate is a translation of a page announced with <link rel="alternate" hreflang>.
+type Alternate struct {
+	Hreflang string
+	URL      string
+}
+
+// GetSEOMetadata extracts the SEO metadata of a page. Meta names are matched case-insensitively and the first
+// of repeated tags wins, except for robots directives, which add up. Open Graph and Twitter Card properties are
+// keyed by their name without prefix, such as "title" for og:title.
+func GetSEOMetadata(doc *html.Node) SEOMetadata {
+	metadata := SEOMetadata{
+		Titles:      []string{},
+		Alternates:  []Alternate{},
+		OpenGraph:   map[string]string{},
+		TwitterCard: map[string]string{},
+	}
+	var robots []string
+
+	stack := []*html.Node{doc}
+	for len(stack) > 0 {
+		n := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		// Titles of inline SVG and MathML are not page titles
+		if n.Type == html.ElementNode && n.Namespace == "" {
+			switch n.Data {
+			case "html":
+				if lang, ok := attrValue(n, "lang"); ok && metadata.Lang == "" {
+					metadata.Lang = lang
+				}
+			case "title":
+				metadata.Titles = append(metadata.Titles, collapseSpaces(textContent(n)))
+			case "meta":
+				addMeta(&metadata, &robots, n)
+			case "link":
+				addLink(&metadata, n)
+			}
+		}
+
+		for c := n.LastChild; c != nil; c = c.PrevSibling {
+			stack = append(stack, c)
+		}
+	}
+
+	metadata.Robots = strings.Join(robots, ", ")
+	return metadata
+}
+
+func addMeta(metadata *SEOMetadata, robots *[]string, n *html.Node) {
+	content, ok := attrValue(n, "content")
+	if !ok {
+		return
+	}
+
+	// Open Graph uses property, Twitter Cards use name, but pages mix them up
+	name, _ := attrValue(n, "name")
+	if name == "" {
+		name, _ = attrValue(n, "property")
+	}
+	name = strings.ToLower(name)
+
+	setOnce := func(values map[string]string, key string) {
+		if _, exists := values[key]; !exists && key != "" {
+			values[key] = content
+		}
+	}
+
+	switch {
+	case name == "description":
+		if metadata.Description == "" {
+			metadata.Description = content
+		}
+	case name == "robots":
+		*robots = append(*robots, content)
+	case name == "viewport":
+		if metadata.Viewport == "" {
+			metadata.Viewport = content
+		}
+	case strings.HasPrefix(name, "og:"):
+		setOnce(metadata.OpenGraph, strings.TrimPrefix(name, "og:"))
+	case strings.HasPrefix(name, "twitter:"):
+		setOnce(metadata.TwitterCard, strings.TrimPrefix(name, "twitter:"))
+	}
+}
+
+func addLink(metadata *SEOMetadata, n *html.Node) {
+	href, ok := attrValue(n, "href")
+	if !ok {
+		return
+	}
+
+	for _, rel := range strings.Fields(strings.ToLower(getAttr(n, "rel"))) {
+		switch rel {
+		case "canonical":
+			if metadata.Canonical == "" {
+				metadata.Canonical = href
+			}
+		case "alternate":
+			if hreflang, ok := attrValue(n, "hreflang"); ok {
+				metadata.Alternates = append(metadata.Alternates, Alternate{Hreflang: hreflang, URL: href})
+			}
+		}
+	}
+}
+
+// IsNoindex reports whether robots directives keep a page out of search results.
+func IsNoindex(robots string) bool {
+	for _, directive := range strings.Split(strings.ToLower(robots), ",") {
+		switch strings.TrimSpace(directive) {
+		case "noindex", "none":
+			return true
+		}
+	}
+	return false
+}
+
+func textContent(n *html.Node) string {
+	var text strings.Builder
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == html.TextNode {
+			text.WriteString(c.Data)
+		}
+	}
+	return text.String()
+}
+
+func collapseSpaces(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
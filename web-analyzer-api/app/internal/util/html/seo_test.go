@@ -0,0 +1,61 @@
+package htmlhelper
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/html"
+)
+
+func TestGetSEOMetadata(t *testing.T) {
+	doc, err := html.Parse(strings.NewReader(`<!DOCTYPE html><html lang="en-GB"><head>
+		<title>  Home |
+			Example </title>
+		<META NAME="Description" content=" Welcome to the example site. ">
+		<meta name="description" content="Ignored">
+		<meta name="robots" content="noindex"><meta name="ROBOTS" content="nofollow">
+		<meta name="viewport" content="width=device-width, initial-scale=1">
+		<link rel="Canonical" href="https://example.test/">
+		<link rel="alternate" hreflang="de" href="/de/"><link rel="alternate" href="/feed.xml" type="application/rss+xml">
+		<meta property="og:title" content="Example"><meta property="og:title" content="Ignored">
+		<meta name="og:type" content="website"><meta name="twitter:card" content="summary">
+		<meta property="twitter:site" content="@example"><meta property="og:image">
+	</head><body>
+		<svg><title>Icon</title></svg>
+		<title>Second</title>
+	</body></html>`))
+	assert.NoError(t, err)
+
+	assert.Equal(t, SEOMetadata{
+		Titles:      []string{"Home | Example", "Second"},
+		Description: "Welcome to the example site.",
+		Robots:      "noindex, nofollow",
+		Canonical:   "https://example.test/",
+		Alternates:  []Alternate{{Hreflang: "de", URL: "/de/"}},
+		OpenGraph:   map[string]string{"title": "Example", "type": "website"},
+		TwitterCard: map[string]string{"card": "summary", "site": "@example"},
+		Viewport:    "width=device-width, initial-scale=1",
+		Lang:        "en-GB",
+	}, GetSEOMetadata(doc))
+
+	t.Run("No metadata", func(t *testing.T) {
+		doc, err := html.Parse(strings.NewReader(`<p>Hello</p>`))
+		assert.NoError(t, err)
+
+		metadata := GetSEOMetadata(doc)
+		assert.Empty(t, metadata.Titles)
+		assert.Empty(t, metadata.Description)
+		assert.Empty(t, metadata.Lang)
+		assert.Empty(t, metadata.OpenGraph)
+	})
+}
+
+func TestIsNoindex(t *testing.T) {
+	assert.True(t, IsNoindex("noindex"))
+	assert.True(t, IsNoindex("follow, NOINDEX"))
+	assert.True(t, IsNoindex("none"))
+	assert.False(t, IsNoindex("index, follow"))
+	assert.False(t, IsNoindex("max-snippet:-1, noimageindex"))
+	assert.False(t, IsNoindex(""))
+}
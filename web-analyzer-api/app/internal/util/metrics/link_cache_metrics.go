@@ -0,0 +1,57 @@
+package metrics
+
+import (
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	linkCacheLookupsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "link_cache_lookups_total",
+			Help: "Total number of link result cache lookups",
+		},
+		LinkCacheLabels,
+	)
+
+	linkCacheEvictionsTotal = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "link_cache_evictions_total",
+			Help: "Total number of link results evicted from the cache to stay within its size",
+		},
+	)
+
+	linkCacheEntries = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "link_cache_entries",
+			Help: "Number of link results in the cache",
+		},
+	)
+
+	registerLinkCacheOnce sync.Once
+)
+
+func RegisterLinkCacheMetrics() {
+	registerLinkCacheOnce.Do(func() {
+		prometheus.MustRegister(linkCacheLookupsTotal)
+		prometheus.MustRegister(linkCacheEvictionsTotal)
+		prometheus.MustRegister(linkCacheEntries)
+	})
+}
+
+func RecordLinkCacheHit() {
+	linkCacheLookupsTotal.WithLabelValues(LinkCacheHit).Inc()
+}
+
+func RecordLinkCacheMiss() {
+	linkCacheLookupsTotal.WithLabelValues(LinkCacheMiss).Inc()
+}
+
+func RecordLinkCacheEviction() {
+	linkCacheEvictionsTotal.Inc()
+}
+
+func SetLinkCacheEntries(entries int) {
+	linkCacheEntries.Set(float64(entries))
+}
@@ -0,0 +1,25 @@
+package metrics
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecordLinkCacheLookups(t *testing.T) {
+	RegisterLinkCacheMetrics()
+
+	hits := testutil.ToFloat64(linkCacheLookupsTotal.WithLabelValues(LinkCacheHit))
+	misses := testutil.ToFloat64(linkCacheLookupsTotal.WithLabelValues(LinkCacheMiss))
+
+	RecordLinkCacheHit()
+	RecordLinkCacheHit()
+	RecordLinkCacheMiss()
+
+	assert.Equal(t, hits+2, testutil.ToFloat64(linkCacheLookupsTotal.WithLabelValues(LinkCacheHit)))
+	assert.Equal(t, misses+1, testutil.ToFloat64(linkCacheLookupsTotal.WithLabelValues(LinkCacheMiss)))
+
+	SetLinkCacheEntries(3)
+	assert.Equal(t, float64(3), testutil.ToFloat64(linkCacheEntries))
+}
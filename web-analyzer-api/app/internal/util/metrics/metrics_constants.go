@@ -14,6 +14,12 @@ const (
 	LabelStatus   = "status"
 	LabelCategory = "category"
 	LabelReason   = "reason"
+	LabelResult   = "result"
+)
+
+const (
+	LinkCacheHit  = "hit"
+	LinkCacheMiss = "miss"
 )
 
 var APILabels = []string{
@@ -26,3 +32,7 @@ var APIRequestDurationLabels = []string{
 	LabelMethod,
 	LabelEndpoint,
 }
+
+var LinkCacheLabels = []string{
+	LabelResult,
+}
@@ -98,6 +98,12 @@ const app = {
             }
             console.log(data);
 
+            if (data.status === 'queued') {
+                statusText.text(`Analysis queued (position ${data.queue_position})...`);
+            } else if (data.status === 'pending') {
+                statusText.text('Analysis in progress...');
+            }
+
             if (data.status === 'success') {
                 clearInterval(interval);
                 app.renderResults(data);
@@ -109,6 +115,18 @@ const app = {
                 statusText.text('Analysis failed.');
                 loadingSpinner.hide();
                 analyzeBtn.disabled = false;
+            } else if (data.status === 'cancelled') {
+                clearInterval(interval);
+                statusText.text('Analysis cancelled.');
+                loadingSpinner.hide();
+                analyzeBtn.disabled = false;
+            } else if (data.status === 'interrupted') {
+                // Stopped by a server shutdown, the server may resume it on its next start
+                clearInterval(interval);
+                errorText.text(data.error_description);
+                statusText.text('Analysis interrupted.');
+                loadingSpinner.hide();
+                analyzeBtn.disabled = false;
             }
         }
 